@@ -22,9 +22,16 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to initialize SDK: %v", err)
 	}
+	defer sdk.Close()
 
 	fmt.Println("🚀 EasyCash SDK Initialized (Advanced Mode)")
 
+	// Shield funds so the private transfer has notes to spend
+	if _, err := sdk.ShieldFunds("USDC", "7500.00"); err != nil {
+		log.Fatalf("Failed to shield funds: %v", err)
+	}
+	fmt.Printf("Shielded balance: %s USDC\n", sdk.GetShieldedBalance("USDC"))
+
 	// 2. Define a Shielded Transfer Request
 	req := &types.TransactionRequest{
 		ReferenceID: "ref_pay_salary_001",
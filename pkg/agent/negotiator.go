@@ -5,14 +5,20 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/useeasycash/ecash-sdk-core/pkg/cache"
 	"github.com/useeasycash/ecash-sdk-core/pkg/types"
 )
 
 // AgentNegotiator handles fee negotiation and route selection with the Agent Network
 type AgentNegotiator struct {
 	timeout time.Duration
+
+	quotes *cache.Loader[[]RouteQuote]
 }
 
+// quoteValidity is how long agents honor a quote
+const quoteValidity = time.Minute
+
 func NewNegotiator(timeout time.Duration) *AgentNegotiator {
 	return &AgentNegotiator{
 		timeout: timeout,
@@ -21,15 +27,54 @@ func NewNegotiator(timeout time.Duration) *AgentNegotiator {
 
 // RouteQuote represents a quote from an agent for executing a transaction
 type RouteQuote struct {
-	AgentID       string
-	EstimatedFee  string
-	EstimatedTime time.Duration
-	Route         []string // Chain hops
-	SecurityScore float64  // 0.0 - 1.0
+	AgentID       string        `json:"agent_id"`
+	EstimatedFee  string        `json:"estimated_fee"`
+	EstimatedTime time.Duration `json:"estimated_time"`
+	Route         []string      `json:"route"`          // Chain hops
+	SecurityScore float64       `json:"security_score"` // 0.0 - 1.0
+	ExpiresAt     time.Time     `json:"expires_at"`     // the agent no longer honors the quote after this
 }
 
-// RequestQuotes fetches multiple route quotes from available agents
+// QuotesExpiry returns the earliest expiry of a quote set, bounding how long it may be
+// cached; the zero time for no quotes
+func QuotesExpiry(quotes []RouteQuote) time.Time {
+	var earliest time.Time
+	for _, q := range quotes {
+		if earliest.IsZero() || q.ExpiresAt.Before(earliest) {
+			earliest = q.ExpiresAt
+		}
+	}
+	return earliest
+}
+
+// SetQuoteCache serves quotes for identical routes through loader, which coalesces
+// concurrent requests and may share quotes across replicas on one cache server; a nil
+// loader disables quote caching
+func (n *AgentNegotiator) SetQuoteCache(loader *cache.Loader[[]RouteQuote]) {
+	n.quotes = loader
+}
+
+// quoteKey identifies the requests that receive the same quotes
+func quoteKey(req *types.TransactionRequest) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s", req.Type, req.SourceChain, req.TargetChain, req.Asset, req.Amount)
+}
+
+// RequestQuotes fetches multiple route quotes from available agents, or from the quote
+// cache when one is set
 func (n *AgentNegotiator) RequestQuotes(ctx context.Context, req *types.TransactionRequest) ([]RouteQuote, error) {
+	if n.quotes == nil {
+		return n.fetchQuotes(ctx, req)
+	}
+	return n.quotes.Get(ctx, quoteKey(req), func(ctx context.Context) ([]RouteQuote, error) {
+		// Shared loads outlive the request that started them, so bound them here
+		ctx, cancel := context.WithTimeout(ctx, n.timeout)
+		defer cancel()
+		return n.fetchQuotes(ctx, req)
+	})
+}
+
+// fetchQuotes asks the Agent Discovery Service for quotes
+func (n *AgentNegotiator) fetchQuotes(ctx context.Context, req *types.TransactionRequest) ([]RouteQuote, error) {
 	// Simulate network call to Agent Discovery Service
 	select {
 	case <-ctx.Done():
@@ -39,6 +84,7 @@ func (n *AgentNegotiator) RequestQuotes(ctx context.Context, req *types.Transact
 	}
 
 	// Return simulated quotes
+	expires := time.Now().Add(quoteValidity)
 	quotes := []RouteQuote{
 		{
 			AgentID:       "agent-001",
@@ -46,6 +92,7 @@ func (n *AgentNegotiator) RequestQuotes(ctx context.Context, req *types.Transact
 			EstimatedTime: 15 * time.Second,
 			Route:         []string{string(req.SourceChain), string(req.TargetChain)},
 			SecurityScore: 0.98,
+			ExpiresAt:     expires,
 		},
 		{
 			AgentID:       "agent-002",
@@ -53,6 +100,7 @@ func (n *AgentNegotiator) RequestQuotes(ctx context.Context, req *types.Transact
 			EstimatedTime: 30 * time.Second,
 			Route:         []string{string(req.SourceChain), "polygon", string(req.TargetChain)},
 			SecurityScore: 0.85,
+			ExpiresAt:     expires,
 		},
 	}
 
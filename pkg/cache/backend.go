@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTTL is returned when a value is stored without a positive TTL
+var ErrInvalidTTL = errors.New("cache ttl must be positive")
+
+// Backend stores serialized values, either in process or in a shared server so that
+// replicas see each other's entries. Implementations are safe for concurrent use.
+type Backend interface {
+	// Get returns the value stored under key; a miss is not an error
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl, which must be positive
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	Close() error
+}
+
+// MemoryBackend is a Backend over an in-process Cache
+type MemoryBackend struct {
+	cache *Cache[string, []byte]
+}
+
+// NewMemoryBackend creates an in-process backend with the given bounds; opts.TTL is
+// ignored since every Set carries its own
+func NewMemoryBackend(opts Options[string, []byte]) *MemoryBackend {
+	return &MemoryBackend{cache: New(opts)}
+}
+
+func (b *MemoryBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok := b.cache.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	return append([]byte(nil), value...), true, nil
+}
+
+func (b *MemoryBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	b.cache.SetWithTTL(key, append([]byte(nil), value...), ttl)
+	return nil
+}
+
+func (b *MemoryBackend) Delete(ctx context.Context, key string) error {
+	b.cache.Delete(key)
+	return nil
+}
+
+func (b *MemoryBackend) Close() error {
+	b.cache.Close()
+	return nil
+}
+
+// Codec serializes cached values for a Backend
+type Codec[V any] interface {
+	Encode(value V) ([]byte, error)
+	Decode(data []byte) (V, error)
+}
+
+// JSONCodec serializes values as JSON
+type JSONCodec[V any] struct{}
+
+func (JSONCodec[V]) Encode(value V) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (JSONCodec[V]) Decode(data []byte) (V, error) {
+	var value V
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// Store is a typed view of a Backend. Keys are namespaced by prefix so several stores,
+// or several SDK versions with different value layouts, can share one backend.
+type Store[V any] struct {
+	backend Backend
+	codec   Codec[V]
+	prefix  string
+}
+
+// NewStore creates a store of V values under prefix
+func NewStore[V any](backend Backend, codec Codec[V], prefix string) *Store[V] {
+	return &Store[V]{backend: backend, codec: codec, prefix: prefix}
+}
+
+// Get returns the value stored under key. Values that no longer decode are reported
+// as errors rather than misses so a layout change is noticed.
+func (s *Store[V]) Get(ctx context.Context, key string) (V, bool, error) {
+	var zero V
+	data, ok, err := s.backend.Get(ctx, s.prefix+key)
+	if err != nil || !ok {
+		return zero, false, err
+	}
+	value, err := s.codec.Decode(data)
+	if err != nil {
+		return zero, false, fmt.Errorf("failed to decode cached %s: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Set stores value under key for ttl
+func (s *Store[V]) Set(ctx context.Context, key string, value V, ttl time.Duration) error {
+	data, err := s.codec.Encode(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cached %s: %w", key, err)
+	}
+	return s.backend.Set(ctx, s.prefix+key, data, ttl)
+}
+
+// Delete removes key
+func (s *Store[V]) Delete(ctx context.Context, key string) error {
+	return s.backend.Delete(ctx, s.prefix+key)
+}
@@ -1,84 +1,248 @@
 package cache
 
 import (
+	"container/list"
 	"sync"
 	"time"
 )
 
-// CacheEntry represents a cached item with expiration
-type CacheEntry struct {
-	Value      interface{}
+// EvictionReason says why an entry left the cache
+type EvictionReason int
+
+const (
+	EvictedCapacity EvictionReason = iota // least recently used entry over MaxEntries or MaxCost
+	EvictedExpired                        // entry outlived its TTL
+	EvictedDeleted                        // removed by Delete
+	EvictedReplaced                       // overwritten by Set
+)
+
+// Options bounds a Cache. Zero limits mean unbounded.
+type Options[K comparable, V any] struct {
+	// TTL is how long Set keeps an entry; zero or less keeps it until evicted
+	TTL        time.Duration
+	MaxEntries int
+	MaxCost    int64
+	// CleanupInterval is how often expired entries are swept in the background; zero
+	// disables the sweep and expired entries are dropped when read or evicted
+	CleanupInterval time.Duration
+	// Cost weighs a value against MaxCost; nil counts every entry as 1
+	Cost func(V) int64
+	// OnEvict is called outside the cache lock whenever an entry leaves the cache
+	OnEvict func(key K, value V, reason EvictionReason)
+}
+
+// CacheEntry represents a cached item with expiration. A zero Expiration never expires.
+type CacheEntry[K comparable, V any] struct {
+	Key        K
+	Value      V
 	Expiration time.Time
+	cost       int64
+}
+
+// expired reports whether the entry has outlived its TTL at now
+func (e *CacheEntry[K, V]) expired(now time.Time) bool {
+	return !e.Expiration.IsZero() && now.After(e.Expiration)
 }
 
-// Cache provides in-memory caching for agent quotes and route data
-type Cache struct {
-	mu    sync.RWMutex
-	items map[string]*CacheEntry
-	ttl   time.Duration
+// eviction is a callback owed for an entry removed under the lock
+type eviction[K comparable, V any] struct {
+	entry  *CacheEntry[K, V]
+	reason EvictionReason
 }
 
-// NewCache creates a new cache with specified TTL
-func NewCache(ttl time.Duration) *Cache {
-	c := &Cache{
-		items: make(map[string]*CacheEntry),
-		ttl:   ttl,
+// Cache provides in-memory caching for agent quotes and route data. It is bounded
+// by entry count and total cost and evicts the least recently used entry first.
+type Cache[K comparable, V any] struct {
+	opts Options[K, V]
+
+	mu    sync.Mutex
+	items map[K]*list.Element
+	lru   *list.List // front is most recently used
+	cost  int64
+
+	stop      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewCache creates an unbounded cache with specified TTL, swept once per TTL. A zero
+// TTL keeps entries until they are deleted.
+func NewCache[K comparable, V any](ttl time.Duration) *Cache[K, V] {
+	return New(Options[K, V]{TTL: ttl, CleanupInterval: ttl})
+}
+
+// New creates a cache with the given bounds. Call Close to stop its cleanup goroutine.
+func New[K comparable, V any](opts Options[K, V]) *Cache[K, V] {
+	c := &Cache[K, V]{
+		opts:    opts,
+		items:   make(map[K]*list.Element),
+		lru:     list.New(),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
 	}
 
 	// Start cleanup goroutine
-	go c.cleanup()
+	if opts.CleanupInterval > 0 {
+		go c.cleanup()
+	} else {
+		close(c.stopped)
+	}
 
 	return c
 }
 
-// Set stores a value in the cache
-func (c *Cache) Set(key string, value interface{}) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// Close stops the cleanup goroutine and waits for it to exit. The cache remains
+// usable; expired entries are then only dropped when read or evicted.
+func (c *Cache[K, V]) Close() {
+	c.closeOnce.Do(func() { close(c.stop) })
+	<-c.stopped
+}
+
+// Set stores a value in the cache with the default TTL
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.opts.TTL)
+}
+
+// SetWithTTL stores a value that expires after ttl instead of the default; zero or
+// less never expires
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	entry := &CacheEntry[K, V]{Key: key, Value: value, cost: 1}
+	if ttl > 0 {
+		entry.Expiration = time.Now().Add(ttl)
+	}
+	if c.opts.Cost != nil {
+		entry.cost = c.opts.Cost(value)
+	}
 
-	c.items[key] = &CacheEntry{
-		Value:      value,
-		Expiration: time.Now().Add(c.ttl),
+	c.mu.Lock()
+	var evicted []eviction[K, V]
+	if elem, exists := c.items[key]; exists {
+		evicted = append(evicted, eviction[K, V]{c.removeLocked(elem), EvictedReplaced})
 	}
+	if c.opts.MaxCost > 0 && entry.cost > c.opts.MaxCost {
+		// A value over the whole budget would flush every other entry and still not fit
+		evicted = append(evicted, eviction[K, V]{entry, EvictedCapacity})
+	} else {
+		c.items[key] = c.lru.PushFront(entry)
+		c.cost += entry.cost
+		evicted = append(evicted, c.trimLocked()...)
+	}
+	c.mu.Unlock()
+
+	c.notify(evicted)
 }
 
-// Get retrieves a value from the cache
-func (c *Cache) Get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// Get retrieves a value from the cache and marks it recently used
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	var zero V
 
-	entry, exists := c.items[key]
+	c.mu.Lock()
+	elem, exists := c.items[key]
 	if !exists {
-		return nil, false
+		c.mu.Unlock()
+		return zero, false
 	}
 
-	if time.Now().After(entry.Expiration) {
-		return nil, false
+	entry := elem.Value.(*CacheEntry[K, V])
+	if entry.expired(time.Now()) {
+		c.removeLocked(elem)
+		c.mu.Unlock()
+		c.notify([]eviction[K, V]{{entry, EvictedExpired}})
+		return zero, false
 	}
+	c.lru.MoveToFront(elem)
+	c.mu.Unlock()
 
 	return entry.Value, true
 }
 
 // Delete removes a key from the cache
-func (c *Cache) Delete(key string) {
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	elem, exists := c.items[key]
+	if !exists {
+		c.mu.Unlock()
+		return
+	}
+	entry := c.removeLocked(elem)
+	c.mu.Unlock()
+
+	c.notify([]eviction[K, V]{{entry, EvictedDeleted}})
+}
+
+// Len returns the number of cached entries, including expired ones not yet cleaned up
+func (c *Cache[K, V]) Len() int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	delete(c.items, key)
+	return c.lru.Len()
 }
 
-// cleanup periodically removes expired entries
-func (c *Cache) cleanup() {
-	ticker := time.NewTicker(c.ttl)
+// Cost returns the total cost of the cached entries
+func (c *Cache[K, V]) Cost() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cost
+}
+
+// removeLocked unlinks an entry; c.mu must be held
+func (c *Cache[K, V]) removeLocked(elem *list.Element) *CacheEntry[K, V] {
+	entry := c.lru.Remove(elem).(*CacheEntry[K, V])
+	delete(c.items, entry.Key)
+	c.cost -= entry.cost
+	return entry
+}
+
+// trimLocked evicts least recently used entries until the cache is within bounds;
+// c.mu must be held
+func (c *Cache[K, V]) trimLocked() []eviction[K, V] {
+	var evicted []eviction[K, V]
+	for c.lru.Len() > 0 &&
+		(c.opts.MaxEntries > 0 && c.lru.Len() > c.opts.MaxEntries ||
+			c.opts.MaxCost > 0 && c.cost > c.opts.MaxCost) {
+		evicted = append(evicted, eviction[K, V]{c.removeLocked(c.lru.Back()), EvictedCapacity})
+	}
+	return evicted
+}
+
+// notify runs the eviction callback for removed entries
+func (c *Cache[K, V]) notify(evicted []eviction[K, V]) {
+	if c.opts.OnEvict == nil {
+		return
+	}
+	for _, e := range evicted {
+		c.opts.OnEvict(e.entry.Key, e.entry.Value, e.reason)
+	}
+}
+
+// cleanup periodically removes expired entries until Close
+func (c *Cache[K, V]) cleanup() {
+	defer close(c.stopped)
+	ticker := time.NewTicker(c.opts.CleanupInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		c.mu.Lock()
-		now := time.Now()
-		for key, entry := range c.items {
-			if now.After(entry.Expiration) {
-				delete(c.items, key)
-			}
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.sweep()
 		}
-		c.mu.Unlock()
 	}
 }
+
+// sweep removes every expired entry
+func (c *Cache[K, V]) sweep() {
+	c.mu.Lock()
+	now := time.Now()
+	var evicted []eviction[K, V]
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if entry := elem.Value.(*CacheEntry[K, V]); entry.expired(now) {
+			evicted = append(evicted, eviction[K, V]{c.removeLocked(elem), EvictedExpired})
+		}
+		elem = next
+	}
+	c.mu.Unlock()
+
+	c.notify(evicted)
+}
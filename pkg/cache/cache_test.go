@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type evicted struct {
+	key    string
+	reason EvictionReason
+}
+
+func newRecordingCache(opts Options[string, int]) (*Cache[string, int], *[]evicted) {
+	var log []evicted
+	opts.OnEvict = func(key string, _ int, reason EvictionReason) {
+		log = append(log, evicted{key, reason})
+	}
+	return New(opts), &log
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+	c, log := newRecordingCache(Options[string, int]{TTL: time.Minute, MaxEntries: 2})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	// Reading a makes b the least recently used
+	v, ok := c.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	c.Set("c", 3)
+	assert.Equal(t, 2, c.Len())
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, []evicted{{"b", EvictedCapacity}}, *log)
+
+	c.Set("a", 10)
+	c.Delete("c")
+	assert.Equal(t, []evicted{{"b", EvictedCapacity}, {"a", EvictedReplaced}, {"c", EvictedDeleted}}, *log)
+	v, _ = c.Get("a")
+	assert.Equal(t, 10, v)
+}
+
+func TestCacheMaxCost(t *testing.T) {
+	c, log := newRecordingCache(Options[string, int]{
+		TTL:     time.Minute,
+		MaxCost: 10,
+		Cost:    func(v int) int64 { return int64(v) },
+	})
+
+	c.Set("a", 4)
+	c.Set("b", 4)
+	assert.Equal(t, int64(8), c.Cost())
+
+	c.Set("c", 5)
+	assert.Equal(t, int64(9), c.Cost())
+	assert.Equal(t, []evicted{{"a", EvictedCapacity}}, *log)
+
+	// A value over the whole budget is rejected without flushing the cache
+	c.Set("huge", 11)
+	_, ok := c.Get("huge")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, evicted{"huge", EvictedCapacity}, (*log)[len(*log)-1])
+}
+
+func TestCacheTTLOverride(t *testing.T) {
+	c, log := newRecordingCache(Options[string, int]{TTL: time.Minute})
+
+	c.SetWithTTL("short", 1, 10*time.Millisecond)
+	c.Set("long", 2)
+	time.Sleep(20 * time.Millisecond)
+
+	_, ok := c.Get("short")
+	assert.False(t, ok)
+	_, ok = c.Get("long")
+	assert.True(t, ok)
+	assert.Equal(t, []evicted{{"short", EvictedExpired}}, *log)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestCacheCleanupAndClose(t *testing.T) {
+	c, log := newRecordingCache(Options[string, int]{TTL: 5 * time.Millisecond, CleanupInterval: 5 * time.Millisecond})
+	c.Set("a", 1)
+	require.Eventually(t, func() bool { return c.Len() == 0 }, time.Second, time.Millisecond)
+
+	c.Close()
+	c.Close()
+	select {
+	case <-c.stopped:
+	default:
+		t.Fatal("cleanup goroutine still running after Close")
+	}
+	assert.Equal(t, []evicted{{"a", EvictedExpired}}, *log)
+
+	// Without the sweep, expired entries are dropped when read
+	c.Set("b", 2)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 1, c.Len())
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+}
+
+func TestCacheZeroTTL(t *testing.T) {
+	c := NewCache[string, int](0)
+	defer c.Close()
+
+	// Without a TTL entries stay until deleted or evicted
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, -time.Second)
+	c.SetWithTTL("c", 3, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	v, ok := c.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, 1, v)
+	v, ok = c.Get("b")
+	require.True(t, ok)
+	assert.Equal(t, 2, v)
+	_, ok = c.Get("c")
+	assert.False(t, ok)
+}
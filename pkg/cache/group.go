@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// flight is one in-progress load shared by every caller of its key
+type flight[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// Group coalesces concurrent loads of the same key into one call. The zero value is
+// ready to use.
+type Group[V any] struct {
+	mu      sync.Mutex
+	flights map[string]*flight[V]
+}
+
+// Do runs fn once for all concurrent callers of key and returns its result. fn runs
+// detached from the caller's cancellation so one caller giving up does not fail the
+// others; a cancelled caller returns ctx's error without waiting.
+func (g *Group[V]) Do(ctx context.Context, key string, fn func(context.Context) (V, error)) (V, error) {
+	f := g.start(ctx, key, fn)
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// Go starts fn for key in the background unless a load of key is already in flight
+func (g *Group[V]) Go(ctx context.Context, key string, fn func(context.Context) (V, error)) {
+	g.start(ctx, key, fn)
+}
+
+// InFlight reports whether a load of key is running
+func (g *Group[V]) InFlight(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.flights[key]
+	return ok
+}
+
+func (g *Group[V]) start(ctx context.Context, key string, fn func(context.Context) (V, error)) *flight[V] {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if f, ok := g.flights[key]; ok {
+		return f
+	}
+	if g.flights == nil {
+		g.flights = make(map[string]*flight[V])
+	}
+	f := &flight[V]{done: make(chan struct{})}
+	g.flights[key] = f
+
+	go func() {
+		f.value, f.err = fn(context.WithoutCancel(ctx))
+
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	return f
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// LoaderOptions configures a Loader
+type LoaderOptions[V any] struct {
+	// TTL is how long a loaded value is fresh
+	TTL time.Duration
+	// StaleTTL is how long past TTL a value is still served while a background load
+	// refreshes it; zero serves only fresh values
+	StaleTTL time.Duration
+	// Expiry is the instant a value must no longer be served at all, e.g. the expiry
+	// of a quote; it bounds both TTLs. Nil or a zero time means no bound.
+	Expiry func(V) time.Time
+	// OnError receives backend failures, which are otherwise treated as misses
+	OnError func(error)
+}
+
+// stamped is a value and the instant it stops being fresh
+type stamped[V any] struct {
+	value      V
+	freshUntil time.Time
+}
+
+// stampedCodec prefixes a value's encoding with its freshness deadline in Unix nanoseconds
+type stampedCodec[V any] struct {
+	inner Codec[V]
+}
+
+func (c stampedCodec[V]) Encode(s stamped[V]) ([]byte, error) {
+	data, err := c.inner.Encode(s.value)
+	if err != nil {
+		return nil, err
+	}
+	out := binary.BigEndian.AppendUint64(nil, uint64(s.freshUntil.UnixNano()))
+	return append(out, data...), nil
+}
+
+func (c stampedCodec[V]) Decode(data []byte) (stamped[V], error) {
+	if len(data) < 8 {
+		return stamped[V]{}, fmt.Errorf("cached value is %d bytes, missing its freshness header", len(data))
+	}
+	value, err := c.inner.Decode(data[8:])
+	if err != nil {
+		return stamped[V]{}, err
+	}
+	return stamped[V]{value: value, freshUntil: time.Unix(0, int64(binary.BigEndian.Uint64(data)))}, nil
+}
+
+// Loader reads values through a Backend, loading misses with a caller-supplied
+// function. Concurrent misses of a key share one load, and with a StaleTTL an expired
+// value is served while a single background load replaces it.
+type Loader[V any] struct {
+	store *Store[stamped[V]]
+	opts  LoaderOptions[V]
+	group Group[V]
+}
+
+// NewLoader creates a loader storing values in backend under prefix
+func NewLoader[V any](backend Backend, codec Codec[V], prefix string, opts LoaderOptions[V]) *Loader[V] {
+	return &Loader[V]{
+		store: NewStore[stamped[V]](backend, stampedCodec[V]{inner: codec}, prefix),
+		opts:  opts,
+	}
+}
+
+// Get returns the cached value of key, calling load on a miss or, in the background,
+// once the value is stale
+func (l *Loader[V]) Get(ctx context.Context, key string, load func(context.Context) (V, error)) (V, error) {
+	cached, found, err := l.store.Get(ctx, key)
+	if err != nil {
+		l.report(err)
+	}
+
+	now := time.Now()
+	if found && l.servable(cached.value, now) {
+		if now.Before(cached.freshUntil) {
+			return cached.value, nil
+		}
+		if l.opts.StaleTTL > 0 {
+			l.group.Go(ctx, key, l.loadAndStore(key, load))
+			return cached.value, nil
+		}
+	}
+	return l.group.Do(ctx, key, l.loadAndStore(key, load))
+}
+
+// servable reports whether value is before its own expiry
+func (l *Loader[V]) servable(value V, now time.Time) bool {
+	if l.opts.Expiry == nil {
+		return true
+	}
+	expiry := l.opts.Expiry(value)
+	return expiry.IsZero() || now.Before(expiry)
+}
+
+// loadAndStore wraps load to cache its result for TTL plus StaleTTL, never past the
+// value's own expiry
+func (l *Loader[V]) loadAndStore(key string, load func(context.Context) (V, error)) func(context.Context) (V, error) {
+	return func(ctx context.Context) (V, error) {
+		value, err := load(ctx)
+		if err != nil {
+			return value, err
+		}
+
+		now := time.Now()
+		freshUntil := now.Add(l.opts.TTL)
+		keepUntil := freshUntil.Add(l.opts.StaleTTL)
+		if l.opts.Expiry != nil {
+			if expiry := l.opts.Expiry(value); !expiry.IsZero() && expiry.Before(keepUntil) {
+				keepUntil = expiry
+			}
+		}
+		if keepUntil.After(now) {
+			if err := l.store.Set(ctx, key, stamped[V]{value: value, freshUntil: freshUntil}, keepUntil.Sub(now)); err != nil {
+				l.report(err)
+			}
+		}
+		return value, nil
+	}
+}
+
+func (l *Loader[V]) report(err error) {
+	if l.opts.OnError != nil {
+		l.opts.OnError(err)
+	}
+}
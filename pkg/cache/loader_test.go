@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expiringValue carries its own expiry like a route quote
+type expiringValue struct {
+	N         int       `json:"n"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func newTestLoader(t *testing.T, opts LoaderOptions[expiringValue]) *Loader[expiringValue] {
+	backend := NewMemoryBackend(Options[string, []byte]{})
+	t.Cleanup(func() { backend.Close() })
+	opts.Expiry = func(v expiringValue) time.Time { return v.ExpiresAt }
+	return NewLoader(backend, JSONCodec[expiringValue]{}, "test:", opts)
+}
+
+func TestLoaderCoalescesConcurrentMisses(t *testing.T) {
+	loader := newTestLoader(t, LoaderOptions[expiringValue]{TTL: time.Minute})
+	release := make(chan struct{})
+	var calls atomic.Int32
+	load := func(ctx context.Context) (expiringValue, error) {
+		calls.Add(1)
+		<-release
+		return expiringValue{N: 1}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]expiringValue, 20)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := loader.Get(context.Background(), "route", load)
+			assert.NoError(t, err)
+			results[i] = v
+		}(i)
+	}
+	require.Eventually(t, func() bool { return loader.group.InFlight("route") }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, v := range results {
+		assert.Equal(t, 1, v.N)
+	}
+
+	// The loaded value is now cached
+	_, err := loader.Get(context.Background(), "route", load)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestLoaderCancelledCallerDoesNotFailOthers(t *testing.T) {
+	loader := newTestLoader(t, LoaderOptions[expiringValue]{TTL: time.Minute})
+	release := make(chan struct{})
+	load := func(ctx context.Context) (expiringValue, error) {
+		select {
+		case <-release:
+			return expiringValue{N: 1}, nil
+		case <-ctx.Done():
+			return expiringValue{}, ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := loader.Get(ctx, "route", load)
+		first <- err
+	}()
+	require.Eventually(t, func() bool { return loader.group.InFlight("route") }, time.Second, time.Millisecond)
+
+	second := make(chan expiringValue)
+	go func() {
+		v, _ := loader.Get(context.Background(), "route", load)
+		second <- v
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+	close(release)
+	assert.Equal(t, 1, (<-second).N)
+}
+
+func TestLoaderStaleWhileRevalidate(t *testing.T) {
+	loader := newTestLoader(t, LoaderOptions[expiringValue]{TTL: 50 * time.Millisecond, StaleTTL: time.Minute})
+	var calls atomic.Int32
+	load := func(ctx context.Context) (expiringValue, error) {
+		n := calls.Add(1)
+		return expiringValue{N: int(n), ExpiresAt: time.Now().Add(time.Minute)}, nil
+	}
+	ctx := context.Background()
+
+	v, err := loader.Get(ctx, "route", load)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v.N)
+
+	// A stale value is returned immediately while one refresh runs in the background
+	time.Sleep(60 * time.Millisecond)
+	v, err = loader.Get(ctx, "route", load)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v.N)
+
+	require.Eventually(t, func() bool {
+		v, err := loader.Get(ctx, "route", load)
+		return err == nil && v.N == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestLoaderBoundedByValueExpiry(t *testing.T) {
+	loader := newTestLoader(t, LoaderOptions[expiringValue]{TTL: 10 * time.Millisecond, StaleTTL: time.Minute})
+	var calls atomic.Int32
+	load := func(ctx context.Context) (expiringValue, error) {
+		n := calls.Add(1)
+		return expiringValue{N: int(n), ExpiresAt: time.Now().Add(40 * time.Millisecond)}, nil
+	}
+	ctx := context.Background()
+
+	_, err := loader.Get(ctx, "route", load)
+	require.NoError(t, err)
+
+	// Past its own expiry a value is never served stale; the caller waits for a new one
+	time.Sleep(50 * time.Millisecond)
+	v, err := loader.Get(ctx, "route", load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, v.N)
+
+	// Values that are already expired when loaded are not cached at all
+	expired := func(ctx context.Context) (expiringValue, error) {
+		return expiringValue{N: 9, ExpiresAt: time.Now().Add(-time.Second)}, nil
+	}
+	_, err = loader.Get(ctx, "expired", expired)
+	require.NoError(t, err)
+	_, found, err := loader.store.Get(ctx, "expired")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
@@ -0,0 +1,305 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrBackendClosed is returned by a closed RESPBackend
+var ErrBackendClosed = errors.New("cache backend is closed")
+
+// maxBulkLength bounds bulk strings read from the server, matching Redis's own limit
+const maxBulkLength = 512 << 20
+
+// RESPError is an error reply from the server, e.g. "WRONGPASS invalid password"
+type RESPError string
+
+func (e RESPError) Error() string {
+	return "cache server: " + string(e)
+}
+
+// RESPConfig configures a RESPBackend
+type RESPConfig struct {
+	Addr     string // host:port of a Redis-protocol server
+	Password string // sent with AUTH when set
+	DB       int    // selected with SELECT when non-zero
+	Timeout  time.Duration
+	MaxIdle  int // idle connections kept for reuse; defaults to 4
+}
+
+// RESPBackend is a Backend on a Redis-protocol (RESP2) server, shared by every replica
+// pointing at it. Connections are pooled; one that fails mid-command is discarded.
+type RESPBackend struct {
+	cfg RESPConfig
+
+	mu     sync.Mutex
+	idle   []*respConn
+	closed bool
+}
+
+// respConn is one authenticated server connection
+type respConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// NewRESPBackend connects to the server and checks it answers PING
+func NewRESPBackend(ctx context.Context, cfg RESPConfig) (*RESPBackend, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("cache server address is required")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.MaxIdle == 0 {
+		cfg.MaxIdle = 4
+	}
+
+	b := &RESPBackend{cfg: cfg}
+	reply, err := b.do(ctx, "PING")
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach cache server: %w", err)
+	}
+	if reply.str != "PONG" {
+		return nil, fmt.Errorf("unexpected PING reply from cache server: %q", reply.str)
+	}
+	return b, nil
+}
+
+func (b *RESPBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := b.do(ctx, "GET", key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply.null {
+		return nil, false, nil
+	}
+	if reply.kind != '$' {
+		return nil, false, fmt.Errorf("unexpected GET reply type %q", reply.kind)
+	}
+	return reply.bulk, true, nil
+}
+
+func (b *RESPBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	millis := ttl.Milliseconds()
+	if millis <= 0 {
+		return ErrInvalidTTL
+	}
+	_, err := b.do(ctx, "SET", key, string(value), "PX", strconv.FormatInt(millis, 10))
+	return err
+}
+
+func (b *RESPBackend) Delete(ctx context.Context, key string) error {
+	_, err := b.do(ctx, "DEL", key)
+	return err
+}
+
+// Close closes idle connections; connections in use are closed when returned
+func (b *RESPBackend) Close() error {
+	b.mu.Lock()
+	idle := b.idle
+	b.idle, b.closed = nil, true
+	b.mu.Unlock()
+
+	for _, c := range idle {
+		c.conn.Close()
+	}
+	return nil
+}
+
+// do runs one command, returning error replies as RESPError
+func (b *RESPBackend) do(ctx context.Context, args ...string) (respReply, error) {
+	c, err := b.acquire(ctx)
+	if err != nil {
+		return respReply{}, err
+	}
+
+	reply, err := c.roundTrip(ctx, b.cfg.Timeout, args)
+	if err != nil {
+		// The connection state is unknown after an I/O error
+		c.conn.Close()
+		return respReply{}, err
+	}
+	b.release(c)
+
+	if reply.kind == '-' {
+		return respReply{}, RESPError(reply.str)
+	}
+	return reply, nil
+}
+
+// acquire returns an idle connection or dials and authenticates a new one
+func (b *RESPBackend) acquire(ctx context.Context) (*respConn, error) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrBackendClosed
+	}
+	if n := len(b.idle); n > 0 {
+		c := b.idle[n-1]
+		b.idle = b.idle[:n-1]
+		b.mu.Unlock()
+		return c, nil
+	}
+	b.mu.Unlock()
+
+	dialer := net.Dialer{Timeout: b.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", b.cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &respConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+
+	var setup [][]string
+	if b.cfg.Password != "" {
+		setup = append(setup, []string{"AUTH", b.cfg.Password})
+	}
+	if b.cfg.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(b.cfg.DB)})
+	}
+	for _, args := range setup {
+		reply, err := c.roundTrip(ctx, b.cfg.Timeout, args)
+		if err == nil && reply.kind == '-' {
+			err = RESPError(reply.str)
+		}
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s failed: %w", args[0], err)
+		}
+	}
+	return c, nil
+}
+
+// release returns a healthy connection to the pool
+func (b *RESPBackend) release(c *respConn) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed || len(b.idle) >= b.cfg.MaxIdle {
+		c.conn.Close()
+		return
+	}
+	b.idle = append(b.idle, c)
+}
+
+// roundTrip writes a command and reads its reply within the context deadline or timeout
+func (c *respConn) roundTrip(ctx context.Context, timeout time.Duration, args []string) (respReply, error) {
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return respReply{}, err
+	}
+
+	// Unblock the connection if the context is cancelled mid-command
+	stop := context.AfterFunc(ctx, func() { c.conn.SetDeadline(time.Now()) })
+
+	var reply respReply
+	err := writeRESPCommand(c.w, args)
+	if err == nil {
+		reply, err = readRESP(c.r)
+	}
+	if !stop() {
+		// The deadline may be reset at any moment, so the connection cannot be reused
+		return respReply{}, ctx.Err()
+	}
+	return reply, err
+}
+
+// respReply is one decoded RESP2 value
+type respReply struct {
+	kind  byte   // '+', '-', ':', '$' or '*'
+	str   string // simple string or error text
+	num   int64
+	bulk  []byte
+	array []respReply
+	null  bool // null bulk string or array
+}
+
+// writeRESPCommand encodes and flushes a command as an array of bulk strings
+func writeRESPCommand(w *bufio.Writer, args []string) error {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return w.Flush()
+}
+
+// readRESPLine reads one CRLF-terminated line without the terminator
+func readRESPLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("malformed RESP line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
+
+// readRESP decodes one value
+func readRESP(r *bufio.Reader) (respReply, error) {
+	line, err := readRESPLine(r)
+	if err != nil {
+		return respReply{}, err
+	}
+	if line == "" {
+		return respReply{}, fmt.Errorf("empty RESP line")
+	}
+
+	reply := respReply{kind: line[0]}
+	body := line[1:]
+	switch reply.kind {
+	case '+', '-':
+		reply.str = body
+	case ':':
+		if reply.num, err = strconv.ParseInt(body, 10, 64); err != nil {
+			return respReply{}, fmt.Errorf("malformed RESP integer %q", body)
+		}
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < -1 || n > maxBulkLength {
+			return respReply{}, fmt.Errorf("malformed RESP bulk length %q", body)
+		}
+		if n == -1 {
+			reply.null = true
+			break
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return respReply{}, err
+		}
+		if buf[n] != '\r' || buf[n+1] != '\n' {
+			return respReply{}, fmt.Errorf("RESP bulk string is not CRLF terminated")
+		}
+		reply.bulk = buf[:n]
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < -1 {
+			return respReply{}, fmt.Errorf("malformed RESP array length %q", body)
+		}
+		if n == -1 {
+			reply.null = true
+			break
+		}
+		for i := 0; i < n; i++ {
+			elem, err := readRESP(r)
+			if err != nil {
+				return respReply{}, err
+			}
+			reply.array = append(reply.array, elem)
+		}
+	default:
+		return respReply{}, fmt.Errorf("unknown RESP type %q", reply.kind)
+	}
+	return reply, nil
+}
@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// respStandIn is an in-process server speaking the subset of the Redis protocol the
+// RESP backend uses
+type respStandIn struct {
+	addr     string
+	password string
+	dials    atomic.Int64
+
+	mu      sync.Mutex
+	values  map[string][]byte
+	expires map[string]time.Time
+}
+
+func newRESPStandIn(t *testing.T, password string) *respStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	s := &respStandIn{
+		addr:     ln.Addr().String(),
+		password: password,
+		values:   make(map[string][]byte),
+		expires:  make(map[string]time.Time),
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.dials.Add(1)
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *respStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	authed := s.password == ""
+	for {
+		cmd, err := readRESP(r)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, arg := range cmd.array {
+			args = append(args, string(arg.bulk))
+		}
+		name := strings.ToUpper(args[0])
+		switch {
+		case name == "AUTH":
+			if args[1] != s.password {
+				w.WriteString("-WRONGPASS invalid username-password pair\r\n")
+				break
+			}
+			authed = true
+			w.WriteString("+OK\r\n")
+		case !authed:
+			w.WriteString("-NOAUTH Authentication required.\r\n")
+		default:
+			w.WriteString(s.exec(name, args[1:]))
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *respStandIn) exec(name string, args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch name {
+	case "PING":
+		return "+PONG\r\n"
+	case "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		value, ok := s.values[args[0]]
+		if !ok || time.Now().After(s.expires[args[0]]) {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "SET":
+		if len(args) != 4 || strings.ToUpper(args[2]) != "PX" {
+			return "-ERR syntax error\r\n"
+		}
+		millis, err := strconv.Atoi(args[3])
+		if err != nil || millis <= 0 {
+			return "-ERR invalid expire time in 'set' command\r\n"
+		}
+		s.values[args[0]] = []byte(args[1])
+		s.expires[args[0]] = time.Now().Add(time.Duration(millis) * time.Millisecond)
+		return "+OK\r\n"
+	case "DEL":
+		_, ok := s.values[args[0]]
+		delete(s.values, args[0])
+		if ok {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	default:
+		return "-ERR unknown command '" + name + "'\r\n"
+	}
+}
+
+type testQuote struct {
+	AgentID string   `json:"agent_id"`
+	Route   []string `json:"route"`
+}
+
+func TestStoreBackends(t *testing.T) {
+	server := newRESPStandIn(t, "")
+	resp, err := NewRESPBackend(context.Background(), RESPConfig{Addr: server.addr})
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Close() })
+
+	for name, backend := range map[string]Backend{
+		"memory": NewMemoryBackend(Options[string, []byte]{MaxEntries: 16}),
+		"resp":   resp,
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewStore[[]testQuote](backend, JSONCodec[[]testQuote]{}, "quotes:")
+			quotes := []testQuote{{AgentID: "agent-001", Route: []string{"ethereum", "base"}}}
+
+			_, ok, err := store.Get(ctx, "k")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			require.NoError(t, store.Set(ctx, "k", quotes, time.Minute))
+			got, ok, err := store.Get(ctx, "k")
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, quotes, got)
+
+			// Keys are namespaced by the store prefix
+			_, ok, err = backend.Get(ctx, "k")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			require.NoError(t, store.Delete(ctx, "k"))
+			_, ok, err = store.Get(ctx, "k")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			require.NoError(t, store.Set(ctx, "short", quotes, 20*time.Millisecond))
+			time.Sleep(30 * time.Millisecond)
+			_, ok, err = store.Get(ctx, "short")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.ErrorIs(t, store.Set(ctx, "k", quotes, 0), ErrInvalidTTL)
+
+			// Values another version can no longer decode surface as errors
+			require.NoError(t, backend.Set(ctx, "quotes:bad", []byte("{"), time.Minute))
+			_, _, err = store.Get(ctx, "bad")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRESPBackendSharedAndPooled(t *testing.T) {
+	server := newRESPStandIn(t, "secret")
+	ctx := context.Background()
+
+	replicaA, err := NewRESPBackend(ctx, RESPConfig{Addr: server.addr, Password: "secret", DB: 2})
+	require.NoError(t, err)
+	replicaB, err := NewRESPBackend(ctx, RESPConfig{Addr: server.addr, Password: "secret"})
+	require.NoError(t, err)
+
+	require.NoError(t, replicaA.Set(ctx, "shared", []byte("binary\r\n\x00value"), time.Minute))
+	value, ok, err := replicaB.Get(ctx, "shared")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []byte("binary\r\n\x00value"), value)
+
+	// Sequential commands reuse one connection per replica
+	for i := 0; i < 10; i++ {
+		_, _, err := replicaA.Get(ctx, "shared")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(2), server.dials.Load())
+
+	require.NoError(t, replicaA.Close())
+	_, _, err = replicaA.Get(ctx, "shared")
+	assert.ErrorIs(t, err, ErrBackendClosed)
+	require.NoError(t, replicaB.Close())
+}
+
+func TestRESPBackendErrors(t *testing.T) {
+	server := newRESPStandIn(t, "secret")
+	ctx := context.Background()
+
+	_, err := NewRESPBackend(ctx, RESPConfig{Addr: server.addr, Password: "wrong"})
+	var respErr RESPError
+	require.True(t, errors.As(err, &respErr), "got %v", err)
+	assert.Contains(t, string(respErr), "WRONGPASS")
+
+	_, err = NewRESPBackend(ctx, RESPConfig{Addr: server.addr})
+	require.True(t, errors.As(err, &respErr), "got %v", err)
+	assert.Contains(t, string(respErr), "NOAUTH")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = NewRESPBackend(cancelled, RESPConfig{Addr: server.addr, Password: "secret"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -2,13 +2,19 @@ package client
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math/big"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/useeasycash/ecash-sdk-core/pkg/agent"
 	"github.com/useeasycash/ecash-sdk-core/pkg/cache"
 	"github.com/useeasycash/ecash-sdk-core/pkg/config"
+	"github.com/useeasycash/ecash-sdk-core/pkg/crypto"
 	sdkerrors "github.com/useeasycash/ecash-sdk-core/pkg/errors"
 	"github.com/useeasycash/ecash-sdk-core/pkg/monitoring"
 	"github.com/useeasycash/ecash-sdk-core/pkg/types"
@@ -19,10 +25,23 @@ import (
 // EasyCashClient is the main entry point for the SDK
 type EasyCashClient struct {
 	config     *config.SDKConfig
-	zk         *zk.ProofGenerator
+	shielded   *zk.ShieldedState
+	wallet     *zk.NoteWallet
 	negotiator *agent.AgentNegotiator
-	cache      *cache.Cache
+	cache      *cache.Cache[string, *types.TransactionResponse]
+	quotes     cache.Backend // shared quote cache, closed with the client
 	metrics    *monitoring.Metrics
+	signer     crypto.Signer
+	remote     *crypto.RemoteSigner // signer connected from the config, closed with the client
+	closeOnce  sync.Once
+
+	// proverMu guards zk and provers, which SetProver replaces while transactions run
+	proverMu sync.RWMutex
+	zk       *zk.ProofGenerator
+	provers  *zk.ProverPool
+
+	approvalPolicy *crypto.ApprovalPolicy
+	approvers      []crypto.Signer
 }
 
 // NewClient initializes a new EasyCash SDK client with full configuration
@@ -35,20 +54,324 @@ func NewClient(cfg *config.SDKConfig) (*EasyCashClient, error) {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "invalid configuration", err)
 	}
 
+	prover, err := zk.NewProver(zk.ProverConfig{
+		Backend:      cfg.ProverBackend,
+		CircuitPath:  cfg.CircuitPath,
+		KeyPath:      cfg.VerificationKeyPath,
+		ManifestPath: cfg.CircuitManifestPath,
+		CircuitID:    cfg.CircuitID,
+		Command:      cfg.ProverCommand,
+		Timeout:      cfg.Timeout,
+	})
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrProofGeneration, "failed to initialize prover", err)
+	}
+
+	shielded := zk.NewShieldedState()
+	if cfg.ShieldedStatePath != "" {
+		if shielded, err = zk.LoadShieldedStateFile(cfg.ShieldedStatePath); err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "failed to load shielded state", err)
+		}
+	}
+
+	var wallet *zk.NoteWallet
+	newKeys := false
+	if cfg.NoteWalletPath != "" {
+		_, err := os.Stat(cfg.NoteWalletPath)
+		newKeys = errors.Is(err, os.ErrNotExist)
+		if wallet, err = zk.LoadNoteWalletFile(cfg.NoteWalletPath); err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "failed to load note wallet", err)
+		}
+	} else {
+		sk, err := zk.NewSpendingKey()
+		if err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "failed to create note wallet", err)
+		}
+		wallet = zk.NewNoteWalletFromSpendingKey(sk)
+	}
+	if wallet.ViewingKey() == nil {
+		vk, err := zk.NewViewingKey()
+		if err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "failed to create viewing key", err)
+		}
+		wallet.SetViewingKey(vk)
+		newKeys = true
+	}
+	// Save new keys right away: notes sent to the receiving address before the first
+	// shielded operation must still decrypt after a restart
+	if newKeys && cfg.NoteWalletPath != "" {
+		if err := wallet.SaveFile(cfg.NoteWalletPath); err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrStorageFailure, "failed to save note wallet", err)
+		}
+	}
+	wallet.SyncSpent(shielded)
+
 	client := &EasyCashClient{
 		config:     cfg,
-		zk:         zk.NewProofGenerator("./circuits/spend.wasm"),
+		zk:         zk.NewProofGenerator(prover),
+		shielded:   shielded,
+		wallet:     wallet,
 		negotiator: agent.NewNegotiator(cfg.Timeout),
 		metrics:    monitoring.GetMetrics(),
 	}
 
 	if cfg.EnableCaching {
-		client.cache = cache.NewCache(cfg.CacheTTL)
+		client.cache = cache.New(cache.Options[string, *types.TransactionResponse]{
+			TTL:             cfg.CacheTTL,
+			MaxEntries:      cfg.CacheMaxEntries,
+			CleanupInterval: cfg.CacheSweepInterval,
+		})
+	}
+	if cfg.ProofCacheTTL > 0 {
+		client.zk.SetCache(zk.NewProofCache(cfg.ProofCacheTTL))
+	}
+	client.provers = client.newProverPool(client.zk)
+
+	if cfg.QuoteCacheTTL > 0 {
+		if client.quotes, err = newCacheBackend(cfg); err != nil {
+			client.Close()
+			return nil, err
+		}
+		client.negotiator.SetQuoteCache(cache.NewLoader(client.quotes, cache.JSONCodec[[]agent.RouteQuote]{}, "ecash:quotes:v2:",
+			cache.LoaderOptions[[]agent.RouteQuote]{
+				TTL:      cfg.QuoteCacheTTL,
+				StaleTTL: cfg.QuoteStaleTTL,
+				Expiry:   agent.QuotesExpiry,
+				OnError:  func(err error) { fmt.Printf("[SDK] Quote cache unavailable: %v\n", err) },
+			}))
+	}
+
+	if cfg.ApprovalLimit != "" {
+		if _, ok := new(big.Rat).SetString(cfg.ApprovalLimit); !ok {
+			client.Close()
+			return nil, sdkerrors.New(sdkerrors.ErrInvalidRequest, "invalid approval limit: "+cfg.ApprovalLimit)
+		}
+	}
+
+	if cfg.SignerEndpoint != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+		defer cancel()
+
+		signer, err := crypto.NewRemoteSigner(ctx, crypto.RemoteSignerConfig{
+			Endpoint: cfg.SignerEndpoint,
+			KeyID:    cfg.SignerKeyID,
+			Token:    cfg.SignerToken,
+			Timeout:  cfg.Timeout,
+		})
+		if err != nil {
+			client.Close()
+			return nil, sdkerrors.Wrap(sdkerrors.ErrSigningFailed, "failed to connect to remote signer", err)
+		}
+		client.signer = signer
+		client.remote = signer
 	}
 
 	return client, nil
 }
 
+// newCacheBackend connects the quote cache backend selected by the config
+func newCacheBackend(cfg *config.SDKConfig) (cache.Backend, error) {
+	switch cfg.CacheBackend {
+	case "", "memory":
+		return cache.NewMemoryBackend(cache.Options[string, []byte]{
+			MaxEntries:      cfg.CacheMaxEntries,
+			CleanupInterval: cfg.CacheSweepInterval,
+		}), nil
+	case "redis":
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+		defer cancel()
+
+		backend, err := cache.NewRESPBackend(ctx, cache.RESPConfig{
+			Addr:     cfg.CacheAddr,
+			Password: cfg.CachePassword,
+			Timeout:  cfg.Timeout,
+		})
+		if err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrNetworkFailure, "failed to connect to cache server", err)
+		}
+		return backend, nil
+	default:
+		return nil, sdkerrors.New(sdkerrors.ErrInvalidRequest, "unknown cache backend: "+cfg.CacheBackend)
+	}
+}
+
+// Close stops the response cache and the prover pool, failing queued proof jobs, and
+// releases connections to the cache server and remote signer. The client must not be
+// used afterwards.
+func (c *EasyCashClient) Close() error {
+	var errs []error
+	c.closeOnce.Do(func() {
+		if c.cache != nil {
+			c.cache.Close()
+		}
+		_, provers := c.proving()
+		provers.Close()
+		if c.quotes != nil {
+			errs = append(errs, c.quotes.Close())
+		}
+		if c.remote != nil {
+			errs = append(errs, c.remote.Close())
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// SetSigner configures the signer used to authorize intents, replacing any remote signer from the config
+func (c *EasyCashClient) SetSigner(signer crypto.Signer) {
+	c.signer = signer
+}
+
+// SetProver replaces the proof backend selected by the config. The proof cache is
+// kept; its entries are keyed by verification key. Jobs queued for the old backend fail.
+// It is safe to call while transactions are running.
+func (c *EasyCashClient) SetProver(prover zk.Prover) {
+	c.proverMu.Lock()
+	pg := zk.NewProofGenerator(prover)
+	pg.SetCache(c.zk.Cache())
+	c.zk = pg
+
+	old := c.provers
+	c.provers = c.newProverPool(pg)
+	c.proverMu.Unlock()
+
+	old.Close()
+}
+
+// ProverPool returns the pool shielded proofs are generated on; batch callers can
+// submit their own jobs to it
+func (c *EasyCashClient) ProverPool() *zk.ProverPool {
+	_, provers := c.proving()
+	return provers
+}
+
+// proving returns the current proof generator and the pool running it
+func (c *EasyCashClient) proving() (*zk.ProofGenerator, *zk.ProverPool) {
+	c.proverMu.RLock()
+	defer c.proverMu.RUnlock()
+	return c.zk, c.provers
+}
+
+// newProverPool starts a worker pool for pg
+func (c *EasyCashClient) newProverPool(pg *zk.ProofGenerator) *zk.ProverPool {
+	cfg := zk.ProverPoolConfig{Workers: c.config.ProverWorkers, MaxQueue: c.config.ProverQueueSize}
+	if c.config.EnableMetrics {
+		cfg.Metrics = c.metrics
+	}
+	return zk.NewProverPool(pg, cfg)
+}
+
+// SetApprovalPolicy requires M-of-N approvals for transfers above config.ApprovalLimit;
+// until it is called such transfers are refused.
+// Approvers are asked to sign when a request arrives without an approval bundle; pass none
+// if bundles are always collected out of band.
+func (c *EasyCashClient) SetApprovalPolicy(policy crypto.ApprovalPolicy, approvers ...crypto.Signer) error {
+	if err := policy.Validate(); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "invalid approval policy", err)
+	}
+	c.approvalPolicy = &policy
+	c.approvers = approvers
+	return nil
+}
+
+// requiresApproval reports whether the request amount exceeds the configured approval limit
+func (c *EasyCashClient) requiresApproval(req *types.TransactionRequest) bool {
+	if c.config.ApprovalLimit == "" {
+		return false
+	}
+	limit, ok := new(big.Rat).SetString(c.config.ApprovalLimit)
+	if !ok {
+		return false
+	}
+	amount, ok := new(big.Rat).SetString(req.Amount)
+	// Unparseable amounts never skip approval
+	return !ok || amount.Cmp(limit) > 0
+}
+
+// approveIntent collects approvals (unless a bundle is already attached) and verifies the
+// bundle. Amounts above the limit are refused while no approval policy is set.
+func (c *EasyCashClient) approveIntent(ctx context.Context, req *types.TransactionRequest) error {
+	if !c.requiresApproval(req) {
+		return nil
+	}
+	if c.approvalPolicy == nil {
+		return fmt.Errorf("amount exceeds the approval limit %s and no approval policy is set", c.config.ApprovalLimit)
+	}
+
+	if req.Approval == nil && len(c.approvers) > 0 {
+		coordinator, err := crypto.NewApprovalCoordinator(*c.approvalPolicy, req, c.config.IntentVerifyingContract)
+		if err != nil {
+			return err
+		}
+		if err := coordinator.Collect(ctx, c.approvers...); err != nil {
+			return err
+		}
+		bundle, err := coordinator.Bundle()
+		if err != nil {
+			return err
+		}
+		req.Approval = bundle
+	}
+
+	return crypto.VerifyApprovalBundle(*c.approvalPolicy, req, c.config.IntentVerifyingContract)
+}
+
+// intentOf copies the caller's request so that deriving, signing and approving the
+// intent never write into a struct the caller may reuse for another transfer
+func intentOf(req *types.TransactionRequest) *types.TransactionRequest {
+	intent := *req
+	if req.Stealth != nil {
+		stealth := *req.Stealth
+		intent.Stealth = &stealth
+	}
+	return &intent
+}
+
+// resolveStealthRecipient sets a fresh one-time Recipient for a stealth payment and
+// records the announcement data the recipient scans for. It runs before signing so
+// the signature covers the derived recipient.
+func resolveStealthRecipient(req *types.TransactionRequest) error {
+	if req.Stealth == nil || req.Stealth.EphemeralPubKey != "" {
+		return nil
+	}
+	meta, err := crypto.ParseStealthMetaAddress(req.Stealth.MetaAddress)
+	if err != nil {
+		return err
+	}
+	ann, err := crypto.GenerateStealthAddress(meta)
+	if err != nil {
+		return err
+	}
+	req.Recipient = ann.Address
+	req.Stealth.EphemeralPubKey = "0x" + hex.EncodeToString(ann.EphemeralPubKey)
+	req.Stealth.ViewTag = ann.ViewTag
+	return nil
+}
+
+// signIntent authorizes the request with the configured signer unless it is already signed
+func (c *EasyCashClient) signIntent(ctx context.Context, req *types.TransactionRequest) error {
+	if c.signer == nil || req.Signature != "" {
+		return nil
+	}
+
+	digest, err := crypto.IntentDigest(req, c.config.IntentVerifyingContract)
+	if err != nil {
+		return err
+	}
+	sig, err := c.signer.Sign(ctx, digest)
+	if err != nil {
+		return err
+	}
+	req.Signature = "0x" + hex.EncodeToString(sig)
+	return nil
+}
+
+// cachesResponse reports whether the request's response may be served from or stored in
+// the response cache. Shielded spends and stealth payments are never shared: their notes,
+// proofs and one-time recipients belong to a single transfer.
+func (c *EasyCashClient) cachesResponse(req *types.TransactionRequest) bool {
+	return c.config.EnableCaching && c.cache != nil && !req.IsShielded && req.Stealth == nil
+}
+
 // ExecuteTransaction constructs a transfer intent and executes it with full validation
 func (c *EasyCashClient) ExecuteTransaction(ctx context.Context, req *types.TransactionRequest) (*types.TransactionResponse, error) {
 	startTime := time.Now()
@@ -65,26 +388,54 @@ func (c *EasyCashClient) ExecuteTransaction(ctx context.Context, req *types.Tran
 	if err := validator.ValidateTransactionRequest(req); err != nil {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "validation failed", err)
 	}
+	req = intentOf(req)
+
+	// 1a. Derive a one-time recipient for stealth payments
+	if err := resolveStealthRecipient(req); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "failed to derive stealth address", err)
+	}
+
+	// 1b. Authorize intent with the configured signer
+	if err := c.signIntent(ctx, req); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrSigningFailed, "failed to sign intent", err)
+	}
+
+	// 1c. Enforce M-of-N approval for amounts above the approval limit
+	if err := c.approveIntent(ctx, req); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrApprovalRequired, "intent approval failed", err)
+	}
 
 	// 2. Check Cache for similar recent transactions
-	if c.config.EnableCaching && c.cache != nil {
+	if c.cachesResponse(req) {
 		cacheKey := fmt.Sprintf("%s-%s-%s", req.Type, req.Amount, req.Asset)
-		if cached, found := c.cache.Get(cacheKey); found {
+		if resp, found := c.cache.Get(cacheKey); found {
 			fmt.Println("[SDK] Cache hit for transaction pattern")
-			if resp, ok := cached.(*types.TransactionResponse); ok {
-				success = true
-				return resp, nil
-			}
+			success = true
+			return resp, nil
 		}
 	}
 
-	// 3. Generate ZK Proof if shielded
+	// 3. Reserve shielded notes and generate the ZK proof
+	var spend *shieldedSpend
+	var envelope *zk.ProofEnvelope
 	if c.config.EnableZKProofs && req.IsShielded {
-		proof, err := c.zk.GenerateSolvencyProof(req.Amount, "0")
+		var err error
+		if spend, err = c.beginShieldedSpend(req); err != nil {
+			return nil, err
+		}
+		// Released unless the spend is committed below
+		defer spend.abort()
+
+		// The proof and its envelope come from the same backend even if SetProver runs
+		pg, provers := c.proving()
+		proof, err := c.proveShieldedSpend(ctx, pg, provers, spend)
 		if err != nil {
 			return nil, sdkerrors.Wrap(sdkerrors.ErrProofGeneration, "failed to generate privacy proof", err)
 		}
-		fmt.Printf("[SDK] Generated ZK Proof: %s...\n", proof[:10])
+		if envelope, err = pg.Envelope(proof, *spend.inputs); err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrProofGeneration, "failed to encode privacy proof", err)
+		}
+		fmt.Printf("[SDK] Generated ZK Proof: %s (%s, %d bytes)\n", envelope.ID(), envelope.CircuitID, len(envelope.Proof))
 	}
 
 	// 4. Request quotes from agents
@@ -122,8 +473,25 @@ func (c *EasyCashClient) ExecuteTransaction(ctx context.Context, req *types.Tran
 		FeeUsed:     bestRoute.EstimatedFee,
 	}
 
+	// 7b. Record the spend: notes become spent and the outputs join the note tree
+	if spend != nil {
+		if err := c.commitShieldedSpend(spend); err != nil {
+			return nil, err
+		}
+		if spend.encrypted != nil {
+			resp.EncryptedNote = spend.encrypted.String()
+		}
+	}
+	if envelope != nil {
+		resp.Proof = envelope.String()
+	}
+	if req.Stealth != nil {
+		resp.Recipient = req.Recipient
+		resp.Stealth = req.Stealth
+	}
+
 	// 8. Cache successful result
-	if c.config.EnableCaching && c.cache != nil {
+	if c.cachesResponse(req) {
 		cacheKey := fmt.Sprintf("%s-%s-%s", req.Type, req.Amount, req.Asset)
 		c.cache.Set(cacheKey, resp)
 	}
@@ -136,5 +504,15 @@ func (c *EasyCashClient) GetMetrics() map[string]interface{} {
 	if !c.config.EnableMetrics {
 		return map[string]interface{}{"metrics_disabled": true}
 	}
-	return c.metrics.GetStats()
+	stats := c.metrics.GetStats()
+	pg, _ := c.proving()
+	if cache := pg.Cache(); cache != nil {
+		// proof_cache_hits counts the pool's cached proofs; these count the cache's own lookups
+		proofStats := cache.Stats()
+		stats["proof_cache_lookups_hit"] = proofStats.Hits
+		stats["proof_cache_lookups_missed"] = proofStats.Misses
+		stats["proof_cache_invalidations"] = proofStats.Invalidations
+		stats["proof_cache_entries"] = proofStats.Entries
+	}
+	return stats
 }
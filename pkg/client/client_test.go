@@ -0,0 +1,336 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/useeasycash/ecash-sdk-core/pkg/config"
+	sdkerrors "github.com/useeasycash/ecash-sdk-core/pkg/errors"
+	"github.com/useeasycash/ecash-sdk-core/pkg/types"
+	"github.com/useeasycash/ecash-sdk-core/pkg/zk"
+)
+
+const testRecipient = "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+
+// newTestClient returns a client whose note tree lives at statePath and whose wallet
+// lives at walletPath
+func newTestClient(t *testing.T, statePath, walletPath string) *EasyCashClient {
+	cfg := config.DefaultConfig()
+	cfg.ProverBackend = zk.BackendReference
+	cfg.ProverWorkers = 1
+	cfg.QuoteCacheTTL = 0
+	cfg.EnableMetrics = false
+	cfg.ShieldedStatePath = statePath
+	cfg.NoteWalletPath = walletPath
+
+	c, err := NewClient(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func shieldedRequest(amount string) *types.TransactionRequest {
+	return &types.TransactionRequest{
+		Type:        types.IntentTransfer,
+		Amount:      amount,
+		Asset:       "USDC",
+		Recipient:   testRecipient,
+		SourceChain: types.ChainBase,
+		IsShielded:  true,
+	}
+}
+
+func units(t *testing.T, amount string) uint64 {
+	u, err := zk.ParseAmount(amount)
+	require.NoError(t, err)
+	return u
+}
+
+func requireCode(t *testing.T, err error, code sdkerrors.ErrorCode) {
+	var sdkErr *sdkerrors.SDKError
+	require.True(t, errors.As(err, &sdkErr), "expected *SDKError, got %v", err)
+	assert.Equal(t, code, sdkErr.Code, "got %v", err)
+}
+
+// selectedNullifiers returns the nullifiers of the notes the wallet would spend for
+// amount, leaving them unreserved
+func selectedNullifiers(t *testing.T, c *EasyCashClient, amount string) []string {
+	selection, err := c.NoteWallet().SelectNotes("USDC", units(t, amount))
+	require.NoError(t, err)
+	defer selection.Release()
+
+	var out []string
+	for _, n := range selection.Nullifiers() {
+		out = append(out, n.String())
+	}
+	return out
+}
+
+func TestShieldedTransaction(t *testing.T) {
+	dir := t.TempDir()
+	alice := newTestClient(t, filepath.Join(dir, "alice-state.json"), filepath.Join(dir, "alice.json"))
+	bob := newTestClient(t, filepath.Join(dir, "bob-state.json"), filepath.Join(dir, "bob.json"))
+	ctx := context.Background()
+
+	_, err := alice.ShieldFunds("USDC", "100")
+	require.NoError(t, err)
+	assert.Equal(t, units(t, "100"), alice.NoteWallet().Balance("USDC"))
+
+	req := shieldedRequest("60")
+	req.RecipientKey = bob.ReceivingAddress()
+	req.Memo = "invoice 42"
+	resp, err := alice.ExecuteTransaction(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "confirmed", resp.Status)
+	assert.NotEmpty(t, resp.Proof)
+	require.NotEmpty(t, resp.EncryptedNote)
+
+	// The spent note is replaced by a change note for the remainder
+	assert.Equal(t, units(t, "40"), alice.NoteWallet().Balance("USDC"))
+	notes := alice.NoteWallet().Notes()
+	require.Len(t, notes, 2)
+	assert.True(t, notes[0].Spent)
+	assert.False(t, notes[1].Spent)
+	assert.Equal(t, units(t, "40"), notes[1].Amount)
+	assert.True(t, alice.ShieldedState().IsSpent(alice.NoteWallet().Notes()[0].Nullifier))
+
+	// The proven balance is what the spent note holds
+	envelope, err := zk.ParseProofEnvelopeString(resp.Proof)
+	require.NoError(t, err)
+	assert.NoError(t, envelope.VerifySpentNotes([]*zk.Commitment{notes[0].Commitment}))
+	assert.Error(t, envelope.VerifySpentNotes([]*zk.Commitment{notes[1].Commitment}))
+
+	// The output note is not recorded before its commitment is in bob's note tree
+	received, err := bob.ReceiveNotes(resp.EncryptedNote)
+	requireCode(t, err, sdkerrors.ErrInvalidRequest)
+	assert.ErrorIs(t, err, zk.ErrNoteNotInTree)
+	assert.Empty(t, received)
+	assert.Zero(t, bob.NoteWallet().Balance("USDC"))
+
+	// Bob mirrors the commitments alice published, in leaf order
+	enc, err := zk.ParseEncryptedNoteString(resp.EncryptedNote)
+	require.NoError(t, err)
+	leaves := make([]string, 3)
+	leaves[enc.LeafIndex] = enc.Commitment.String()
+	for _, note := range notes {
+		leaves[note.LeafIndex] = note.Commitment.String()
+	}
+	require.NoError(t, bob.SyncNoteTree(0, leaves...))
+	require.NoError(t, bob.SyncNoteTree(1, leaves[1:]...))
+	assert.Equal(t, alice.ShieldedState().Root(), bob.ShieldedState().Root())
+
+	// The output note decrypts for the recipient key only
+	received, err = bob.ReceiveNotes(resp.EncryptedNote)
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	assert.Equal(t, units(t, "60"), received[0].Note.Amount)
+	assert.Equal(t, "invoice 42", string(received[0].Memo))
+	assert.Equal(t, units(t, "60"), bob.NoteWallet().Balance("USDC"))
+
+	received, err = alice.ReceiveNotes(resp.EncryptedNote)
+	require.NoError(t, err)
+	assert.Empty(t, received)
+
+	// Bob spends the received note from his own state
+	_, err = bob.ExecuteTransaction(ctx, shieldedRequest("60"))
+	require.NoError(t, err)
+	assert.Zero(t, bob.NoteWallet().Balance("USDC"))
+}
+
+func TestSyncNoteTree(t *testing.T) {
+	c := newTestClient(t, "", "")
+	note, err := zk.NewNote("USDC", units(t, "5"))
+	require.NoError(t, err)
+	other, err := zk.NewNote("USDC", units(t, "5"))
+	require.NoError(t, err)
+	require.NoError(t, c.SyncNoteTree(0, note.Commitment.String()))
+	root := c.ShieldedState().Root()
+
+	// A gap or a conflicting leaf leaves the tree untouched
+	requireCode(t, c.SyncNoteTree(2, other.Commitment.String()), sdkerrors.ErrInvalidRequest)
+	requireCode(t, c.SyncNoteTree(0, other.Commitment.String(), note.Commitment.String()), sdkerrors.ErrInvalidRequest)
+	requireCode(t, c.SyncNoteTree(1, "0x1234"), sdkerrors.ErrInvalidRequest)
+	assert.Equal(t, root, c.ShieldedState().Root())
+	assert.True(t, c.ShieldedState().HasCommitment(0, note.Commitment))
+	assert.False(t, c.ShieldedState().HasCommitment(1, other.Commitment))
+}
+
+func TestShieldedDoubleSpend(t *testing.T) {
+	c := newTestClient(t, "", "")
+	ctx := context.Background()
+
+	_, err := c.ShieldFunds("USDC", "100")
+	require.NoError(t, err)
+	nullifiers := selectedNullifiers(t, c, "50")
+
+	req := shieldedRequest("50")
+	req.Nullifiers = nullifiers
+	_, err = c.ExecuteTransaction(ctx, req)
+	require.NoError(t, err)
+
+	// The same request again names notes that are already spent; it is neither
+	// accepted nor served from the response cache
+	req = shieldedRequest("50")
+	req.Nullifiers = nullifiers
+	_, err = c.ExecuteTransaction(ctx, req)
+	requireCode(t, err, sdkerrors.ErrDoubleSpend)
+	assert.Equal(t, units(t, "50"), c.NoteWallet().Balance("USDC"))
+}
+
+func TestShieldedInsufficientFunds(t *testing.T) {
+	c := newTestClient(t, "", "")
+	ctx := context.Background()
+
+	_, err := c.ExecuteTransaction(ctx, shieldedRequest("10"))
+	requireCode(t, err, sdkerrors.ErrInsufficientFunds)
+
+	_, err = c.ShieldFunds("USDC", "100")
+	require.NoError(t, err)
+	_, err = c.ExecuteTransaction(ctx, shieldedRequest("100.5"))
+	requireCode(t, err, sdkerrors.ErrInsufficientFunds)
+
+	// The failed attempt released its notes
+	_, err = c.ExecuteTransaction(ctx, shieldedRequest("100"))
+	require.NoError(t, err)
+	assert.Zero(t, c.NoteWallet().Balance("USDC"))
+}
+
+func TestShieldedRejectsUnusableRecipientKey(t *testing.T) {
+	c := newTestClient(t, "", "")
+	_, err := c.ShieldFunds("USDC", "100")
+	require.NoError(t, err)
+
+	// A small-order key is refused before any note is spent
+	req := shieldedRequest("40")
+	req.RecipientKey = "0x" + strings.Repeat("00", 32)
+	_, err = c.ExecuteTransaction(context.Background(), req)
+	requireCode(t, err, sdkerrors.ErrInvalidRequest)
+	assert.Equal(t, units(t, "100"), c.NoteWallet().Balance("USDC"))
+	require.Len(t, c.NoteWallet().Notes(), 1)
+	assert.False(t, c.NoteWallet().Notes()[0].Spent)
+}
+
+func TestShieldedStateSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	statePath, walletPath := filepath.Join(dir, "state.json"), filepath.Join(dir, "wallet.json")
+	ctx := context.Background()
+
+	c := newTestClient(t, statePath, walletPath)
+	address := c.ReceivingAddress()
+	_, err := c.ShieldFunds("USDC", "100")
+	require.NoError(t, err)
+	nullifiers := selectedNullifiers(t, c, "30")
+
+	req := shieldedRequest("30")
+	req.Nullifiers = nullifiers
+	_, err = c.ExecuteTransaction(ctx, req)
+	require.NoError(t, err)
+	root := c.ShieldedState().Root()
+	require.NoError(t, c.Close())
+
+	// The restarted client keeps its keys, change note and spent nullifiers
+	restarted := newTestClient(t, statePath, walletPath)
+	assert.Equal(t, address, restarted.ReceivingAddress())
+	assert.Equal(t, root, restarted.ShieldedState().Root())
+	assert.Equal(t, units(t, "70"), restarted.NoteWallet().Balance("USDC"))
+	for _, s := range nullifiers {
+		n, err := zk.ParseNullifier(s)
+		require.NoError(t, err)
+		assert.True(t, restarted.ShieldedState().IsSpent(n))
+	}
+
+	req = shieldedRequest("30")
+	req.Nullifiers = nullifiers
+	_, err = restarted.ExecuteTransaction(ctx, req)
+	requireCode(t, err, sdkerrors.ErrDoubleSpend)
+
+	_, err = restarted.ExecuteTransaction(ctx, shieldedRequest("70"))
+	require.NoError(t, err)
+	assert.Zero(t, restarted.NoteWallet().Balance("USDC"))
+}
+
+func TestNewClientSavesNewKeys(t *testing.T) {
+	walletPath := filepath.Join(t.TempDir(), "wallet.json")
+	c := newTestClient(t, "", walletPath)
+	address := c.ReceivingAddress()
+
+	// Notes sent before the first shielded operation still reach the reloaded wallet
+	sender := newTestClient(t, "", "")
+	note, err := zk.NewNote("USDC", units(t, "5"))
+	require.NoError(t, err)
+	recipient, err := zk.ParseReceivingAddress(address)
+	require.NoError(t, err)
+	enc, err := zk.EncryptNote(recipient, &sender.NoteWallet().ViewingKey().Outgoing, note, nil)
+	require.NoError(t, err)
+
+	reloaded := newTestClient(t, "", walletPath)
+	assert.Equal(t, address, reloaded.ReceivingAddress())
+	require.NoError(t, reloaded.SyncNoteTree(0, note.Commitment.String()))
+	received, err := reloaded.ReceiveNotes(enc.String())
+	require.NoError(t, err)
+	assert.Len(t, received, 1)
+}
+
+func TestSetProverWhileTransacting(t *testing.T) {
+	c := newTestClient(t, "", "")
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		_, err := c.ShieldFunds("USDC", "10")
+		require.NoError(t, err)
+	}
+
+	// Spends in flight may fail with the pool they were queued on, but never race
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = c.ExecuteTransaction(ctx, shieldedRequest("10"))
+			c.GetMetrics()
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		c.SetProver(zk.NewReferenceProver(zk.NewVerificationKey(zk.SolvencyCircuitID)))
+	}
+	wg.Wait()
+
+	_, err := c.ExecuteTransaction(ctx, shieldedRequest("10"))
+	require.NoError(t, err)
+}
+
+func TestClientClose(t *testing.T) {
+	c := newTestClient(t, "", "")
+
+	require.NoError(t, c.Close())
+	require.NoError(t, c.Close())
+
+	_, err := c.ProverPool().Submit(context.Background(), zk.ProofJob{Priority: zk.PriorityNormal})
+	assert.ErrorIs(t, err, zk.ErrPoolClosed)
+}
+
+func TestGetMetricsKeepsPoolAndCacheCounters(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ProverBackend = zk.BackendReference
+	cfg.ProverWorkers = 1
+	cfg.EnableMetrics = true
+	c, err := NewClient(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+
+	_, err = c.ShieldFunds("USDC", "100")
+	require.NoError(t, err)
+	_, err = c.ExecuteTransaction(context.Background(), shieldedRequest("10"))
+	require.NoError(t, err)
+
+	// The pool's counter and the cache's own lookups are reported under separate keys
+	stats := c.GetMetrics()
+	assert.IsType(t, int64(0), stats["proof_cache_hits"])
+	assert.IsType(t, uint64(0), stats["proof_cache_lookups_hit"])
+	assert.Equal(t, uint64(1), stats["proof_cache_lookups_missed"])
+}
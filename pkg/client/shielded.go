@@ -0,0 +1,314 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sdkerrors "github.com/useeasycash/ecash-sdk-core/pkg/errors"
+	"github.com/useeasycash/ecash-sdk-core/pkg/types"
+	"github.com/useeasycash/ecash-sdk-core/pkg/zk"
+)
+
+// ShieldedState returns the local note commitment tree and nullifier set
+func (c *EasyCashClient) ShieldedState() *zk.ShieldedState {
+	return c.shielded
+}
+
+// NoteWallet returns the wallet tracking the client's shielded notes
+func (c *EasyCashClient) NoteWallet() *zk.NoteWallet {
+	return c.wallet
+}
+
+// GetShieldedBalance returns the unspent shielded balance of an asset as a decimal string
+func (c *EasyCashClient) GetShieldedBalance(asset string) string {
+	return zk.FormatAmount(c.wallet.Balance(asset))
+}
+
+// ReceivingAddress returns the address counterparties encrypt shielded notes to
+func (c *EasyCashClient) ReceivingAddress() string {
+	return c.wallet.ViewingKey().Address().String()
+}
+
+// ViewingKey returns the wallet's viewing key. It reads the full shielded history but
+// cannot spend, and is what an auditor is given.
+func (c *EasyCashClient) ViewingKey() string {
+	return c.wallet.ViewingKey().String()
+}
+
+// DiscloseTransaction proves the amount and recipient of one shielded transaction,
+// given its encrypted note, to a third party without sharing the viewing key
+func (c *EasyCashClient) DiscloseTransaction(encryptedNote string) (*zk.Disclosure, error) {
+	enc, err := zk.ParseEncryptedNoteString(encryptedNote)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "invalid encrypted note", err)
+	}
+	d, err := c.wallet.ViewingKey().Disclose(enc)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "failed to disclose transaction", err)
+	}
+	return d, nil
+}
+
+// SyncNoteTree mirrors note commitments published from leaf start on into the local
+// note tree, so notes received at those leaves can be recorded and spent
+func (c *EasyCashClient) SyncNoteTree(start uint64, commitments ...string) error {
+	parsed := make([]*zk.Commitment, 0, len(commitments))
+	for _, s := range commitments {
+		commitment, err := zk.ParseCommitmentString(s)
+		if err != nil {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "invalid note commitment", err)
+		}
+		parsed = append(parsed, commitment)
+	}
+
+	added, err := c.shielded.SyncCommitments(start, parsed)
+	if err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "failed to sync note tree", err)
+	}
+	if added > 0 {
+		if err := c.persistShielded(); err != nil {
+			return sdkerrors.Wrap(sdkerrors.ErrStorageFailure, "failed to save note tree", err)
+		}
+	}
+	return nil
+}
+
+// ReceiveNotes trial-decrypts encrypted notes (as returned in TransactionResponse) and
+// records the ones addressed to this client. It returns the notes received. Notes whose
+// commitment is not yet in the local note tree are not recorded, since they could not
+// be spent; the error then wraps zk.ErrNoteNotInTree and they can be received again
+// after SyncNoteTree.
+func (c *EasyCashClient) ReceiveNotes(encrypted ...string) ([]zk.ScannedNote, error) {
+	notes := make([]*zk.EncryptedNote, 0, len(encrypted))
+	for _, s := range encrypted {
+		enc, err := zk.ParseEncryptedNoteString(s)
+		if err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "invalid encrypted note", err)
+		}
+		notes = append(notes, enc)
+	}
+
+	received, err := c.wallet.Receive(c.shielded, notes...)
+	if len(received) > 0 {
+		if err := c.persistShielded(); err != nil {
+			return received, sdkerrors.Wrap(sdkerrors.ErrStorageFailure, "failed to save received notes", err)
+		}
+	}
+	if errors.Is(err, zk.ErrNoteNotInTree) {
+		return received, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "received notes are not in the local note tree, sync it first", err)
+	}
+	if err != nil {
+		return received, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "failed to receive notes", err)
+	}
+	return received, nil
+}
+
+// ShieldFunds records a note created by shielding amount of asset: its commitment joins
+// the note tree and the note becomes spendable by this client
+func (c *EasyCashClient) ShieldFunds(asset, amount string) (*zk.Note, error) {
+	units, err := zk.ParseAmount(amount)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "invalid amount", err)
+	}
+	note, err := zk.NewNote(asset, units)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrProofGeneration, "failed to create note", err)
+	}
+	if note.LeafIndex, err = c.shielded.AddCommitment(note.Commitment); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrProofGeneration, "failed to add note commitment", err)
+	}
+	if err := c.wallet.AddNote(note); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrProofGeneration, "failed to record note", err)
+	}
+	if err := c.persistShielded(); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrStorageFailure, "failed to save shielded note", err)
+	}
+	return note, nil
+}
+
+// shieldedSpend tracks the notes and nullifiers reserved by one shielded transaction
+type shieldedSpend struct {
+	asset     string
+	amount    uint64
+	selection *zk.NoteSelection
+	pending   *zk.PendingSpend
+	anchor    zk.MerkleHash
+	inputs    *zk.PublicInputs
+	// output is the recipient's note; it is encrypted to recipientKey when one is given
+	output       *zk.Note
+	recipientKey *zk.ReceivingAddress
+	memo         []byte
+	encrypted    *zk.EncryptedNote
+}
+
+// beginShieldedSpend selects notes covering the request (or the notes named by its
+// nullifiers), checks they are in the note tree at the anchor and reserves their
+// nullifiers against double spends
+func (c *EasyCashClient) beginShieldedSpend(req *types.TransactionRequest) (*shieldedSpend, error) {
+	amount, err := zk.ParseAmount(req.Amount)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "invalid shielded amount", err)
+	}
+
+	var recipientKey *zk.ReceivingAddress
+	if req.RecipientKey != "" {
+		addr, err := zk.ParseReceivingAddress(req.RecipientKey)
+		if err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "invalid recipient key", err)
+		}
+		recipientKey = &addr
+	} else if req.Memo != "" {
+		return nil, sdkerrors.New(sdkerrors.ErrInvalidRequest, "memo requires a recipient key")
+	}
+	if len(req.Memo) > zk.MemoSize {
+		return nil, sdkerrors.New(sdkerrors.ErrInvalidRequest, fmt.Sprintf("memo exceeds %d bytes", zk.MemoSize))
+	}
+	if recipientKey != nil && len(req.Asset) > zk.MaxAssetLength {
+		return nil, sdkerrors.New(sdkerrors.ErrInvalidRequest, "asset symbol too long for note encryption")
+	}
+	anchor := c.shielded.Root()
+	if req.Anchor != "" {
+		if anchor, err = zk.ParseMerkleHash(req.Anchor); err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "invalid anchor", err)
+		}
+	}
+
+	var selection *zk.NoteSelection
+	if len(req.Nullifiers) > 0 {
+		nullifiers := make([]zk.Nullifier, 0, len(req.Nullifiers))
+		for _, s := range req.Nullifiers {
+			n, err := zk.ParseNullifier(s)
+			if err != nil {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "invalid nullifier", err)
+			}
+			nullifiers = append(nullifiers, n)
+		}
+		if selection, err = c.wallet.SelectByNullifiers(nullifiers); err != nil {
+			if errors.Is(err, zk.ErrDoubleSpend) {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrDoubleSpend, "shielded spend rejected", err)
+			}
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "invalid note selection", err)
+		}
+		if selection.Notes[0].Asset != req.Asset || selection.Total < amount {
+			selection.Release()
+			return nil, sdkerrors.New(sdkerrors.ErrInsufficientFunds, "selected notes do not cover the amount")
+		}
+	} else if selection, err = c.wallet.SelectNotes(req.Asset, amount); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInsufficientFunds, "insufficient shielded balance", err)
+	}
+
+	pending, err := c.shielded.BeginSpend(anchor, selection.Notes)
+	if err != nil {
+		selection.Release()
+		if errors.Is(err, zk.ErrDoubleSpend) {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrDoubleSpend, "shielded spend rejected", err)
+		}
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "shielded spend rejected", err)
+	}
+	nullifiers := selection.Nullifiers()
+
+	// A retry of the same spend derives the same output note, so a cached proof applies
+	digest := zk.NullifierDigest(nullifiers)
+	output := zk.NewNoteWithBlinding(req.Asset, amount, c.wallet.OutputBlinding(digest, amount, req.Recipient))
+
+	return &shieldedSpend{
+		asset:     req.Asset,
+		amount:    amount,
+		selection: selection,
+		pending:   pending,
+		anchor:    anchor,
+		inputs: &zk.PublicInputs{
+			AmountCommitment: output.Commitment,
+			Recipient:        req.Recipient,
+			Nullifier:        digest,
+		},
+		output:       output,
+		recipientKey: recipientKey,
+		memo:         []byte(req.Memo),
+	}, nil
+}
+
+// proveShieldedSpend proves on provers, which run pg, that the selected notes cover the
+// output note's amount
+func (c *EasyCashClient) proveShieldedSpend(ctx context.Context, pg *zk.ProofGenerator, provers *zk.ProverPool, spend *shieldedSpend) (string, error) {
+	// The balance commitment is the sum of the input note commitments, so a verifier
+	// can check it against the notes being spent
+	witness := zk.SolvencyWitness{
+		Balance:         spend.selection.Total,
+		BalanceBlinding: spend.selection.BalanceBlinding(),
+		Amount:          spend.output.Amount,
+		AmountBlinding:  spend.output.Blinding,
+	}
+	if cache := pg.Cache(); cache != nil {
+		cache.SetRoot(spend.anchor)
+	}
+	return provers.Prove(ctx, zk.ProofJob{Priority: zk.PriorityNormal, Witness: witness, Inputs: *spend.inputs})
+}
+
+// commitShieldedSpend marks the notes spent and adds the recipient output and any
+// change note to the note tree; the change note is kept in the wallet and the output
+// note is encrypted to the recipient key, if any. The spend is recorded in memory before
+// the state and wallet are saved and stays recorded when saving fails: the error then
+// means the files lag behind the spend, not that the spend did not happen.
+func (c *EasyCashClient) commitShieldedSpend(spend *shieldedSpend) error {
+	outputs := []*zk.Commitment{spend.output.Commitment}
+
+	var change *zk.Note
+	if spend.selection.Total > spend.amount {
+		var err error
+		if change, err = zk.NewNote(spend.asset, spend.selection.Total-spend.amount); err != nil {
+			return sdkerrors.Wrap(sdkerrors.ErrProofGeneration, "failed to create change note", err)
+		}
+		outputs = append(outputs, change.Commitment)
+	}
+
+	indices, err := spend.pending.Commit(outputs...)
+	if err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrDoubleSpend, "failed to record shielded spend", err)
+	}
+	spend.selection.MarkSpent()
+	spend.output.LeafIndex = indices[0]
+
+	if change != nil {
+		change.LeafIndex = indices[1]
+		if err := c.wallet.AddNote(change); err != nil {
+			return sdkerrors.Wrap(sdkerrors.ErrDoubleSpend, "failed to record change note", err)
+		}
+	}
+	// Without the saved nullifiers the notes would be spendable again after a restart
+	if err := c.persistShielded(); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrStorageFailure, "failed to save shielded spend", err)
+	}
+
+	// The spend is recorded, so reporting an error here would invite a retry that pays
+	// twice. The recipient key was checked when the spend began, leaving only the random
+	// source to fail; the transfer then succeeds without an EncryptedNote.
+	if spend.recipientKey != nil {
+		if spend.encrypted, err = zk.EncryptNote(*spend.recipientKey, &c.wallet.ViewingKey().Outgoing, spend.output, spend.memo); err != nil {
+			fmt.Printf("[SDK] Failed to encrypt output note at leaf %d: %v\n", spend.output.LeafIndex, err)
+		}
+	}
+	return nil
+}
+
+// abort releases the reserved notes and nullifiers; it is a no-op after a commit
+func (s *shieldedSpend) abort() {
+	s.pending.Abort()
+	s.selection.Release()
+}
+
+// persistShielded saves the note tree and wallet when file paths are configured
+func (c *EasyCashClient) persistShielded() error {
+	if c.config.ShieldedStatePath != "" {
+		if err := c.shielded.SaveFile(c.config.ShieldedStatePath); err != nil {
+			return err
+		}
+	}
+	if c.config.NoteWalletPath != "" {
+		if err := c.wallet.SaveFile(c.config.NoteWalletPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
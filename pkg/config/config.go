@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"runtime"
+	"strconv"
 	"time"
 )
 
@@ -18,29 +20,77 @@ type SDKConfig struct {
 	RetryBackoff time.Duration
 
 	// Privacy Configuration
-	EnableZKProofs bool
-	ProofCacheTTL  time.Duration
+	EnableZKProofs      bool
+	ProofCacheTTL       time.Duration
+	ProverBackend       string // "reference" (in-process) or "external"
+	CircuitPath         string // circuit artifact for the external prover
+	VerificationKeyPath string // empty uses the built-in solvency key
+	CircuitManifestPath string // pins artifact and key hashes; replaces CircuitPath and VerificationKeyPath
+	CircuitID           string // manifest circuit version to prove with; empty is the default solvency circuit
+	ProverCommand       string // external prover binary
+	ProverWorkers       int    // proofs generated concurrently; bounds prover memory
+	ProverQueueSize     int    // proof jobs waiting for a worker; 0 is unbounded
+	ShieldedStatePath   string // note tree and nullifier snapshot; empty keeps state in memory
+	NoteWalletPath      string // owned notes and nullifier key; empty keeps notes in memory
+
+	// Signing Configuration
+	SignerEndpoint          string // remote signer service; empty disables remote signing
+	SignerKeyID             string
+	SignerToken             string
+	IntentVerifyingContract string // EIP-712 verifying contract for EVM intents
+	ApprovalLimit           string // amounts above this need M-of-N approval; empty disables
+
+	// Shared Cache Configuration
+	CacheBackend  string        // "memory" (per process) or "redis" (shared by replicas)
+	CacheAddr     string        // host:port of the Redis-protocol server
+	CachePassword string        // sent with AUTH when set
+	QuoteCacheTTL time.Duration // agent quotes are reused this long; 0 disables quote caching
+	QuoteStaleTTL time.Duration // stale quotes are served this much longer while refreshing
 
 	// Performance Configuration
-	EnableMetrics bool
-	EnableCaching bool
-	CacheTTL      time.Duration
+	EnableMetrics   bool
+	EnableCaching   bool
+	CacheTTL        time.Duration // responses are reused this long; 0 keeps them until evicted
+	CacheMaxEntries int           // least recently used responses are evicted beyond this; 0 is unbounded
+	// CacheSweepInterval is how often expired responses are swept; 0 drops them lazily
+	CacheSweepInterval time.Duration
 }
 
 // DefaultConfig returns sensible defaults
 func DefaultConfig() *SDKConfig {
 	return &SDKConfig{
-		APIEndpoint:    getEnv("ECASH_API_ENDPOINT", "https://api.useeasy.cash"),
-		APIKey:         getEnv("ECASH_API_KEY", ""),
-		Environment:    getEnv("ECASH_ENV", "mainnet"),
-		Timeout:        30 * time.Second,
-		MaxRetries:     3,
-		RetryBackoff:   2 * time.Second,
-		EnableZKProofs: true,
-		ProofCacheTTL:  5 * time.Minute,
-		EnableMetrics:  true,
-		EnableCaching:  true,
-		CacheTTL:       1 * time.Minute,
+		APIEndpoint:         getEnv("ECASH_API_ENDPOINT", "https://api.useeasy.cash"),
+		APIKey:              getEnv("ECASH_API_KEY", ""),
+		Environment:         getEnv("ECASH_ENV", "mainnet"),
+		Timeout:             30 * time.Second,
+		MaxRetries:          3,
+		RetryBackoff:        2 * time.Second,
+		EnableZKProofs:      true,
+		ProofCacheTTL:       5 * time.Minute,
+		ProverBackend:       getEnv("ECASH_PROVER_BACKEND", "reference"),
+		CircuitPath:         getEnv("ECASH_CIRCUIT_PATH", ""),
+		ProverCommand:       getEnv("ECASH_PROVER_COMMAND", ""),
+		ProverWorkers:       getEnvInt("ECASH_PROVER_WORKERS", runtime.NumCPU()),
+		ProverQueueSize:     getEnvInt("ECASH_PROVER_QUEUE_SIZE", 256),
+		VerificationKeyPath: getEnv("ECASH_VERIFICATION_KEY_PATH", ""),
+		CircuitManifestPath: getEnv("ECASH_CIRCUIT_MANIFEST", ""),
+		CircuitID:           getEnv("ECASH_CIRCUIT_ID", ""),
+		ShieldedStatePath:   getEnv("ECASH_SHIELDED_STATE_PATH", ""),
+		NoteWalletPath:      getEnv("ECASH_NOTE_WALLET_PATH", ""),
+		SignerEndpoint:      getEnv("ECASH_SIGNER_ENDPOINT", ""),
+		SignerKeyID:         getEnv("ECASH_SIGNER_KEY_ID", ""),
+		SignerToken:         getEnv("ECASH_SIGNER_TOKEN", ""),
+		ApprovalLimit:       getEnv("ECASH_APPROVAL_LIMIT", ""),
+		CacheBackend:        getEnv("ECASH_CACHE_BACKEND", "memory"),
+		CacheAddr:           getEnv("ECASH_CACHE_ADDR", ""),
+		CachePassword:       getEnv("ECASH_CACHE_PASSWORD", ""),
+		QuoteCacheTTL:       15 * time.Second,
+		QuoteStaleTTL:       15 * time.Second,
+		EnableMetrics:       true,
+		EnableCaching:       true,
+		CacheTTL:            1 * time.Minute,
+		CacheMaxEntries:     1024,
+		CacheSweepInterval:  1 * time.Minute,
 	}
 }
 
@@ -52,6 +102,14 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvInt retrieves an integer environment variable with fallback
+func getEnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
 // Validate checks if the configuration is valid
 func (c *SDKConfig) Validate() error {
 	// Add validation logic here
@@ -0,0 +1,228 @@
+package crypto
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Algorithm identifies the key type and signature scheme of a Signer
+type Algorithm string
+
+const (
+	// AlgorithmECDSAP256 signs 32-byte digests, producing fixed-width low-S r||s
+	AlgorithmECDSAP256 Algorithm = "ecdsa-p256"
+	// AlgorithmSecp256k1 signs 32-byte digests, producing Ethereum r||s||v with v in {0, 1}
+	AlgorithmSecp256k1 Algorithm = "secp256k1"
+	// AlgorithmEd25519 signs the payload directly, producing a 64-byte signature
+	AlgorithmEd25519 Algorithm = "ed25519"
+)
+
+// Signer signs digests with a key that may live in process memory, on disk or in a remote KMS.
+// Public keys are serialized as uncompressed SEC1 points for ECDSA and raw 32 bytes for Ed25519.
+type Signer interface {
+	Algorithm() Algorithm
+	PublicKey() []byte
+	Sign(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+var (
+	_ Signer = (*MemorySigner)(nil)
+	_ Signer = (*FileSigner)(nil)
+	_ Signer = (*RemoteSigner)(nil)
+)
+
+// MemorySigner is a Signer backed by a private key held in process memory
+type MemorySigner struct {
+	algorithm  Algorithm
+	privateKey []byte
+	publicKey  []byte
+}
+
+// NewMemorySigner creates an in-memory Signer. The key may be an *ecdsa.PrivateKey
+// (P-256 or secp256k1), *secp256k1.PrivateKey, ed25519.PrivateKey or one of the SDK signers.
+func NewMemorySigner(key interface{}) (*MemorySigner, error) {
+	algorithm, privKey, err := keyMaterial(key)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := derivePublicKey(algorithm, privKey)
+	if err != nil {
+		return nil, err
+	}
+	return &MemorySigner{
+		algorithm:  algorithm,
+		privateKey: privKey,
+		publicKey:  pubKey,
+	}, nil
+}
+
+// Algorithm returns the signature scheme of the key
+func (s *MemorySigner) Algorithm() Algorithm {
+	return s.algorithm
+}
+
+// PublicKey returns the serialized public key
+func (s *MemorySigner) PublicKey() []byte {
+	return append([]byte(nil), s.publicKey...)
+}
+
+// Sign signs the digest with the in-memory key
+func (s *MemorySigner) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return signWithKey(s.algorithm, s.privateKey, digest)
+}
+
+// keyMaterial extracts the algorithm and raw 32-byte private key from supported key types
+func keyMaterial(key interface{}) (Algorithm, []byte, error) {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		raw := make([]byte, 32)
+		switch {
+		case k.Curve == elliptic.P256():
+			k.D.FillBytes(raw)
+			return AlgorithmECDSAP256, raw, nil
+		case k.Curve.Params().Name == secp256k1.S256().Params().Name:
+			k.D.FillBytes(raw)
+			return AlgorithmSecp256k1, raw, nil
+		default:
+			return "", nil, fmt.Errorf("unsupported curve: %s", k.Curve.Params().Name)
+		}
+	case *ECDSASigner:
+		return keyMaterial(k.privateKey)
+	case *secp256k1.PrivateKey:
+		return AlgorithmSecp256k1, k.Serialize(), nil
+	case *Secp256k1Signer:
+		return AlgorithmSecp256k1, k.PrivateKeyBytes(), nil
+	case ed25519.PrivateKey:
+		return AlgorithmEd25519, append([]byte(nil), k.Seed()...), nil
+	case *Ed25519Signer:
+		return AlgorithmEd25519, append([]byte(nil), k.privateKey.Seed()...), nil
+	default:
+		return "", nil, fmt.Errorf("unsupported key type: %T", key)
+	}
+}
+
+// p256PrivateKey rebuilds a P-256 key from its 32-byte scalar
+func p256PrivateKey(privKey []byte) (*ecdsa.PrivateKey, error) {
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), privKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256 private key: %w", err)
+	}
+	return key, nil
+}
+
+// derivePublicKey returns the serialized public key for a raw private key
+func derivePublicKey(algorithm Algorithm, privKey []byte) ([]byte, error) {
+	switch algorithm {
+	case AlgorithmECDSAP256:
+		key, err := p256PrivateKey(privKey)
+		if err != nil {
+			return nil, err
+		}
+		return key.PublicKey.Bytes()
+	case AlgorithmSecp256k1:
+		signer, err := Secp256k1SignerFromBytes(privKey)
+		if err != nil {
+			return nil, err
+		}
+		return signer.privateKey.PubKey().SerializeUncompressed(), nil
+	case AlgorithmEd25519:
+		if len(privKey) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid ed25519 seed length: %d", len(privKey))
+		}
+		return ed25519.NewKeyFromSeed(privKey).Public().(ed25519.PublicKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+}
+
+// signWithKey signs a digest with a raw private key of the given algorithm
+func signWithKey(algorithm Algorithm, privKey, digest []byte) ([]byte, error) {
+	switch algorithm {
+	case AlgorithmECDSAP256:
+		if len(digest) != 32 {
+			return nil, fmt.Errorf("digest must be 32 bytes, got %d", len(digest))
+		}
+		key, err := p256PrivateKey(privKey)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := NewSigner(key).SignHash(digest, false)
+		if err != nil {
+			return nil, err
+		}
+		return sig.Encode(key.Curve, FormatRS)
+	case AlgorithmSecp256k1:
+		signer, err := Secp256k1SignerFromBytes(privKey)
+		if err != nil {
+			return nil, err
+		}
+		return signer.SignHash(digest)
+	case AlgorithmEd25519:
+		if len(privKey) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid ed25519 seed length: %d", len(privKey))
+		}
+		return ed25519.Sign(ed25519.NewKeyFromSeed(privKey), digest), nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+}
+
+// VerifyDigest checks a Signer's output against its serialized public key
+func VerifyDigest(algorithm Algorithm, pubKey, digest, sig []byte) error {
+	switch algorithm {
+	case AlgorithmECDSAP256:
+		key, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), pubKey)
+		if err != nil {
+			return fmt.Errorf("invalid public key: %w", err)
+		}
+		if len(sig) != 64 {
+			return fmt.Errorf("%w: expected 64 bytes, got %d", ErrInvalidSignature, len(sig))
+		}
+		parsed, err := ParseSignatureFormat(key.Curve, sig, FormatRS)
+		if err != nil {
+			return err
+		}
+		if !ecdsa.Verify(key, digest, parsed.R, parsed.S) {
+			return ErrSignatureMismatch
+		}
+		return nil
+	case AlgorithmSecp256k1:
+		key, err := secp256k1.ParsePubKey(pubKey)
+		if err != nil {
+			return fmt.Errorf("invalid public key: %w", err)
+		}
+		recovered, err := RecoverSecp256k1(digest, sig)
+		if err != nil {
+			return err
+		}
+		if !recovered.Equal(key.ToECDSA()) {
+			return ErrSignatureMismatch
+		}
+		return nil
+	case AlgorithmEd25519:
+		if len(pubKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid public key length: %d", len(pubKey))
+		}
+		if !ed25519.Verify(pubKey, digest, sig) {
+			return ErrSignatureMismatch
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+}
+
+// zeroBytes overwrites key material once it is no longer needed
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,169 @@
+package crypto
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKeys returns one key of each supported algorithm
+func testKeys(t *testing.T) map[Algorithm]interface{} {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	k1, err := GenerateSecp256k1Signer()
+	require.NoError(t, err)
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return map[Algorithm]interface{}{
+		AlgorithmECDSAP256: p256,
+		AlgorithmSecp256k1: k1,
+		AlgorithmEd25519:   ed,
+	}
+}
+
+func TestMemorySigner(t *testing.T) {
+	digest := Keccak256([]byte("intent"))
+
+	for algorithm, key := range testKeys(t) {
+		t.Run(string(algorithm), func(t *testing.T) {
+			signer, err := NewMemorySigner(key)
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, signer.Algorithm())
+
+			sig, err := signer.Sign(context.Background(), digest)
+			require.NoError(t, err)
+			assert.NoError(t, VerifyDigest(algorithm, signer.PublicKey(), digest, sig))
+			assert.Error(t, VerifyDigest(algorithm, signer.PublicKey(), Keccak256([]byte("other")), sig))
+		})
+	}
+
+	_, err := NewMemorySigner("not a key")
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	signer, err := NewMemorySigner(testKeys(t)[AlgorithmEd25519])
+	require.NoError(t, err)
+	_, err = signer.Sign(ctx, digest)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFileSigner(t *testing.T) {
+	dir := t.TempDir()
+	digest := Keccak256([]byte("intent"))
+
+	for algorithm, key := range testKeys(t) {
+		t.Run(string(algorithm), func(t *testing.T) {
+			path := filepath.Join(dir, string(algorithm)+".json")
+			require.NoError(t, WriteEncryptedKey(path, key, "correct horse", LightScryptParams))
+
+			memory, err := NewMemorySigner(key)
+			require.NoError(t, err)
+
+			signer, err := NewFileSigner(path, "correct horse")
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, signer.Algorithm())
+			assert.Equal(t, memory.PublicKey(), signer.PublicKey())
+
+			sig, err := signer.Sign(context.Background(), digest)
+			require.NoError(t, err)
+			assert.NoError(t, VerifyDigest(algorithm, signer.PublicKey(), digest, sig))
+
+			_, err = NewFileSigner(path, "wrong")
+			assert.ErrorIs(t, err, ErrWrongPassphrase)
+		})
+	}
+
+	_, err := NewFileSigner(filepath.Join(dir, "missing.json"), "x")
+	assert.Error(t, err)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	keys := testKeys(t)
+	signers := make(map[string]Signer)
+	for algorithm, key := range keys {
+		signer, err := NewMemorySigner(key)
+		require.NoError(t, err)
+		signers[string(algorithm)] = signer
+	}
+
+	server := httptest.NewServer(NewSignerHandler(signers, "secret-token"))
+	defer server.Close()
+
+	ctx := context.Background()
+	digest := Keccak256([]byte("intent"))
+
+	for id, local := range signers {
+		t.Run(id, func(t *testing.T) {
+			remote, err := NewRemoteSigner(ctx, RemoteSignerConfig{Endpoint: server.URL, KeyID: id, Token: "secret-token"})
+			require.NoError(t, err)
+			assert.Equal(t, local.Algorithm(), remote.Algorithm())
+			assert.Equal(t, local.PublicKey(), remote.PublicKey())
+
+			sig, err := remote.Sign(ctx, digest)
+			require.NoError(t, err)
+			assert.NoError(t, VerifyDigest(remote.Algorithm(), remote.PublicKey(), digest, sig))
+		})
+	}
+
+	_, err := NewRemoteSigner(ctx, RemoteSignerConfig{Endpoint: server.URL, KeyID: "missing", Token: "secret-token"})
+	assert.ErrorContains(t, err, "unknown key")
+
+	_, err = NewRemoteSigner(ctx, RemoteSignerConfig{Endpoint: server.URL, KeyID: string(AlgorithmEd25519), Token: "wrong"})
+	assert.ErrorContains(t, err, "401")
+
+	_, err = NewRemoteSigner(ctx, RemoteSignerConfig{KeyID: "x"})
+	assert.Error(t, err)
+}
+
+func TestRemoteSignerEscapesKeyID(t *testing.T) {
+	signer, err := NewMemorySigner(testKeys(t)[AlgorithmEd25519])
+	require.NoError(t, err)
+	// A key ID that would otherwise add a path segment and a query
+	const id = "treasury/hot?key=1"
+	server := httptest.NewServer(NewSignerHandler(map[string]Signer{id: signer, "treasury": signer}, ""))
+	defer server.Close()
+
+	ctx := context.Background()
+	remote, err := NewRemoteSigner(ctx, RemoteSignerConfig{Endpoint: server.URL, KeyID: id})
+	require.NoError(t, err)
+	digest := Keccak256([]byte("intent"))
+	sig, err := remote.Sign(ctx, digest)
+	require.NoError(t, err)
+	assert.NoError(t, VerifyDigest(remote.Algorithm(), remote.PublicKey(), digest, sig))
+
+	_, err = NewRemoteSigner(ctx, RemoteSignerConfig{Endpoint: server.URL, KeyID: "treasury/other"})
+	assert.ErrorContains(t, err, "unknown key")
+}
+
+func TestRemoteSignerRejectsBadSignature(t *testing.T) {
+	key := testKeys(t)[AlgorithmSecp256k1]
+	honest, err := NewMemorySigner(key)
+	require.NoError(t, err)
+	other, err := NewMemorySigner(testKeys(t)[AlgorithmSecp256k1])
+	require.NoError(t, err)
+
+	// Advertise one key but sign with another
+	mux := http.NewServeMux()
+	keyHandler := NewSignerHandler(map[string]Signer{"k": honest}, "")
+	signHandler := NewSignerHandler(map[string]Signer{"k": other}, "")
+	mux.Handle("GET /v1/keys/{id}", keyHandler)
+	mux.Handle("POST /v1/keys/{id}/sign", signHandler)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	remote, err := NewRemoteSigner(context.Background(), RemoteSignerConfig{Endpoint: server.URL, KeyID: "k"})
+	require.NoError(t, err)
+	_, err = remote.Sign(context.Background(), Keccak256([]byte("intent")))
+	assert.ErrorIs(t, err, ErrSignatureMismatch)
+}
@@ -0,0 +1,127 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+)
+
+// Ed25519Signer signs messages and Solana transactions with an Ed25519 key
+type Ed25519Signer struct {
+	privateKey ed25519.PrivateKey
+}
+
+// NewEd25519Signer creates a signer from an Ed25519 private key
+func NewEd25519Signer(privKey ed25519.PrivateKey) *Ed25519Signer {
+	return &Ed25519Signer{
+		privateKey: privKey,
+	}
+}
+
+// GenerateEd25519Signer creates a signer with a freshly generated key
+func GenerateEd25519Signer() (*Ed25519Signer, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("key generation failed: %w", err)
+	}
+	return NewEd25519Signer(key), nil
+}
+
+// Ed25519SignerFromBytes creates a signer from a 32-byte seed or a 64-byte seed||public key keypair
+func Ed25519SignerFromBytes(key []byte) (*Ed25519Signer, error) {
+	switch len(key) {
+	case ed25519.SeedSize:
+		return NewEd25519Signer(ed25519.NewKeyFromSeed(key)), nil
+	case ed25519.PrivateKeySize:
+		privKey := ed25519.NewKeyFromSeed(key[:ed25519.SeedSize])
+		if !privKey.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(key[ed25519.SeedSize:])) {
+			return nil, fmt.Errorf("keypair public key does not match seed")
+		}
+		return NewEd25519Signer(privKey), nil
+	default:
+		return nil, fmt.Errorf("invalid ed25519 key length: %d", len(key))
+	}
+}
+
+// Ed25519SignerFromBase58 imports a base58-encoded key as exported by Solana wallets
+func Ed25519SignerFromBase58(key string) (*Ed25519Signer, error) {
+	b, err := base58.Decode(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base58 key: %w", err)
+	}
+	return Ed25519SignerFromBytes(b)
+}
+
+// ExportBase58 returns the 64-byte keypair in base58, the format used by Solana wallets
+func (s *Ed25519Signer) ExportBase58() string {
+	return base58.Encode(s.privateKey)
+}
+
+// PublicKey returns the signer's public key
+func (s *Ed25519Signer) PublicKey() ed25519.PublicKey {
+	return s.privateKey.Public().(ed25519.PublicKey)
+}
+
+// Address returns the base58-encoded public key, which is the Solana account address
+func (s *Ed25519Signer) Address() string {
+	return base58.Encode(s.PublicKey())
+}
+
+// Sign returns the raw 64-byte Ed25519 signature over data
+func (s *Ed25519Signer) Sign(data []byte) []byte {
+	return ed25519.Sign(s.privateKey, data)
+}
+
+// SignMessage signs data and returns the 0x-prefixed hex signature
+func (s *Ed25519Signer) SignMessage(data []byte) (string, error) {
+	return "0x" + hex.EncodeToString(s.Sign(data)), nil
+}
+
+// VerifyEd25519 verifies a signature (0x-hex or base58) over data against a public key
+func VerifyEd25519(pubKey ed25519.PublicKey, data []byte, signature string) error {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key length: %d", len(pubKey))
+	}
+
+	sig, err := decodeEd25519Signature(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pubKey, data, sig) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// decodeEd25519Signature accepts 0x-prefixed hex or base58 encodings
+func decodeEd25519Signature(signature string) ([]byte, error) {
+	var sig []byte
+	var err error
+	if len(signature) >= 2 && (signature[:2] == "0x" || signature[:2] == "0X") {
+		sig, err = DecodeHex(signature)
+	} else {
+		sig, err = base58.Decode(signature)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidSignature, ed25519.SignatureSize, len(sig))
+	}
+	return sig, nil
+}
+
+// ParseSolanaAddress decodes a base58 Solana address into an Ed25519 public key
+func ParseSolanaAddress(address string) (ed25519.PublicKey, error) {
+	b, err := base58.Decode(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid solana address: %w", err)
+	}
+	if len(b) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid solana address length: %d", len(b))
+	}
+	return ed25519.PublicKey(b), nil
+}
@@ -0,0 +1,119 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEd25519RFC8032Vector(t *testing.T) {
+	// RFC 8032 section 7.1, TEST 2
+	seed, _ := hex.DecodeString("4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb")
+	signer, err := Ed25519SignerFromBytes(seed)
+	require.NoError(t, err)
+	assert.Equal(t, "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c", hex.EncodeToString(signer.PublicKey()))
+
+	sig, err := signer.SignMessage([]byte{0x72})
+	require.NoError(t, err)
+	assert.Equal(t, "0x92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da"+
+		"085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00", sig)
+	assert.NoError(t, VerifyEd25519(signer.PublicKey(), []byte{0x72}, sig))
+}
+
+func TestEd25519Base58RoundTrip(t *testing.T) {
+	signer, err := GenerateEd25519Signer()
+	require.NoError(t, err)
+
+	imported, err := Ed25519SignerFromBase58(signer.ExportBase58())
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), imported.Address())
+
+	pub, err := ParseSolanaAddress(signer.Address())
+	require.NoError(t, err)
+	assert.True(t, pub.Equal(signer.PublicKey()))
+
+	// A keypair whose public half does not match the seed is rejected
+	tampered := append([]byte(nil), signer.privateKey...)
+	tampered[63] ^= 1
+	_, err = Ed25519SignerFromBytes(tampered)
+	assert.Error(t, err)
+
+	_, err = Ed25519SignerFromBase58("not-base58-0OIl")
+	assert.Error(t, err)
+}
+
+func TestSolanaOffchainMessage(t *testing.T) {
+	signer, err := GenerateEd25519Signer()
+	require.NoError(t, err)
+
+	serialized, err := SolanaOffchainMessage([]byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, append([]byte("\xffsolana offchain\x00\x00\x05\x00"), "hello"...), serialized)
+
+	format, err := solanaMessageFormat([]byte("héllo"))
+	require.NoError(t, err)
+	assert.Equal(t, SolanaLimitedUTF8, format)
+
+	format, err = solanaMessageFormat(bytes.Repeat([]byte("a"), 2000))
+	require.NoError(t, err)
+	assert.Equal(t, SolanaExtendedUTF8, format)
+
+	_, err = SolanaOffchainMessage([]byte{0xff, 0xfe})
+	assert.Error(t, err)
+
+	sig, err := signer.SignSolanaMessage([]byte("authorize withdrawal"))
+	require.NoError(t, err)
+	assert.NoError(t, VerifySolanaMessage(signer.Address(), []byte("authorize withdrawal"), sig))
+	assert.ErrorIs(t, VerifySolanaMessage(signer.Address(), []byte("other"), sig), ErrSignatureMismatch)
+}
+
+// buildSolanaTransaction assembles an unsigned legacy transaction with the given required signers
+func buildSolanaTransaction(signers []ed25519.PublicKey, readonly ed25519.PublicKey) []byte {
+	var msg bytes.Buffer
+	msg.Write([]byte{byte(len(signers)), 0, 1})
+	msg.WriteByte(byte(len(signers) + 1))
+	for _, key := range signers {
+		msg.Write(key)
+	}
+	msg.Write(readonly)
+	msg.Write(bytes.Repeat([]byte{0xab}, 32)) // recent blockhash
+	msg.WriteByte(0)                          // no instructions
+
+	var tx bytes.Buffer
+	tx.WriteByte(byte(len(signers)))
+	tx.Write(make([]byte, len(signers)*ed25519.SignatureSize))
+	tx.Write(msg.Bytes())
+	return tx.Bytes()
+}
+
+func TestSignSolanaTransaction(t *testing.T) {
+	payer, err := GenerateEd25519Signer()
+	require.NoError(t, err)
+	authority, err := GenerateEd25519Signer()
+	require.NoError(t, err)
+	program, err := GenerateEd25519Signer()
+	require.NoError(t, err)
+
+	tx := buildSolanaTransaction([]ed25519.PublicKey{payer.PublicKey(), authority.PublicKey()}, program.PublicKey())
+	assert.Error(t, VerifySolanaTransaction(tx))
+
+	// Signers may be passed in any order
+	signed, err := SignSolanaTransaction(tx, authority, payer)
+	require.NoError(t, err)
+	assert.NoError(t, VerifySolanaTransaction(signed))
+	assert.Equal(t, make([]byte, 64), tx[1:65], "input must not be modified")
+
+	message, err := SolanaTransactionMessage(signed)
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(payer.PublicKey(), message, signed[1:65]))
+
+	_, err = SignSolanaTransaction(tx, program)
+	assert.Error(t, err)
+
+	_, err = SignSolanaTransaction(tx[:40], payer)
+	assert.Error(t, err)
+}
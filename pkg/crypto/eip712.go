@@ -0,0 +1,419 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// TypedDataField describes a single member of an EIP-712 struct type
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedDataTypes maps struct type names to their ordered members
+type TypedDataTypes map[string][]TypedDataField
+
+// TypedDataDomain is the EIP-712 domain separating signatures between dApps and chains.
+// Empty fields are omitted from the EIP712Domain type.
+type TypedDataDomain struct {
+	Name              string   `json:"name,omitempty"`
+	Version           string   `json:"version,omitempty"`
+	ChainID           *big.Int `json:"chainId,omitempty"`
+	VerifyingContract string   `json:"verifyingContract,omitempty"`
+	Salt              string   `json:"salt,omitempty"`
+}
+
+// TypedData is a complete EIP-712 payload ready to be hashed and signed
+type TypedData struct {
+	Types       TypedDataTypes         `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      TypedDataDomain        `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+const eip712DomainType = "EIP712Domain"
+
+var (
+	arrayTypeRegex = regexp.MustCompile(`^(.+)\[(\d*)\]$`)
+	intTypeRegex   = regexp.MustCompile(`^(u?)int(\d*)$`)
+	bytesTypeRegex = regexp.MustCompile(`^bytes(\d+)$`)
+)
+
+// Keccak256 returns the legacy Keccak-256 digest used by Ethereum
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// domainFields returns the EIP712Domain members present in the domain, in canonical order
+func (d TypedDataDomain) domainFields() []TypedDataField {
+	var fields []TypedDataField
+	if d.Name != "" {
+		fields = append(fields, TypedDataField{Name: "name", Type: "string"})
+	}
+	if d.Version != "" {
+		fields = append(fields, TypedDataField{Name: "version", Type: "string"})
+	}
+	if d.ChainID != nil {
+		fields = append(fields, TypedDataField{Name: "chainId", Type: "uint256"})
+	}
+	if d.VerifyingContract != "" {
+		fields = append(fields, TypedDataField{Name: "verifyingContract", Type: "address"})
+	}
+	if d.Salt != "" {
+		fields = append(fields, TypedDataField{Name: "salt", Type: "bytes32"})
+	}
+	return fields
+}
+
+// domainMessage returns the domain as a message map suitable for EncodeData
+func (d TypedDataDomain) domainMessage() map[string]interface{} {
+	msg := make(map[string]interface{})
+	if d.Name != "" {
+		msg["name"] = d.Name
+	}
+	if d.Version != "" {
+		msg["version"] = d.Version
+	}
+	if d.ChainID != nil {
+		msg["chainId"] = d.ChainID
+	}
+	if d.VerifyingContract != "" {
+		msg["verifyingContract"] = d.VerifyingContract
+	}
+	if d.Salt != "" {
+		msg["salt"] = d.Salt
+	}
+	return msg
+}
+
+// types returns the declared types with the EIP712Domain type filled in from the domain
+func (td *TypedData) types() TypedDataTypes {
+	if _, ok := td.Types[eip712DomainType]; ok {
+		return td.Types
+	}
+	all := make(TypedDataTypes, len(td.Types)+1)
+	for name, fields := range td.Types {
+		all[name] = fields
+	}
+	all[eip712DomainType] = td.Domain.domainFields()
+	return all
+}
+
+// EncodeType returns the canonical type string, e.g. "Mail(Person from,Person to,string contents)Person(string name,address wallet)"
+func (td *TypedData) EncodeType(primaryType string) (string, error) {
+	types := td.types()
+	if _, ok := types[primaryType]; !ok {
+		return "", fmt.Errorf("unknown type: %s", primaryType)
+	}
+
+	deps := make(map[string]bool)
+	collectDependencies(types, primaryType, deps)
+	delete(deps, primaryType)
+
+	sorted := make([]string, 0, len(deps))
+	for dep := range deps {
+		sorted = append(sorted, dep)
+	}
+	sort.Strings(sorted)
+
+	var buf strings.Builder
+	for _, name := range append([]string{primaryType}, sorted...) {
+		buf.WriteString(name)
+		buf.WriteByte('(')
+		for i, field := range types[name] {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(field.Type)
+			buf.WriteByte(' ')
+			buf.WriteString(field.Name)
+		}
+		buf.WriteByte(')')
+	}
+	return buf.String(), nil
+}
+
+// collectDependencies walks struct members and records every referenced struct type
+func collectDependencies(types TypedDataTypes, typeName string, found map[string]bool) {
+	typeName = baseType(typeName)
+	if found[typeName] {
+		return
+	}
+	fields, ok := types[typeName]
+	if !ok {
+		return
+	}
+	found[typeName] = true
+	for _, field := range fields {
+		collectDependencies(types, field.Type, found)
+	}
+}
+
+// baseType strips any array suffixes from a type name
+func baseType(typeName string) string {
+	for {
+		m := arrayTypeRegex.FindStringSubmatch(typeName)
+		if m == nil {
+			return typeName
+		}
+		typeName = m[1]
+	}
+}
+
+// TypeHash returns keccak256(encodeType(primaryType))
+func (td *TypedData) TypeHash(primaryType string) ([]byte, error) {
+	encoded, err := td.EncodeType(primaryType)
+	if err != nil {
+		return nil, err
+	}
+	return Keccak256([]byte(encoded)), nil
+}
+
+// HashStruct returns keccak256(typeHash || encodeData(data)) for the given struct type
+func (td *TypedData) HashStruct(primaryType string, data map[string]interface{}) ([]byte, error) {
+	encoded, err := td.EncodeData(primaryType, data)
+	if err != nil {
+		return nil, err
+	}
+	return Keccak256(encoded), nil
+}
+
+// EncodeData returns typeHash followed by the 32-byte encoding of each member
+func (td *TypedData) EncodeData(primaryType string, data map[string]interface{}) ([]byte, error) {
+	types := td.types()
+	fields, ok := types[primaryType]
+	if !ok {
+		return nil, fmt.Errorf("unknown type: %s", primaryType)
+	}
+
+	typeHash, err := td.TypeHash(primaryType)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(typeHash)
+	for _, field := range fields {
+		value, ok := data[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing value for field %s.%s", primaryType, field.Name)
+		}
+		word, err := td.encodeValue(types, field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s.%s: %w", primaryType, field.Name, err)
+		}
+		buf.Write(word)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeValue encodes a single member into its 32-byte representation
+func (td *TypedData) encodeValue(types TypedDataTypes, typeName string, value interface{}) ([]byte, error) {
+	if m := arrayTypeRegex.FindStringSubmatch(typeName); m != nil {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected array for type %s, got %T", typeName, value)
+		}
+		if m[2] != "" {
+			size, _ := strconv.Atoi(m[2])
+			if len(items) != size {
+				return nil, fmt.Errorf("expected %d items for type %s, got %d", size, typeName, len(items))
+			}
+		}
+		var buf bytes.Buffer
+		for _, item := range items {
+			word, err := td.encodeValue(types, m[1], item)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(word)
+		}
+		return Keccak256(buf.Bytes()), nil
+	}
+
+	if _, ok := types[typeName]; ok {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected object for type %s, got %T", typeName, value)
+		}
+		return td.HashStruct(typeName, nested)
+	}
+
+	return encodeAtomic(typeName, value)
+}
+
+// encodeAtomic encodes primitive Solidity types
+func encodeAtomic(typeName string, value interface{}) ([]byte, error) {
+	switch typeName {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", value)
+		}
+		return Keccak256([]byte(s)), nil
+	case "bytes":
+		b, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return Keccak256(b), nil
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected bool, got %T", value)
+		}
+		word := make([]byte, 32)
+		if b {
+			word[31] = 1
+		}
+		return word, nil
+	case "address":
+		b, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != 20 {
+			return nil, fmt.Errorf("invalid address length: %d", len(b))
+		}
+		return leftPad(b), nil
+	}
+
+	if m := bytesTypeRegex.FindStringSubmatch(typeName); m != nil {
+		size, _ := strconv.Atoi(m[1])
+		if size < 1 || size > 32 {
+			return nil, fmt.Errorf("invalid type: %s", typeName)
+		}
+		b, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != size {
+			return nil, fmt.Errorf("expected %d bytes for %s, got %d", size, typeName, len(b))
+		}
+		word := make([]byte, 32)
+		copy(word, b)
+		return word, nil
+	}
+
+	if m := intTypeRegex.FindStringSubmatch(typeName); m != nil {
+		bits := 256
+		if m[2] != "" {
+			bits, _ = strconv.Atoi(m[2])
+		}
+		if bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, fmt.Errorf("invalid type: %s", typeName)
+		}
+		n, err := toBigInt(value)
+		if err != nil {
+			return nil, err
+		}
+		return encodeInteger(n, bits, m[1] == "")
+	}
+
+	return nil, fmt.Errorf("unsupported type: %s", typeName)
+}
+
+// encodeInteger encodes a (possibly signed) integer as a 32-byte two's complement word
+func encodeInteger(n *big.Int, bits int, signed bool) ([]byte, error) {
+	if signed {
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, fmt.Errorf("value %s overflows int%d", n, bits)
+		}
+		if n.Sign() < 0 {
+			n = new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+	} else {
+		if n.Sign() < 0 || n.BitLen() > bits {
+			return nil, fmt.Errorf("value %s overflows uint%d", n, bits)
+		}
+	}
+	word := make([]byte, 32)
+	n.FillBytes(word)
+	return word, nil
+}
+
+// leftPad pads b on the left with zeros to 32 bytes
+func leftPad(b []byte) []byte {
+	word := make([]byte, 32)
+	copy(word[32-len(b):], b)
+	return word
+}
+
+// toBytes accepts raw bytes or 0x-prefixed hex strings
+func toBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		if !strings.HasPrefix(v, "0x") && !strings.HasPrefix(v, "0X") {
+			return nil, fmt.Errorf("hex value must be 0x-prefixed: %s", v)
+		}
+		b, err := hex.DecodeString(v[2:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex value: %w", err)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("expected bytes, got %T", value)
+	}
+}
+
+// toBigInt accepts Go integers, *big.Int and decimal or 0x-prefixed hex strings
+func toBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	case float64:
+		// encoding/json decodes numbers as float64
+		if v != float64(int64(v)) {
+			return nil, fmt.Errorf("non-integer value: %v", v)
+		}
+		return big.NewInt(int64(v)), nil
+	case string:
+		n, ok := new(big.Int).SetString(v, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer: %s", v)
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("expected integer, got %T", value)
+	}
+}
+
+// DomainSeparator returns hashStruct(EIP712Domain)
+func (td *TypedData) DomainSeparator() ([]byte, error) {
+	return td.HashStruct(eip712DomainType, td.Domain.domainMessage())
+}
+
+// SigningHash returns the EIP-712 digest: keccak256("\x19\x01" || domainSeparator || hashStruct(message))
+func (td *TypedData) SigningHash() ([]byte, error) {
+	domainSeparator, err := td.DomainSeparator()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash domain: %w", err)
+	}
+	messageHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash message: %w", err)
+	}
+	return Keccak256([]byte{0x19, 0x01}, domainSeparator, messageHash), nil
+}
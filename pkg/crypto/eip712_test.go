@@ -0,0 +1,144 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/useeasycash/ecash-sdk-core/pkg/types"
+)
+
+// mailTypedData is the reference example from the EIP-712 specification
+func mailTypedData() *TypedData {
+	return &TypedData{
+		Types: TypedDataTypes{
+			"Person": {
+				{Name: "name", Type: "string"},
+				{Name: "wallet", Type: "address"},
+			},
+			"Mail": {
+				{Name: "from", Type: "Person"},
+				{Name: "to", Type: "Person"},
+				{Name: "contents", Type: "string"},
+			},
+		},
+		PrimaryType: "Mail",
+		Domain: TypedDataDomain{
+			Name:              "Ether Mail",
+			Version:           "1",
+			ChainID:           big.NewInt(1),
+			VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+		},
+		Message: map[string]interface{}{
+			"from": map[string]interface{}{
+				"name":   "Cow",
+				"wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826",
+			},
+			"to": map[string]interface{}{
+				"name":   "Bob",
+				"wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+			},
+			"contents": "Hello, Bob!",
+		},
+	}
+}
+
+func TestEIP712MailVector(t *testing.T) {
+	td := mailTypedData()
+
+	encoded, err := td.EncodeType("Mail")
+	require.NoError(t, err)
+	assert.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", encoded)
+
+	typeHash, err := td.TypeHash("Mail")
+	require.NoError(t, err)
+	assert.Equal(t, "a0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2", hex.EncodeToString(typeHash))
+
+	domainSeparator, err := td.DomainSeparator()
+	require.NoError(t, err)
+	assert.Equal(t, "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f", hex.EncodeToString(domainSeparator))
+
+	messageHash, err := td.HashStruct("Mail", td.Message)
+	require.NoError(t, err)
+	assert.Equal(t, "c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e", hex.EncodeToString(messageHash))
+
+	digest, err := td.SigningHash()
+	require.NoError(t, err)
+	assert.Equal(t, "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hex.EncodeToString(digest))
+}
+
+func TestEIP712EncodeValueErrors(t *testing.T) {
+	td := mailTypedData()
+
+	td.Message["contents"] = 42
+	_, err := td.SigningHash()
+	assert.Error(t, err)
+
+	delete(td.Message, "contents")
+	_, err = td.SigningHash()
+	assert.Error(t, err)
+
+	_, err = encodeAtomic("uint8", 256)
+	assert.Error(t, err)
+
+	_, err = encodeAtomic("address", "0x1234")
+	assert.Error(t, err)
+
+	word, err := encodeAtomic("int8", -1)
+	require.NoError(t, err)
+	assert.Equal(t, "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", hex.EncodeToString(word))
+}
+
+func TestIntentTypedData(t *testing.T) {
+	req := &types.TransactionRequest{
+		ReferenceID: "ref_001",
+		Type:        types.IntentTransfer,
+		Amount:      "1000.00",
+		Asset:       "USDC",
+		Recipient:   "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+		SourceChain: types.ChainBase,
+		IsShielded:  true,
+	}
+
+	domain, err := NewIntentDomain(req.SourceChain, "0x0000000000000000000000000000000000000001")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8453), domain.ChainID.Int64())
+
+	td := IntentTypedData(req, domain)
+	encoded, err := td.EncodeType(IntentPrimaryType)
+	require.NoError(t, err)
+	assert.Equal(t, "TransactionIntent(string referenceId,string intentType,string amount,string asset,string recipient,string sourceChain,string targetChain,bool isShielded,"+
+		"string[] nullifiers,string anchor,string recipientKey,string memo,string stealthMetaAddress,string stealthEphemeralKey)", encoded)
+
+	// Recompute the digest by hand from the specification's encoding rules
+	shielded := make([]byte, 32)
+	shielded[31] = 1
+	messageHash := Keccak256(
+		Keccak256([]byte(encoded)),
+		Keccak256([]byte("ref_001")),
+		Keccak256([]byte("transfer")),
+		Keccak256([]byte("1000.00")),
+		Keccak256([]byte("USDC")),
+		Keccak256([]byte("0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0")),
+		Keccak256([]byte("base")),
+		Keccak256([]byte("")),
+		shielded,
+		Keccak256(nil), // no nullifiers
+		Keccak256([]byte("")),
+		Keccak256([]byte("")),
+		Keccak256([]byte("")),
+		Keccak256([]byte("")),
+		Keccak256([]byte("")),
+	)
+	domainSeparator, err := td.DomainSeparator()
+	require.NoError(t, err)
+
+	digest, err := IntentSigningHash(req, "0x0000000000000000000000000000000000000001")
+	require.NoError(t, err)
+	assert.Equal(t, Keccak256([]byte{0x19, 0x01}, domainSeparator, messageHash), digest)
+
+	_, err = IntentSigningHash(&types.TransactionRequest{SourceChain: types.ChainSolana}, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,258 @@
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	encryptedKeyVersion = 1
+	cipherAES256GCM     = "aes-256-gcm"
+)
+
+// ErrWrongPassphrase is returned when an encrypted key cannot be authenticated
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted key file")
+
+// encryptedKey is the on-disk representation of a passphrase-protected private key
+type encryptedKey struct {
+	Version    int             `json:"version"`
+	ID         string          `json:"id,omitempty"`
+	Algorithm  Algorithm       `json:"algorithm"`
+	PublicKey  string          `json:"public_key"`
+	Address    string          `json:"address,omitempty"`
+	KDF        string          `json:"kdf"`
+	KDFParams  json.RawMessage `json:"kdfparams"`
+	Salt       string          `json:"salt"`
+	Cipher     string          `json:"cipher"`
+	Nonce      string          `json:"nonce"`
+	Ciphertext string          `json:"ciphertext"`
+}
+
+// deriveKey runs the file's KDF over the passphrase
+func (k *encryptedKey) deriveKey(passphrase string) ([]byte, error) {
+	kdf, err := parseKDF(k.KDF, k.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := hex.DecodeString(k.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	return kdf.deriveKey([]byte(passphrase), salt, 32)
+}
+
+// open decrypts the private key with a derived key-encryption key
+func (k *encryptedKey) open(derived []byte) ([]byte, error) {
+	if k.Cipher != cipherAES256GCM {
+		return nil, fmt.Errorf("unsupported cipher: %s", k.Cipher)
+	}
+	nonce, err := hex.DecodeString(k.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(k.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	aead, err := newGCM(derived)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length: %d", len(nonce))
+	}
+	// The header is bound as associated data so it cannot be swapped between files
+	plaintext, err := aead.Open(nil, nonce, ciphertext, k.associatedData())
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+func (k *encryptedKey) associatedData() []byte {
+	return []byte(fmt.Sprintf("%d|%s|%s", k.Version, k.Algorithm, k.PublicKey))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealKey encrypts a raw private key under a passphrase
+func sealKey(algorithm Algorithm, privKey []byte, passphrase string, kdf KDF) (*encryptedKey, error) {
+	pubKey, err := derivePublicKey(algorithm, privKey)
+	if err != nil {
+		return nil, err
+	}
+	params, err := json.Marshal(kdf)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	k := &encryptedKey{
+		Version:   encryptedKeyVersion,
+		Algorithm: algorithm,
+		PublicKey: hex.EncodeToString(pubKey),
+		KDF:       kdf.Name(),
+		KDFParams: params,
+		Salt:      hex.EncodeToString(salt),
+		Cipher:    cipherAES256GCM,
+	}
+
+	derived, err := k.deriveKey(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("key derivation failed: %w", err)
+	}
+	defer zeroBytes(derived)
+
+	aead, err := newGCM(derived)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	k.Nonce = hex.EncodeToString(nonce)
+	k.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, privKey, k.associatedData()))
+	return k, nil
+}
+
+// WriteEncryptedKey encrypts a private key (any type accepted by NewMemorySigner)
+// under a passphrase and writes it to path with owner-only permissions
+func WriteEncryptedKey(path string, key interface{}, passphrase string, kdf KDF) error {
+	algorithm, privKey, err := keyMaterial(key)
+	if err != nil {
+		return err
+	}
+	defer zeroBytes(privKey)
+
+	sealed, err := sealKey(algorithm, privKey, passphrase, kdf)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(sealed, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// FileSigner is a Signer backed by an encrypted key file.
+// The plaintext private key only exists for the duration of each Sign call, but the
+// key-encryption key derived from the passphrase stays in memory for the signer's
+// lifetime, so the process memory is as sensitive as the unencrypted key.
+type FileSigner struct {
+	key       *encryptedKey
+	derived   []byte // key-encryption key, kept so Sign need not rerun the KDF
+	publicKey []byte
+}
+
+// readEncryptedKey loads and parses a key file
+func readEncryptedKey(path string) (*encryptedKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var key encryptedKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("failed to parse key file: %w", err)
+	}
+	if key.Version != encryptedKeyVersion {
+		return nil, fmt.Errorf("unsupported key file version: %d", key.Version)
+	}
+	return &key, nil
+}
+
+// unlock derives the key-encryption key and decrypts the private key,
+// checking it against the stored public key. The caller must zero both results.
+func (k *encryptedKey) unlock(passphrase string) (privKey, derived []byte, err error) {
+	derived, err = k.deriveKey(passphrase)
+	if err != nil {
+		return nil, nil, fmt.Errorf("key derivation failed: %w", err)
+	}
+
+	privKey, err = k.open(derived)
+	if err != nil {
+		zeroBytes(derived)
+		return nil, nil, err
+	}
+
+	pubKey, err := derivePublicKey(k.Algorithm, privKey)
+	if err == nil && hex.EncodeToString(pubKey) != k.PublicKey {
+		err = fmt.Errorf("key file public key does not match private key")
+	}
+	if err != nil {
+		zeroBytes(privKey)
+		zeroBytes(derived)
+		return nil, nil, err
+	}
+	return privKey, derived, nil
+}
+
+// NewFileSigner opens an encrypted key file written by WriteEncryptedKey
+func NewFileSigner(path, passphrase string) (*FileSigner, error) {
+	key, err := readEncryptedKey(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt once up front so a wrong passphrase fails here rather than on first use
+	privKey, derived, err := key.unlock(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(privKey)
+
+	pubKey, err := hex.DecodeString(key.PublicKey)
+	if err != nil {
+		zeroBytes(derived)
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	return &FileSigner{
+		key:       key,
+		derived:   derived,
+		publicKey: pubKey,
+	}, nil
+}
+
+// Algorithm returns the signature scheme of the key
+func (s *FileSigner) Algorithm() Algorithm {
+	return s.key.Algorithm
+}
+
+// PublicKey returns the serialized public key
+func (s *FileSigner) PublicKey() []byte {
+	return append([]byte(nil), s.publicKey...)
+}
+
+// Sign decrypts the key, signs the digest and wipes the plaintext key
+func (s *FileSigner) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	privKey, err := s.key.open(s.derived)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(privKey)
+	return signWithKey(s.key.Algorithm, privKey, digest)
+}
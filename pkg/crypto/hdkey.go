@@ -0,0 +1,312 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// HardenedOffset is added to a child index to request hardened derivation
+const HardenedOffset uint32 = 0x80000000
+
+var (
+	// ErrInvalidChild is returned for the ~1 in 2^127 indices that yield an invalid key;
+	// callers should skip to the next index
+	ErrInvalidChild = errors.New("derived key is invalid, use the next index")
+
+	// Version bytes of mainnet extended keys (xprv / xpub)
+	xprvVersion = []byte{0x04, 0x88, 0xad, 0xe4}
+	xpubVersion = []byte{0x04, 0x88, 0xb2, 0x1e}
+)
+
+// ExtendedKey is a BIP-32 secp256k1 extended key, private or public
+type ExtendedKey struct {
+	key         []byte // 32-byte private scalar or 33-byte compressed public key
+	chainCode   []byte
+	depth       uint8
+	parentFP    []byte
+	childNumber uint32
+	private     bool
+}
+
+// NewMasterKey derives the BIP-32 master key from a seed (16-64 bytes)
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length: %d", len(seed))
+	}
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	var scalar secp256k1.ModNScalar
+	if overflow := scalar.SetByteSlice(sum[:32]); overflow || scalar.IsZero() {
+		return nil, ErrInvalidChild
+	}
+
+	return &ExtendedKey{
+		key:       sum[:32],
+		chainCode: sum[32:],
+		parentFP:  make([]byte, 4),
+		private:   true,
+	}, nil
+}
+
+// IsPrivate reports whether the key can derive private children and sign
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.private
+}
+
+// Depth returns the number of derivation steps from the master key
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+// publicKeyBytes returns the 33-byte compressed public key
+func (k *ExtendedKey) publicKeyBytes() []byte {
+	if !k.private {
+		return k.key
+	}
+	return secp256k1.PrivKeyFromBytes(k.key).PubKey().SerializeCompressed()
+}
+
+// fingerprint returns the first 4 bytes of HASH160(public key)
+func (k *ExtendedKey) fingerprint() []byte {
+	sha := sha256.Sum256(k.publicKeyBytes())
+	h := ripemd160.New()
+	h.Write(sha[:])
+	return h.Sum(nil)[:4]
+}
+
+// Child derives the child key at index; indices >= HardenedOffset are hardened.
+// Public keys can only derive non-hardened children.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	hardened := index >= HardenedOffset
+	if hardened && !k.private {
+		return nil, fmt.Errorf("cannot derive hardened child from public key")
+	}
+
+	data := make([]byte, 0, 37)
+	if hardened {
+		data = append(data, 0x00)
+		data = append(data, k.key...)
+	} else {
+		data = append(data, k.publicKeyBytes()...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	zeroBytes(data)
+
+	var tweak secp256k1.ModNScalar
+	if overflow := tweak.SetByteSlice(sum[:32]); overflow {
+		return nil, ErrInvalidChild
+	}
+
+	child := &ExtendedKey{
+		chainCode:   sum[32:],
+		depth:       k.depth + 1,
+		parentFP:    k.fingerprint(),
+		childNumber: index,
+		private:     k.private,
+	}
+
+	if k.private {
+		// k_i = IL + k_par (mod n)
+		var parent secp256k1.ModNScalar
+		parent.SetByteSlice(k.key)
+		tweak.Add(&parent)
+		if tweak.IsZero() {
+			return nil, ErrInvalidChild
+		}
+		childKey := tweak.Bytes()
+		child.key = childKey[:]
+		return child, nil
+	}
+
+	// K_i = point(IL) + K_par
+	parentKey, err := secp256k1.ParsePubKey(k.key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	var tweakPoint, parentPoint, result secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(&tweak, &tweakPoint)
+	parentKey.AsJacobian(&parentPoint)
+	secp256k1.AddNonConst(&tweakPoint, &parentPoint, &result)
+	if (result.X.IsZero() && result.Y.IsZero()) || result.Z.IsZero() {
+		return nil, ErrInvalidChild
+	}
+	result.ToAffine()
+	child.key = secp256k1.NewPublicKey(&result.X, &result.Y).SerializeCompressed()
+	return child, nil
+}
+
+// DerivePath derives a descendant from a path such as "m/44'/60'/0'/0/7".
+// A path starting with "m" must be derived from the master key.
+func (k *ExtendedKey) DerivePath(path string) (*ExtendedKey, error) {
+	indices, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(path, "m") && k.depth != 0 {
+		return nil, fmt.Errorf("absolute path %s requires the master key", path)
+	}
+
+	key := k
+	for _, index := range indices {
+		key, err = key.Child(index)
+		if err != nil {
+			return nil, fmt.Errorf("derivation of %s failed: %w", path, err)
+		}
+	}
+	return key, nil
+}
+
+// Neuter returns the public extended key, which can derive non-hardened child addresses
+// (e.g. per-customer deposit addresses) without access to private keys
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	if !k.private {
+		return k
+	}
+	return &ExtendedKey{
+		key:         k.publicKeyBytes(),
+		chainCode:   k.chainCode,
+		depth:       k.depth,
+		parentFP:    k.parentFP,
+		childNumber: k.childNumber,
+	}
+}
+
+// Signer returns a secp256k1 signer for a private extended key
+func (k *ExtendedKey) Signer() (*Secp256k1Signer, error) {
+	if !k.private {
+		return nil, fmt.Errorf("public extended key cannot sign")
+	}
+	return Secp256k1SignerFromBytes(k.key)
+}
+
+// Address returns the Ethereum address of the key; it works for public keys too
+func (k *ExtendedKey) Address() (string, error) {
+	pub, err := secp256k1.ParsePubKey(k.publicKeyBytes())
+	if err != nil {
+		return "", fmt.Errorf("invalid public key: %w", err)
+	}
+	return PubkeyToAddress(pub.ToECDSA()), nil
+}
+
+// String serializes the key in Base58Check xprv / xpub form
+func (k *ExtendedKey) String() string {
+	buf := make([]byte, 0, 82)
+	if k.private {
+		buf = append(buf, xprvVersion...)
+	} else {
+		buf = append(buf, xpubVersion...)
+	}
+	buf = append(buf, k.depth)
+	buf = append(buf, k.parentFP...)
+	buf = binary.BigEndian.AppendUint32(buf, k.childNumber)
+	buf = append(buf, k.chainCode...)
+	if k.private {
+		buf = append(buf, 0x00)
+	}
+	buf = append(buf, k.key...)
+
+	checksum := doubleSHA256(buf)
+	return base58.Encode(append(buf, checksum[:4]...))
+}
+
+// ParseExtendedKey decodes a Base58Check xprv / xpub string
+func ParseExtendedKey(s string) (*ExtendedKey, error) {
+	raw, err := base58.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended key encoding: %w", err)
+	}
+	if len(raw) != 82 {
+		return nil, fmt.Errorf("invalid extended key length: %d", len(raw))
+	}
+	payload, checksum := raw[:78], raw[78:]
+	if !bytes.Equal(doubleSHA256(payload)[:4], checksum) {
+		return nil, fmt.Errorf("invalid extended key checksum")
+	}
+
+	k := &ExtendedKey{
+		depth:       payload[4],
+		parentFP:    payload[5:9],
+		childNumber: binary.BigEndian.Uint32(payload[9:13]),
+		chainCode:   payload[13:45],
+	}
+	keyData := payload[45:78]
+
+	switch {
+	case bytes.Equal(payload[:4], xprvVersion):
+		if keyData[0] != 0x00 {
+			return nil, fmt.Errorf("invalid private key prefix")
+		}
+		var scalar secp256k1.ModNScalar
+		if overflow := scalar.SetByteSlice(keyData[1:]); overflow || scalar.IsZero() {
+			return nil, fmt.Errorf("private key out of range")
+		}
+		k.key = keyData[1:]
+		k.private = true
+	case bytes.Equal(payload[:4], xpubVersion):
+		if _, err := secp256k1.ParsePubKey(keyData); err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		k.key = keyData
+	default:
+		return nil, fmt.Errorf("unsupported extended key version %x", payload[:4])
+	}
+
+	if k.depth == 0 && (k.childNumber != 0 || !bytes.Equal(k.parentFP, make([]byte, 4))) {
+		return nil, fmt.Errorf("master key with non-zero parent or index")
+	}
+	return k, nil
+}
+
+func doubleSHA256(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// ParseDerivationPath parses "m/44'/60'/0'/0/0" (or a relative "0/1") into child indices.
+// Hardened segments may be marked with ' or h.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	segments := strings.Split(strings.TrimSpace(path), "/")
+	if segments[0] == "m" {
+		segments = segments[1:]
+	}
+
+	indices := make([]uint32, 0, len(segments))
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid derivation path: %s", path)
+		}
+		hardened := strings.HasSuffix(segment, "'") || strings.HasSuffix(segment, "h") || strings.HasSuffix(segment, "H")
+		if hardened {
+			segment = segment[:len(segment)-1]
+		}
+		value, err := strconv.ParseUint(segment, 10, 32)
+		if err != nil || uint32(value) >= HardenedOffset {
+			return nil, fmt.Errorf("invalid derivation path segment %q in %s", segment, path)
+		}
+		index := uint32(value)
+		if hardened {
+			index += HardenedOffset
+		}
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
@@ -0,0 +1,116 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestMnemonic(t *testing.T) {
+	mnemonic, err := NewMnemonic(256)
+	require.NoError(t, err)
+	assert.NoError(t, ValidateMnemonic(mnemonic))
+
+	_, err = NewMnemonic(100)
+	assert.Error(t, err)
+
+	// BIP-39 reference vector
+	seed, err := MnemonicToSeed(testMnemonic, "TREZOR")
+	require.NoError(t, err)
+	assert.Equal(t, "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04", hex.EncodeToString(seed))
+
+	// Bad checksum and unknown words are rejected
+	assert.Error(t, ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"))
+	_, err = MnemonicToSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon notaword", "")
+	assert.Error(t, err)
+}
+
+func TestBIP32Vector1(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	require.NoError(t, err)
+	assert.Equal(t, "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi", master.String())
+	assert.Equal(t, "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8", master.Neuter().String())
+
+	child, err := master.DerivePath("m/0'")
+	require.NoError(t, err)
+	assert.Equal(t, "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7", child.String())
+	assert.Equal(t, "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw", child.Neuter().String())
+
+	// Public derivation of a non-hardened child matches private derivation
+	priv, err := child.Child(1)
+	require.NoError(t, err)
+	pub, err := child.Neuter().Child(1)
+	require.NoError(t, err)
+	assert.Equal(t, "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ", pub.String())
+	assert.Equal(t, priv.Neuter().String(), pub.String())
+
+	_, err = child.Neuter().Child(HardenedOffset)
+	assert.Error(t, err)
+	_, err = child.DerivePath("m/1")
+	assert.Error(t, err)
+}
+
+func TestParseExtendedKey(t *testing.T) {
+	for _, s := range []string{
+		"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+		"xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+	} {
+		key, err := ParseExtendedKey(s)
+		require.NoError(t, err)
+		assert.Equal(t, s, key.String())
+	}
+
+	// Corrupted checksum
+	_, err := ParseExtendedKey("xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet9")
+	assert.Error(t, err)
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	indices, err := ParseDerivationPath("m/44'/60h/0'/0/7")
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{44 + HardenedOffset, 60 + HardenedOffset, HardenedOffset, 0, 7}, indices)
+
+	for _, path := range []string{"m//0", "m/x", "m/2147483648", "m/0''"} {
+		_, err := ParseDerivationPath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestSLIP10Ed25519Vector1(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewEd25519MasterKey(seed)
+	require.NoError(t, err)
+	assert.Equal(t, "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7", hex.EncodeToString(master.PrivateKey()))
+	assert.Equal(t, "90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb", hex.EncodeToString(master.ChainCode()))
+
+	child, err := master.DerivePath("m/0'")
+	require.NoError(t, err)
+	assert.Equal(t, "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3", hex.EncodeToString(child.PrivateKey()))
+	assert.Equal(t, "8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69", hex.EncodeToString(child.ChainCode()))
+
+	_, err = master.DerivePath("m/0")
+	assert.Error(t, err)
+}
+
+func TestDeriveStandardSigners(t *testing.T) {
+	evm, err := DeriveEVMSigner(testMnemonic, "", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "0x9858EfFD232B4033E47d90003D41EC34EcaEda94", evm.Address())
+
+	sol, err := DeriveSolanaSigner(testMnemonic, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk", sol.Address())
+
+	// Derived signers work with the Signer backends
+	signer, err := NewMemorySigner(evm)
+	require.NoError(t, err)
+	digest := Keccak256([]byte("intent"))
+	sig, err := signer.Sign(t.Context(), digest)
+	require.NoError(t, err)
+	assert.NoError(t, VerifyDigest(AlgorithmSecp256k1, signer.PublicKey(), digest, sig))
+}
@@ -0,0 +1,57 @@
+package crypto
+
+import "fmt"
+
+// Standard BIP-44 coin types
+const (
+	CoinTypeEthereum uint32 = 60
+	CoinTypeSolana   uint32 = 501
+)
+
+// EVMPath returns the BIP-44 path used by MetaMask and most EVM wallets: m/44'/60'/account'/0/index
+func EVMPath(account, index uint32) string {
+	return fmt.Sprintf("m/44'/%d'/%d'/0/%d", CoinTypeEthereum, account, index)
+}
+
+// SolanaPath returns the SLIP-10 path used by Phantom and the Solana CLI: m/44'/501'/account'/0'
+func SolanaPath(account uint32) string {
+	return fmt.Sprintf("m/44'/%d'/%d'/0'", CoinTypeSolana, account)
+}
+
+// DeriveEVMSigner derives the secp256k1 signer at EVMPath(account, index) from a mnemonic
+func DeriveEVMSigner(mnemonic, passphrase string, account, index uint32) (*Secp256k1Signer, error) {
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(seed)
+
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	key, err := master.DerivePath(EVMPath(account, index))
+	if err != nil {
+		return nil, err
+	}
+	return key.Signer()
+}
+
+// DeriveSolanaSigner derives the Ed25519 signer at SolanaPath(account) from a mnemonic
+func DeriveSolanaSigner(mnemonic, passphrase string, account uint32) (*Ed25519Signer, error) {
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(seed)
+
+	master, err := NewEd25519MasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	key, err := master.DerivePath(SolanaPath(account))
+	if err != nil {
+		return nil, err
+	}
+	return key.Signer(), nil
+}
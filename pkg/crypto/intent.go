@@ -0,0 +1,134 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/useeasycash/ecash-sdk-core/pkg/types"
+)
+
+// IntentPrimaryType is the EIP-712 struct name for transaction intents
+const IntentPrimaryType = "TransactionIntent"
+
+const (
+	intentDomainName    = "EasyCash"
+	intentDomainVersion = "1"
+)
+
+// IntentTypes is the EIP-712 schema custody partners co-sign for a TransactionRequest.
+// It covers every field that decides where funds go, so a signature cannot be
+// replayed with another recipient key, spent note set or stealth announcement.
+var IntentTypes = TypedDataTypes{
+	IntentPrimaryType: {
+		{Name: "referenceId", Type: "string"},
+		{Name: "intentType", Type: "string"},
+		{Name: "amount", Type: "string"},
+		{Name: "asset", Type: "string"},
+		{Name: "recipient", Type: "string"},
+		{Name: "sourceChain", Type: "string"},
+		{Name: "targetChain", Type: "string"},
+		{Name: "isShielded", Type: "bool"},
+		{Name: "nullifiers", Type: "string[]"},
+		{Name: "anchor", Type: "string"},
+		{Name: "recipientKey", Type: "string"},
+		{Name: "memo", Type: "string"},
+		{Name: "stealthMetaAddress", Type: "string"},
+		{Name: "stealthEphemeralKey", Type: "string"},
+	},
+}
+
+// evmChainIDs maps supported EVM networks to their EIP-155 chain IDs
+var evmChainIDs = map[types.ChainID]int64{
+	types.ChainEthereum: 1,
+	types.ChainBase:     8453,
+}
+
+// EVMChainID returns the EIP-155 chain ID for an EVM network
+func EVMChainID(chain types.ChainID) (*big.Int, error) {
+	id, ok := evmChainIDs[chain]
+	if !ok {
+		return nil, fmt.Errorf("chain %s is not an EVM chain", chain)
+	}
+	return big.NewInt(id), nil
+}
+
+// NewIntentDomain returns the EasyCash EIP-712 domain for the given chain and verifying contract
+func NewIntentDomain(chain types.ChainID, verifyingContract string) (TypedDataDomain, error) {
+	chainID, err := EVMChainID(chain)
+	if err != nil {
+		return TypedDataDomain{}, err
+	}
+	return TypedDataDomain{
+		Name:              intentDomainName,
+		Version:           intentDomainVersion,
+		ChainID:           chainID,
+		VerifyingContract: verifyingContract,
+	}, nil
+}
+
+// IntentTypedData maps a TransactionRequest into its EIP-712 typed-data representation
+func IntentTypedData(req *types.TransactionRequest, domain TypedDataDomain) *TypedData {
+	nullifiers := make([]interface{}, len(req.Nullifiers))
+	for i, n := range req.Nullifiers {
+		nullifiers[i] = n
+	}
+	var stealth types.StealthPayment
+	if req.Stealth != nil {
+		stealth = *req.Stealth
+	}
+	return &TypedData{
+		Types:       IntentTypes,
+		PrimaryType: IntentPrimaryType,
+		Domain:      domain,
+		Message: map[string]interface{}{
+			"referenceId": req.ReferenceID,
+			"intentType":  string(req.Type),
+			"amount":      req.Amount,
+			"asset":       req.Asset,
+			"recipient":   req.Recipient,
+			"sourceChain": string(req.SourceChain),
+			"targetChain": string(req.TargetChain),
+			"isShielded":  req.IsShielded,
+
+			"nullifiers":          nullifiers,
+			"anchor":              req.Anchor,
+			"recipientKey":        req.RecipientKey,
+			"memo":                req.Memo,
+			"stealthMetaAddress":  stealth.MetaAddress,
+			"stealthEphemeralKey": stealth.EphemeralPubKey,
+		},
+	}
+}
+
+// IntentSigningHash returns the EIP-712 digest of a TransactionRequest on its source chain
+func IntentSigningHash(req *types.TransactionRequest, verifyingContract string) ([]byte, error) {
+	domain, err := NewIntentDomain(req.SourceChain, verifyingContract)
+	if err != nil {
+		return nil, err
+	}
+	return IntentTypedData(req, domain).SigningHash()
+}
+
+// CanonicalIntent returns the deterministic JSON encoding of a request without its signature or approvals
+func CanonicalIntent(req *types.TransactionRequest) ([]byte, error) {
+	unsigned := *req
+	unsigned.Signature = ""
+	unsigned.Approval = nil
+	return json.Marshal(&unsigned)
+}
+
+// IntentDigest returns the 32-byte digest a Signer authorizes for a request.
+// EVM source chains use the EIP-712 digest; other chains hash the canonical intent with SHA-256.
+func IntentDigest(req *types.TransactionRequest, verifyingContract string) ([]byte, error) {
+	if _, ok := evmChainIDs[req.SourceChain]; ok {
+		return IntentSigningHash(req, verifyingContract)
+	}
+	canonical, err := CanonicalIntent(req)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(canonical)
+	return digest[:], nil
+}
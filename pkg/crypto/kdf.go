@@ -0,0 +1,96 @@
+package crypto
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	kdfScrypt   = "scrypt"
+	kdfArgon2id = "argon2id"
+)
+
+// KDF derives a key-encryption key from a passphrase.
+// It is implemented by ScryptParams and Argon2Params.
+type KDF interface {
+	Name() string
+	deriveKey(passphrase, salt []byte, keyLen int) ([]byte, error)
+}
+
+// ScryptParams are the scrypt cost parameters used to derive the key-encryption key
+type ScryptParams struct {
+	N int `json:"n"`
+	R int `json:"r"`
+	P int `json:"p"`
+}
+
+// Argon2Params are the Argon2id cost parameters; Memory is in KiB
+type Argon2Params struct {
+	Time    uint32 `json:"t"`
+	Memory  uint32 `json:"m"`
+	Threads uint8  `json:"p"`
+}
+
+var (
+	// StandardScryptParams matches the interactive-login cost recommended for keys at rest
+	StandardScryptParams = ScryptParams{N: 1 << 18, R: 8, P: 1}
+	// LightScryptParams trades security for speed, for tests and constrained devices
+	LightScryptParams = ScryptParams{N: 1 << 12, R: 8, P: 6}
+	// StandardArgon2Params follows the RFC 9106 second recommended option
+	StandardArgon2Params = Argon2Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+)
+
+// maxKDFMemory bounds the memory one unlock may use, so a hostile key file cannot
+// exhaust memory; it admits StandardScryptParams exactly
+const maxKDFMemory = 256 << 20
+
+// Name returns the KDF identifier stored in key files
+func (p ScryptParams) Name() string {
+	return kdfScrypt
+}
+
+func (p ScryptParams) deriveKey(passphrase, salt []byte, keyLen int) ([]byte, error) {
+	if p.N <= 1 || p.N&(p.N-1) != 0 || p.N > 1<<22 || p.R <= 0 || p.R > 32 || p.P <= 0 || p.P > 16 {
+		return nil, fmt.Errorf("invalid scrypt parameters: n=%d r=%d p=%d", p.N, p.R, p.P)
+	}
+	// scrypt's working set is 128*N*r bytes
+	if 128*int64(p.N)*int64(p.R) > maxKDFMemory {
+		return nil, fmt.Errorf("scrypt parameters exceed the %d MiB memory limit: n=%d r=%d", maxKDFMemory>>20, p.N, p.R)
+	}
+	return scrypt.Key(passphrase, salt, p.N, p.R, p.P, keyLen)
+}
+
+// Name returns the KDF identifier stored in key files
+func (p Argon2Params) Name() string {
+	return kdfArgon2id
+}
+
+func (p Argon2Params) deriveKey(passphrase, salt []byte, keyLen int) ([]byte, error) {
+	if p.Time == 0 || p.Time > 16 || p.Memory < 8*uint32(p.Threads) || p.Memory > maxKDFMemory>>10 || p.Threads == 0 {
+		return nil, fmt.Errorf("invalid argon2 parameters: t=%d m=%d p=%d", p.Time, p.Memory, p.Threads)
+	}
+	return argon2.IDKey(passphrase, salt, p.Time, p.Memory, p.Threads, uint32(keyLen)), nil
+}
+
+// parseKDF decodes the KDF parameters stored alongside an encrypted key
+func parseKDF(name string, params json.RawMessage) (KDF, error) {
+	switch name {
+	case kdfScrypt:
+		var p ScryptParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("invalid scrypt parameters: %w", err)
+		}
+		return p, nil
+	case kdfArgon2id:
+		var p Argon2Params
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("invalid argon2 parameters: %w", err)
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("unsupported kdf: %s", name)
+	}
+}
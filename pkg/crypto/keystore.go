@@ -0,0 +1,378 @@
+package crypto
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/google/uuid"
+	"github.com/mr-tron/base58"
+	"github.com/useeasycash/ecash-sdk-core/pkg/fileutil"
+)
+
+var (
+	ErrKeyNotFound = errors.New("key not found")
+	ErrKeyLocked   = errors.New("key is locked")
+)
+
+// KeyInfo describes a stored key without exposing key material
+type KeyInfo struct {
+	ID        string
+	Algorithm Algorithm
+	PublicKey []byte
+	Address   string // Ethereum address for secp256k1, Solana address for ed25519
+}
+
+// unlockedKey is a decrypted key kept in memory until it is locked or times out
+type unlockedKey struct {
+	signer *MemorySigner
+	timer  *time.Timer
+}
+
+// Keystore persists passphrase-encrypted signing keys in a directory, one file per key.
+// Files use the SDK's AES-256-GCM key format; Web3 Secret Storage v3 files can be
+// imported and exported for interoperability with Ethereum tooling.
+type Keystore struct {
+	dir string
+	kdf KDF
+
+	mu       sync.Mutex
+	unlocked map[string]*unlockedKey
+}
+
+// NewKeystore opens (creating if needed) a keystore directory. New keys are
+// encrypted with kdf; existing keys keep the parameters they were written with.
+func NewKeystore(dir string, kdf KDF) (*Keystore, error) {
+	if kdf == nil {
+		kdf = StandardScryptParams
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create keystore directory: %w", err)
+	}
+	return &Keystore{
+		dir:      dir,
+		kdf:      kdf,
+		unlocked: make(map[string]*unlockedKey),
+	}, nil
+}
+
+func (ks *Keystore) path(id string) (string, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+	return filepath.Join(ks.dir, id+".json"), nil
+}
+
+// Create generates a new key of the given algorithm and stores it encrypted
+func (ks *Keystore) Create(algorithm Algorithm, passphrase string) (*KeyInfo, error) {
+	var key interface{}
+	var err error
+	switch algorithm {
+	case AlgorithmECDSAP256:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmSecp256k1:
+		key, err = secp256k1.GeneratePrivateKey()
+	case AlgorithmEd25519:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key generation failed: %w", err)
+	}
+	return ks.Import(key, passphrase)
+}
+
+// Import stores an existing key (any type accepted by NewMemorySigner) encrypted under passphrase
+func (ks *Keystore) Import(key interface{}, passphrase string) (*KeyInfo, error) {
+	algorithm, privKey, err := keyMaterial(key)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(privKey)
+
+	sealed, err := sealKey(algorithm, privKey, passphrase, ks.kdf)
+	if err != nil {
+		return nil, err
+	}
+	sealed.ID = uuid.New().String()
+	pubKey, _ := hex.DecodeString(sealed.PublicKey)
+	sealed.Address = keyAddress(algorithm, pubKey)
+
+	if err := ks.write(sealed); err != nil {
+		return nil, err
+	}
+	return sealed.info(), nil
+}
+
+// ImportWeb3 imports a Web3 Secret Storage v3 key file, re-encrypting it under newPassphrase
+func (ks *Keystore) ImportWeb3(data []byte, passphrase, newPassphrase string) (*KeyInfo, error) {
+	signer, err := DecryptWeb3Key(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return ks.Import(signer, newPassphrase)
+}
+
+// Export returns the raw private key. Handle the result with care and zero it after use.
+func (ks *Keystore) Export(id, passphrase string) ([]byte, error) {
+	key, err := ks.read(id)
+	if err != nil {
+		return nil, err
+	}
+	privKey, derived, err := key.unlock(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	zeroBytes(derived)
+	return privKey, nil
+}
+
+// ExportWeb3 returns a secp256k1 key as a Web3 Secret Storage v3 key file encrypted under exportPassphrase
+func (ks *Keystore) ExportWeb3(id, passphrase, exportPassphrase string, params ScryptParams) ([]byte, error) {
+	privKey, err := ks.Export(id, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(privKey)
+
+	key, err := ks.read(id)
+	if err != nil {
+		return nil, err
+	}
+	if key.Algorithm != AlgorithmSecp256k1 {
+		return nil, fmt.Errorf("web3 key files only support secp256k1 keys, got %s", key.Algorithm)
+	}
+	signer, err := Secp256k1SignerFromBytes(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptWeb3Key(signer, exportPassphrase, params)
+}
+
+// List returns all stored keys sorted by ID
+func (ks *Keystore) List() ([]KeyInfo, error) {
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore directory: %w", err)
+	}
+
+	var keys []KeyInfo
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		key, err := ks.read(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			// Skip files that are not keystore keys
+			continue
+		}
+		keys = append(keys, *key.info())
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+// ChangePassphrase re-encrypts a key under a new passphrase. The file is replaced atomically.
+func (ks *Keystore) ChangePassphrase(id, oldPassphrase, newPassphrase string) error {
+	key, err := ks.read(id)
+	if err != nil {
+		return err
+	}
+	privKey, derived, err := key.unlock(oldPassphrase)
+	if err != nil {
+		return err
+	}
+	zeroBytes(derived)
+	defer zeroBytes(privKey)
+
+	sealed, err := sealKey(key.Algorithm, privKey, newPassphrase, ks.kdf)
+	if err != nil {
+		return err
+	}
+	sealed.ID = key.ID
+	sealed.Address = key.Address
+	return ks.write(sealed)
+}
+
+// Unlock decrypts a key and keeps it in memory for timeout (zero keeps it until Lock).
+// Unlocking an already unlocked key resets its timeout.
+func (ks *Keystore) Unlock(id, passphrase string, timeout time.Duration) error {
+	key, err := ks.read(id)
+	if err != nil {
+		return err
+	}
+	privKey, derived, err := key.unlock(passphrase)
+	if err != nil {
+		return err
+	}
+	zeroBytes(derived)
+
+	pubKey, _ := hex.DecodeString(key.PublicKey)
+	unlocked := &unlockedKey{
+		signer: &MemorySigner{algorithm: key.Algorithm, privateKey: privKey, publicKey: pubKey},
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.lockLocked(id)
+	if timeout > 0 {
+		unlocked.timer = time.AfterFunc(timeout, func() {
+			ks.mu.Lock()
+			defer ks.mu.Unlock()
+			// Only expire this unlock, not a later one that replaced it
+			if ks.unlocked[id] == unlocked {
+				ks.lockLocked(id)
+			}
+		})
+	}
+	ks.unlocked[id] = unlocked
+	return nil
+}
+
+// Lock wipes a decrypted key from memory
+func (ks *Keystore) Lock(id string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.lockLocked(id)
+}
+
+// LockAll wipes every decrypted key from memory
+func (ks *Keystore) LockAll() {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	for id := range ks.unlocked {
+		ks.lockLocked(id)
+	}
+}
+
+// lockLocked removes an unlocked key; ks.mu must be held
+func (ks *Keystore) lockLocked(id string) {
+	unlocked, ok := ks.unlocked[id]
+	if !ok {
+		return
+	}
+	if unlocked.timer != nil {
+		unlocked.timer.Stop()
+	}
+	zeroBytes(unlocked.signer.privateKey)
+	delete(ks.unlocked, id)
+}
+
+// IsUnlocked reports whether a key is currently decrypted in memory
+func (ks *Keystore) IsUnlocked(id string) bool {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	_, ok := ks.unlocked[id]
+	return ok
+}
+
+// Signer returns a Signer for a stored key. Signing fails with ErrKeyLocked unless the key is unlocked.
+func (ks *Keystore) Signer(id string) (Signer, error) {
+	key, err := ks.read(id)
+	if err != nil {
+		return nil, err
+	}
+	return &keystoreSigner{ks: ks, info: key.info()}, nil
+}
+
+// keystoreSigner signs with a keystore key while it is unlocked
+type keystoreSigner struct {
+	ks   *Keystore
+	info *KeyInfo
+}
+
+func (s *keystoreSigner) Algorithm() Algorithm {
+	return s.info.Algorithm
+}
+
+func (s *keystoreSigner) PublicKey() []byte {
+	return append([]byte(nil), s.info.PublicKey...)
+}
+
+func (s *keystoreSigner) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	s.ks.mu.Lock()
+	defer s.ks.mu.Unlock()
+
+	unlocked, ok := s.ks.unlocked[s.info.ID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyLocked, s.info.ID)
+	}
+	return unlocked.signer.Sign(ctx, digest)
+}
+
+func (ks *Keystore) read(id string) (*encryptedKey, error) {
+	path, err := ks.path(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+	key, err := readEncryptedKey(path)
+	if err != nil {
+		return nil, err
+	}
+	if key.ID != id {
+		return nil, fmt.Errorf("key file %s has mismatched id %s", id, key.ID)
+	}
+	return key, nil
+}
+
+// write stores a key via a temporary file and rename so a crash never leaves a partial key
+func (ks *Keystore) write(key *encryptedKey) error {
+	path, err := ks.path(key.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := fileutil.WriteAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write key: %w", err)
+	}
+	return nil
+}
+
+func (k *encryptedKey) info() *KeyInfo {
+	pubKey, _ := hex.DecodeString(k.PublicKey)
+	return &KeyInfo{
+		ID:        k.ID,
+		Algorithm: k.Algorithm,
+		PublicKey: pubKey,
+		Address:   k.Address,
+	}
+}
+
+// keyAddress derives the on-chain address for a serialized public key, if the algorithm has one
+func keyAddress(algorithm Algorithm, pubKey []byte) string {
+	switch algorithm {
+	case AlgorithmSecp256k1:
+		key, err := secp256k1.ParsePubKey(pubKey)
+		if err != nil {
+			return ""
+		}
+		return PubkeyToAddress(key.ToECDSA())
+	case AlgorithmEd25519:
+		return base58.Encode(pubKey)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,154 @@
+package crypto
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors from the Web3 Secret Storage Definition
+const (
+	web3TestPassphrase = "testpassword"
+	web3TestPrivateKey = "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d"
+	web3PBKDF2Vector   = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2","kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256","salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+	web3ScryptVector   = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"83dbcc02d8ccb40e466191a123791e0e"},"ciphertext":"d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c","kdf":"scrypt","kdfparams":{"dklen":32,"n":262144,"p":8,"r":1,"salt":"ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},"mac":"2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+)
+
+func TestDecryptWeb3KeyVectors(t *testing.T) {
+	for name, vector := range map[string]string{"pbkdf2": web3PBKDF2Vector, "scrypt": web3ScryptVector} {
+		t.Run(name, func(t *testing.T) {
+			if name == "scrypt" && testing.Short() {
+				t.Skip("scrypt vector uses n=262144")
+			}
+			signer, err := DecryptWeb3Key([]byte(vector), web3TestPassphrase)
+			require.NoError(t, err)
+			assert.Equal(t, web3TestPrivateKey, hex.EncodeToString(signer.PrivateKeyBytes()))
+
+			_, err = DecryptWeb3Key([]byte(vector), "wrong")
+			assert.ErrorIs(t, err, ErrWrongPassphrase)
+		})
+	}
+}
+
+func TestWeb3KeyRoundTrip(t *testing.T) {
+	signer, err := GenerateSecp256k1Signer()
+	require.NoError(t, err)
+
+	data, err := EncryptWeb3Key(signer, "pw", LightScryptParams)
+	require.NoError(t, err)
+
+	decrypted, err := DecryptWeb3Key(data, "pw")
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), decrypted.Address())
+}
+
+func TestKeystoreLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	ks, err := NewKeystore(dir, LightScryptParams)
+	require.NoError(t, err)
+
+	evm, err := ks.Create(AlgorithmSecp256k1, "pw")
+	require.NoError(t, err)
+	assert.Regexp(t, `^0x[0-9a-fA-F]{40}$`, evm.Address)
+
+	sol, err := ks.Create(AlgorithmEd25519, "pw")
+	require.NoError(t, err)
+	assert.NotEmpty(t, sol.Address)
+
+	keys, err := ks.List()
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	info, err := os.Stat(filepath.Join(dir, evm.ID+".json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Passphrase change keeps the key but rejects the old passphrase
+	require.NoError(t, ks.ChangePassphrase(evm.ID, "pw", "new-pw"))
+	assert.ErrorIs(t, ks.ChangePassphrase(evm.ID, "pw", "x"), ErrWrongPassphrase)
+	exported, err := ks.Export(evm.ID, "new-pw")
+	require.NoError(t, err)
+	signer, err := Secp256k1SignerFromBytes(exported)
+	require.NoError(t, err)
+	assert.Equal(t, evm.Address, signer.Address())
+
+	// Web3 export and re-import yields the same address
+	web3, err := ks.ExportWeb3(evm.ID, "new-pw", "export-pw", LightScryptParams)
+	require.NoError(t, err)
+	reimported, err := ks.ImportWeb3(web3, "export-pw", "pw")
+	require.NoError(t, err)
+	assert.Equal(t, evm.Address, reimported.Address)
+
+	_, err = ks.ExportWeb3(sol.ID, "pw", "x", LightScryptParams)
+	assert.Error(t, err)
+
+	_, err = ks.Export("00000000-0000-0000-0000-000000000000", "pw")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = ks.Export("../escape", "pw")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestKeystoreArgon2(t *testing.T) {
+	ks, err := NewKeystore(t.TempDir(), Argon2Params{Time: 1, Memory: 1024, Threads: 1})
+	require.NoError(t, err)
+
+	key, err := ks.Create(AlgorithmECDSAP256, "pw")
+	require.NoError(t, err)
+	require.NoError(t, ks.Unlock(key.ID, "pw", 0))
+
+	signer, err := ks.Signer(key.ID)
+	require.NoError(t, err)
+	digest := Keccak256([]byte("intent"))
+	sig, err := signer.Sign(context.Background(), digest)
+	require.NoError(t, err)
+	assert.NoError(t, VerifyDigest(AlgorithmECDSAP256, key.PublicKey, digest, sig))
+}
+
+func TestKeystoreUnlockTimeout(t *testing.T) {
+	ks, err := NewKeystore(t.TempDir(), LightScryptParams)
+	require.NoError(t, err)
+
+	key, err := ks.Create(AlgorithmSecp256k1, "pw")
+	require.NoError(t, err)
+	signer, err := ks.Signer(key.ID)
+	require.NoError(t, err)
+
+	digest := Keccak256([]byte("intent"))
+	_, err = signer.Sign(context.Background(), digest)
+	assert.ErrorIs(t, err, ErrKeyLocked)
+
+	assert.ErrorIs(t, ks.Unlock(key.ID, "wrong", time.Minute), ErrWrongPassphrase)
+	require.NoError(t, ks.Unlock(key.ID, "pw", 50*time.Millisecond))
+	assert.True(t, ks.IsUnlocked(key.ID))
+
+	sig, err := signer.Sign(context.Background(), digest)
+	require.NoError(t, err)
+	assert.NoError(t, VerifyDigest(AlgorithmSecp256k1, signer.PublicKey(), digest, sig))
+
+	assert.Eventually(t, func() bool { return !ks.IsUnlocked(key.ID) }, time.Second, 10*time.Millisecond)
+	_, err = signer.Sign(context.Background(), digest)
+	assert.ErrorIs(t, err, ErrKeyLocked)
+
+	require.NoError(t, ks.Unlock(key.ID, "pw", 0))
+	ks.LockAll()
+	assert.False(t, ks.IsUnlocked(key.ID))
+}
+
+func TestKDFMemoryLimit(t *testing.T) {
+	salt := make([]byte, 32)
+
+	// Each parameter is within its own range, but together they need 16 GiB
+	_, err := ScryptParams{N: 1 << 22, R: 32, P: 1}.deriveKey([]byte("pw"), salt, 32)
+	assert.ErrorContains(t, err, "memory limit")
+	_, err = Argon2Params{Time: 1, Memory: 4 * 1024 * 1024, Threads: 1}.deriveKey([]byte("pw"), salt, 32)
+	assert.Error(t, err)
+
+	_, err = LightScryptParams.deriveKey([]byte("pw"), salt, 32)
+	assert.NoError(t, err)
+}
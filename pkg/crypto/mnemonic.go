@@ -0,0 +1,43 @@
+package crypto
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// NewMnemonic generates a BIP-39 English mnemonic with the given entropy size.
+// bits must be a multiple of 32 between 128 (12 words) and 256 (24 words).
+func NewMnemonic(bits int) (string, error) {
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate entropy: %w", err)
+	}
+	defer zeroBytes(entropy)
+	return bip39.NewMnemonic(entropy)
+}
+
+// ValidateMnemonic checks the word count, word list membership and checksum of a mnemonic
+func ValidateMnemonic(mnemonic string) error {
+	entropy, err := bip39.EntropyFromMnemonic(normalizeMnemonic(mnemonic))
+	if err != nil {
+		return fmt.Errorf("invalid mnemonic: %w", err)
+	}
+	zeroBytes(entropy)
+	return nil
+}
+
+// MnemonicToSeed validates a mnemonic and derives its 64-byte BIP-39 seed
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	mnemonic = normalizeMnemonic(mnemonic)
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	return bip39.NewSeed(mnemonic, passphrase), nil
+}
+
+// normalizeMnemonic lowercases the mnemonic and collapses whitespace
+func normalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+}
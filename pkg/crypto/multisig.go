@@ -0,0 +1,223 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/useeasycash/ecash-sdk-core/pkg/types"
+)
+
+var (
+	ErrThresholdNotMet   = errors.New("approval threshold not met")
+	ErrUnknownApprover   = errors.New("signer is not an authorized approver")
+	ErrDuplicateApproval = errors.New("approver has already approved")
+)
+
+// Approver identifies a key allowed to approve intents
+type Approver struct {
+	Algorithm Algorithm
+	PublicKey []byte // as returned by Signer.PublicKey
+}
+
+// ApprovalPolicy requires Threshold distinct approvers out of Approvers to sign an intent
+type ApprovalPolicy struct {
+	Threshold int
+	Approvers []Approver
+}
+
+// Validate checks that the threshold is reachable and approvers are unique
+func (p *ApprovalPolicy) Validate() error {
+	if p.Threshold < 1 || p.Threshold > len(p.Approvers) {
+		return fmt.Errorf("invalid threshold %d of %d approvers", p.Threshold, len(p.Approvers))
+	}
+	seen := make(map[string]bool, len(p.Approvers))
+	for _, approver := range p.Approvers {
+		id := approverID(approver.Algorithm, approver.PublicKey)
+		if seen[id] {
+			return fmt.Errorf("duplicate approver %s", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func (p *ApprovalPolicy) isApprover(algorithm Algorithm, pubKey []byte) bool {
+	for _, approver := range p.Approvers {
+		if approver.Algorithm == algorithm && bytes.Equal(approver.PublicKey, pubKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func approverID(algorithm Algorithm, pubKey []byte) string {
+	return string(algorithm) + ":" + hex.EncodeToString(pubKey)
+}
+
+// ApprovalCoordinator collects and verifies approvals for a single intent until the
+// policy threshold is met. It is safe for concurrent use.
+type ApprovalCoordinator struct {
+	policy ApprovalPolicy
+	digest []byte
+
+	mu        sync.Mutex
+	approvals map[string]types.Approval
+}
+
+// NewApprovalCoordinator starts an approval round over the canonical intent digest of req
+func NewApprovalCoordinator(policy ApprovalPolicy, req *types.TransactionRequest, verifyingContract string) (*ApprovalCoordinator, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	digest, err := IntentDigest(req, verifyingContract)
+	if err != nil {
+		return nil, err
+	}
+	return &ApprovalCoordinator{
+		policy:    policy,
+		digest:    digest,
+		approvals: make(map[string]types.Approval),
+	}, nil
+}
+
+// Digest returns the intent digest approvers must sign
+func (c *ApprovalCoordinator) Digest() []byte {
+	return append([]byte(nil), c.digest...)
+}
+
+// AddApproval verifies a partial signature from an authorized approver and records it
+func (c *ApprovalCoordinator) AddApproval(algorithm Algorithm, pubKey, sig []byte) error {
+	if !c.policy.isApprover(algorithm, pubKey) {
+		return fmt.Errorf("%w: %s", ErrUnknownApprover, approverID(algorithm, pubKey))
+	}
+	if err := VerifyDigest(algorithm, pubKey, c.digest, sig); err != nil {
+		return fmt.Errorf("approval from %s rejected: %w", approverID(algorithm, pubKey), err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := approverID(algorithm, pubKey)
+	if _, ok := c.approvals[id]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateApproval, id)
+	}
+	c.approvals[id] = types.Approval{
+		Algorithm: string(algorithm),
+		PublicKey: hex.EncodeToString(pubKey),
+		Signature: hex.EncodeToString(sig),
+	}
+	return nil
+}
+
+// Collect asks each signer to approve the intent concurrently. It succeeds once the
+// threshold is met; individual signer failures are reported only if it is not.
+func (c *ApprovalCoordinator) Collect(ctx context.Context, signers ...Signer) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(signers))
+	for i, signer := range signers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sig, err := signer.Sign(ctx, c.digest)
+			if err == nil {
+				err = c.AddApproval(signer.Algorithm(), signer.PublicKey(), sig)
+			}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	if c.ThresholdMet() {
+		return nil
+	}
+	return thresholdError(c.Count(), c.policy.Threshold, errs)
+}
+
+// Count returns the number of verified approvals
+func (c *ApprovalCoordinator) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.approvals)
+}
+
+// ThresholdMet reports whether enough approvals have been collected
+func (c *ApprovalCoordinator) ThresholdMet() bool {
+	return c.Count() >= c.policy.Threshold
+}
+
+// Bundle returns the aggregated approvals, ordered by approver, once the threshold is met
+func (c *ApprovalCoordinator) Bundle() (*types.ApprovalBundle, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.approvals) < c.policy.Threshold {
+		return nil, fmt.Errorf("%w: %d of %d approvals", ErrThresholdNotMet, len(c.approvals), c.policy.Threshold)
+	}
+
+	ids := make([]string, 0, len(c.approvals))
+	for id := range c.approvals {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	bundle := &types.ApprovalBundle{
+		Digest:    hex.EncodeToString(c.digest),
+		Threshold: c.policy.Threshold,
+		Approvals: make([]types.Approval, 0, len(ids)),
+	}
+	for _, id := range ids {
+		bundle.Approvals = append(bundle.Approvals, c.approvals[id])
+	}
+	return bundle, nil
+}
+
+// VerifyApprovalBundle checks that req carries at least policy.Threshold valid approvals
+// from distinct authorized approvers over its current intent digest
+func VerifyApprovalBundle(policy ApprovalPolicy, req *types.TransactionRequest, verifyingContract string) error {
+	if req.Approval == nil {
+		return fmt.Errorf("%w: no approvals attached", ErrThresholdNotMet)
+	}
+	coordinator, err := NewApprovalCoordinator(policy, req, verifyingContract)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(strings.TrimPrefix(req.Approval.Digest, "0x"), hex.EncodeToString(coordinator.digest)) {
+		return fmt.Errorf("approvals were collected for a different intent")
+	}
+
+	var errs []error
+	for _, approval := range req.Approval.Approvals {
+		pubKey, err := hex.DecodeString(strings.TrimPrefix(approval.PublicKey, "0x"))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid approver public key: %w", err))
+			continue
+		}
+		sig, err := hex.DecodeString(strings.TrimPrefix(approval.Signature, "0x"))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid approval signature: %w", err))
+			continue
+		}
+		if err := coordinator.AddApproval(Algorithm(approval.Algorithm), pubKey, sig); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if !coordinator.ThresholdMet() {
+		return thresholdError(coordinator.Count(), policy.Threshold, errs)
+	}
+	return nil
+}
+
+// thresholdError reports a missed threshold together with the rejected approvals, if any
+func thresholdError(count, threshold int, errs []error) error {
+	if joined := errors.Join(errs...); joined != nil {
+		return fmt.Errorf("%w: %d of %d approvals: %w", ErrThresholdNotMet, count, threshold, joined)
+	}
+	return fmt.Errorf("%w: %d of %d approvals", ErrThresholdNotMet, count, threshold)
+}
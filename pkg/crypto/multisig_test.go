@@ -0,0 +1,134 @@
+package crypto
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/useeasycash/ecash-sdk-core/pkg/types"
+)
+
+// testApprovers returns one memory signer per algorithm and a 2-of-3 policy over them
+func testApprovers(t *testing.T) ([]Signer, ApprovalPolicy) {
+	var signers []Signer
+	policy := ApprovalPolicy{Threshold: 2}
+	for _, key := range testKeys(t) {
+		signer, err := NewMemorySigner(key)
+		require.NoError(t, err)
+		signers = append(signers, signer)
+		policy.Approvers = append(policy.Approvers, Approver{Algorithm: signer.Algorithm(), PublicKey: signer.PublicKey()})
+	}
+	return signers, policy
+}
+
+func TestApprovalCoordinator(t *testing.T) {
+	signers, policy := testApprovers(t)
+	req := &types.TransactionRequest{
+		ReferenceID: "treasury-1",
+		Type:        types.IntentTransfer,
+		Amount:      "250000",
+		Asset:       "USDC",
+		SourceChain: types.ChainBase,
+	}
+
+	coordinator, err := NewApprovalCoordinator(policy, req, "")
+	require.NoError(t, err)
+
+	// One approval is not enough
+	require.ErrorIs(t, coordinator.Collect(context.Background(), signers[0]), ErrThresholdNotMet)
+	_, err = coordinator.Bundle()
+	assert.ErrorIs(t, err, ErrThresholdNotMet)
+
+	// The same approver cannot count twice
+	sig, err := signers[0].Sign(context.Background(), coordinator.Digest())
+	require.NoError(t, err)
+	assert.ErrorIs(t, coordinator.AddApproval(signers[0].Algorithm(), signers[0].PublicKey(), sig), ErrDuplicateApproval)
+
+	// Signatures from keys outside the policy are rejected
+	outsider, err := GenerateEd25519Signer()
+	require.NoError(t, err)
+	outsiderSigner, err := NewMemorySigner(outsider)
+	require.NoError(t, err)
+	sig, err = outsiderSigner.Sign(context.Background(), coordinator.Digest())
+	require.NoError(t, err)
+	assert.ErrorIs(t, coordinator.AddApproval(AlgorithmEd25519, outsiderSigner.PublicKey(), sig), ErrUnknownApprover)
+
+	require.NoError(t, coordinator.Collect(context.Background(), signers[1]))
+	bundle, err := coordinator.Bundle()
+	require.NoError(t, err)
+	assert.Len(t, bundle.Approvals, 2)
+
+	req.Approval = bundle
+	req.Signature = "0xsigned"
+	assert.NoError(t, VerifyApprovalBundle(policy, req, ""))
+
+	// Changing the intent invalidates the approvals
+	req.Amount = "250001"
+	assert.Error(t, VerifyApprovalBundle(policy, req, ""))
+	req.Amount = "250000"
+
+	// A bundle stripped below the threshold fails
+	req.Approval = &types.ApprovalBundle{Digest: bundle.Digest, Threshold: 2, Approvals: bundle.Approvals[:1]}
+	assert.ErrorIs(t, VerifyApprovalBundle(policy, req, ""), ErrThresholdNotMet)
+
+	// Duplicated approvals do not count towards the threshold
+	req.Approval.Approvals = []types.Approval{bundle.Approvals[0], bundle.Approvals[0]}
+	assert.ErrorIs(t, VerifyApprovalBundle(policy, req, ""), ErrThresholdNotMet)
+}
+
+func TestApprovalCoversShieldedAndStealthFields(t *testing.T) {
+	signers, policy := testApprovers(t)
+	newRequest := func() *types.TransactionRequest {
+		return &types.TransactionRequest{
+			ReferenceID:  "treasury-2",
+			Type:         types.IntentTransfer,
+			Amount:       "250000",
+			Asset:        "USDC",
+			SourceChain:  types.ChainBase,
+			IsShielded:   true,
+			Nullifiers:   []string{"0x01", "0x02"},
+			Anchor:       "0xaa",
+			RecipientKey: "0xbb",
+			Memo:         "payroll",
+			Stealth:      &types.StealthPayment{MetaAddress: "st:eth:0xcc", EphemeralPubKey: "0xdd"},
+		}
+	}
+
+	req := newRequest()
+	coordinator, err := NewApprovalCoordinator(policy, req, "")
+	require.NoError(t, err)
+	require.ErrorIs(t, coordinator.Collect(context.Background(), signers[0]), ErrThresholdNotMet)
+	require.NoError(t, coordinator.Collect(context.Background(), signers[1]))
+	bundle, err := coordinator.Bundle()
+	require.NoError(t, err)
+	req.Approval = bundle
+	require.NoError(t, VerifyApprovalBundle(policy, req, ""))
+
+	for name, tamper := range map[string]func(*types.TransactionRequest){
+		"nullifiers":            func(r *types.TransactionRequest) { r.Nullifiers = r.Nullifiers[:1] },
+		"anchor":                func(r *types.TransactionRequest) { r.Anchor = "0xab" },
+		"recipient key":         func(r *types.TransactionRequest) { r.RecipientKey = "0xbc" },
+		"memo":                  func(r *types.TransactionRequest) { r.Memo = "bonus" },
+		"stealth meta-address":  func(r *types.TransactionRequest) { r.Stealth.MetaAddress = "st:eth:0xcd" },
+		"stealth ephemeral key": func(r *types.TransactionRequest) { r.Stealth.EphemeralPubKey = "0xde" },
+		"stealth removed":       func(r *types.TransactionRequest) { r.Stealth = nil },
+	} {
+		tampered := newRequest()
+		tamper(tampered)
+		tampered.Approval = bundle
+		assert.Error(t, VerifyApprovalBundle(policy, tampered, ""), name)
+	}
+}
+
+func TestApprovalPolicyValidate(t *testing.T) {
+	_, policy := testApprovers(t)
+	assert.NoError(t, policy.Validate())
+
+	policy.Threshold = 4
+	assert.Error(t, policy.Validate())
+
+	policy.Threshold = 2
+	policy.Approvers = append(policy.Approvers, policy.Approvers[0])
+	assert.Error(t, policy.Validate())
+}
@@ -0,0 +1,185 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+)
+
+// affinePoint is a point on a short Weierstrass curve; nil coordinates denote infinity
+type affinePoint struct {
+	x, y *big.Int
+}
+
+// weierstrass holds the parameters of y² = x³ + ax + b needed for public-key recovery.
+// crypto/elliptic only exposes b and assumes a = -3, which does not hold for secp256k1.
+type weierstrass struct {
+	p, n, a, b *big.Int
+	g          affinePoint
+}
+
+func curveArithmetic(curve elliptic.Curve) (*weierstrass, error) {
+	params := curve.Params()
+	w := &weierstrass{
+		p: params.P,
+		n: params.N,
+		b: params.B,
+		g: affinePoint{params.Gx, params.Gy},
+	}
+	switch params.Name {
+	case "P-224", "P-256", "P-384", "P-521":
+		w.a = new(big.Int).Sub(params.P, big.NewInt(3))
+	case "secp256k1":
+		w.a = new(big.Int)
+	default:
+		return nil, fmt.Errorf("public key recovery not supported for curve %s", params.Name)
+	}
+	return w, nil
+}
+
+func (w *weierstrass) add(p1, p2 affinePoint) affinePoint {
+	if p1.x == nil {
+		return p2
+	}
+	if p2.x == nil {
+		return p1
+	}
+	if p1.x.Cmp(p2.x) == 0 {
+		if p1.y.Cmp(p2.y) == 0 && p1.y.Sign() != 0 {
+			return w.double(p1)
+		}
+		return affinePoint{}
+	}
+	// λ = (y2 - y1) / (x2 - x1)
+	num := new(big.Int).Sub(p2.y, p1.y)
+	den := new(big.Int).Sub(p2.x, p1.x)
+	den.ModInverse(den.Mod(den, w.p), w.p)
+	lambda := num.Mul(num, den)
+	lambda.Mod(lambda, w.p)
+	return w.finish(lambda, p1, p2.x)
+}
+
+func (w *weierstrass) double(pt affinePoint) affinePoint {
+	if pt.x == nil || pt.y.Sign() == 0 {
+		return affinePoint{}
+	}
+	// λ = (3x² + a) / 2y
+	num := new(big.Int).Mul(pt.x, pt.x)
+	num.Mul(num, big.NewInt(3))
+	num.Add(num, w.a)
+	den := new(big.Int).Lsh(pt.y, 1)
+	den.ModInverse(den.Mod(den, w.p), w.p)
+	lambda := num.Mul(num, den)
+	lambda.Mod(lambda, w.p)
+	return w.finish(lambda, pt, pt.x)
+}
+
+// finish computes x3 = λ² - x1 - x2 and y3 = λ(x1 - x3) - y1
+func (w *weierstrass) finish(lambda *big.Int, p1 affinePoint, x2 *big.Int) affinePoint {
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, p1.x)
+	x3.Sub(x3, x2)
+	x3.Mod(x3, w.p)
+	y3 := new(big.Int).Sub(p1.x, x3)
+	y3.Mul(y3, lambda)
+	y3.Sub(y3, p1.y)
+	y3.Mod(y3, w.p)
+	return affinePoint{x3, y3}
+}
+
+// scalarMult is a variable-time double-and-add; it only ever handles public values
+func (w *weierstrass) scalarMult(pt affinePoint, k *big.Int) affinePoint {
+	var result affinePoint
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		result = w.double(result)
+		if k.Bit(i) == 1 {
+			result = w.add(result, pt)
+		}
+	}
+	return result
+}
+
+// liftX returns the curve point with the given x coordinate and y parity
+func (w *weierstrass) liftX(x *big.Int, odd bool) (affinePoint, error) {
+	if x.Cmp(w.p) >= 0 {
+		return affinePoint{}, fmt.Errorf("%w: x coordinate out of range", ErrInvalidSignature)
+	}
+	rhs := new(big.Int).Mul(x, x)
+	rhs.Mul(rhs, x)
+	ax := new(big.Int).Mul(w.a, x)
+	rhs.Add(rhs, ax)
+	rhs.Add(rhs, w.b)
+	rhs.Mod(rhs, w.p)
+	y := new(big.Int).ModSqrt(rhs, w.p)
+	if y == nil {
+		return affinePoint{}, fmt.Errorf("%w: r is not a valid x coordinate", ErrInvalidSignature)
+	}
+	if (y.Bit(0) == 1) != odd {
+		y.Sub(w.p, y)
+	}
+	return affinePoint{x, y}, nil
+}
+
+// hashToInt converts a digest to an integer the same way crypto/ecdsa does
+func hashToInt(hash []byte, n *big.Int) *big.Int {
+	orderBytes := (n.BitLen() + 7) / 8
+	if len(hash) > orderBytes {
+		hash = hash[:orderBytes]
+	}
+	e := new(big.Int).SetBytes(hash)
+	if excess := len(hash)*8 - n.BitLen(); excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
+
+// RecoverPublicKey recovers the signing public key from a digest and a recoverable signature
+func RecoverPublicKey(curve elliptic.Curve, hash []byte, sig *Signature) (*ecdsa.PublicKey, error) {
+	if err := sig.validate(curve); err != nil {
+		return nil, err
+	}
+	if sig.V > 1 {
+		return nil, fmt.Errorf("%w: invalid recovery id %d", ErrInvalidSignature, sig.V)
+	}
+	w, err := curveArithmetic(curve)
+	if err != nil {
+		return nil, err
+	}
+
+	point, err := w.liftX(sig.R, sig.V == 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Q = r⁻¹(sR - eG)
+	rInv := new(big.Int).ModInverse(sig.R, w.n)
+	e := hashToInt(hash, w.n)
+	negE := new(big.Int).Neg(e)
+	negE.Mod(negE, w.n)
+	u1 := new(big.Int).Mul(negE, rInv)
+	u1.Mod(u1, w.n)
+	u2 := new(big.Int).Mul(sig.S, rInv)
+	u2.Mod(u2, w.n)
+
+	q := w.add(w.scalarMult(w.g, u1), w.scalarMult(point, u2))
+	if q.x == nil {
+		return nil, fmt.Errorf("%w: recovered point at infinity", ErrInvalidSignature)
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: q.x, Y: q.y}, nil
+}
+
+// recoveryID finds the recovery ID that yields pub for the given signature
+func recoveryID(pub *ecdsa.PublicKey, hash []byte, sig *Signature) (byte, error) {
+	for v := byte(0); v <= 1; v++ {
+		candidate := &Signature{R: sig.R, S: sig.S, V: v}
+		recovered, err := RecoverPublicKey(pub.Curve, hash, candidate)
+		if err != nil {
+			continue
+		}
+		if recovered.X.Cmp(pub.X) == 0 && recovered.Y.Cmp(pub.Y) == 0 {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unable to compute recovery id", ErrInvalidSignature)
+}
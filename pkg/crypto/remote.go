@@ -0,0 +1,241 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RemoteSignerConfig configures a RemoteSigner
+type RemoteSignerConfig struct {
+	Endpoint   string // base URL of the signer service, e.g. https://signer.internal
+	KeyID      string
+	Token      string // sent as a bearer token when set
+	Timeout    time.Duration
+	HTTPClient *http.Client
+}
+
+// remoteKeyResponse is returned by GET /v1/keys/{id}
+type remoteKeyResponse struct {
+	Algorithm Algorithm `json:"algorithm"`
+	PublicKey string    `json:"public_key"`
+}
+
+// remoteSignRequest is sent to POST /v1/keys/{id}/sign
+type remoteSignRequest struct {
+	Digest string `json:"digest"`
+}
+
+// remoteSignResponse is returned by POST /v1/keys/{id}/sign
+type remoteSignResponse struct {
+	Signature string `json:"signature"`
+}
+
+// remoteErrorResponse is returned with any non-2xx status
+type remoteErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// RemoteSigner delegates signing to an external signer service (KMS, HSM gateway, MPC node).
+// Every returned signature is verified against the key's public key before it is used.
+type RemoteSigner struct {
+	endpoint  string
+	keyID     string
+	token     string
+	client    *http.Client
+	algorithm Algorithm
+	publicKey []byte
+}
+
+// NewRemoteSigner connects to a signer service and fetches the key's public key
+func NewRemoteSigner(ctx context.Context, cfg RemoteSignerConfig) (*RemoteSigner, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("signer endpoint is required")
+	}
+	if cfg.KeyID == "" {
+		return nil, fmt.Errorf("signer key id is required")
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		timeout := cfg.Timeout
+		if timeout == 0 {
+			timeout = 10 * time.Second
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+
+	s := &RemoteSigner{
+		endpoint: strings.TrimRight(cfg.Endpoint, "/"),
+		keyID:    cfg.KeyID,
+		token:    cfg.Token,
+		client:   client,
+	}
+
+	var key remoteKeyResponse
+	if err := s.do(ctx, http.MethodGet, s.keyURL(), nil, &key); err != nil {
+		return nil, fmt.Errorf("failed to fetch public key: %w", err)
+	}
+	pubKey, err := DecodeHex(key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key from signer: %w", err)
+	}
+	s.algorithm = key.Algorithm
+	s.publicKey = pubKey
+	return s, nil
+}
+
+// keyURL escapes the key ID so IDs with slashes or query characters name a single key
+func (s *RemoteSigner) keyURL() string {
+	return s.endpoint + "/v1/keys/" + url.PathEscape(s.keyID)
+}
+
+// Algorithm returns the signature scheme of the remote key
+func (s *RemoteSigner) Algorithm() Algorithm {
+	return s.algorithm
+}
+
+// PublicKey returns the serialized public key
+func (s *RemoteSigner) PublicKey() []byte {
+	return append([]byte(nil), s.publicKey...)
+}
+
+// Sign asks the signer service to sign the digest
+func (s *RemoteSigner) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	req := remoteSignRequest{Digest: "0x" + hex.EncodeToString(digest)}
+	var resp remoteSignResponse
+	if err := s.do(ctx, http.MethodPost, s.keyURL()+"/sign", req, &resp); err != nil {
+		return nil, fmt.Errorf("remote signing failed: %w", err)
+	}
+
+	sig, err := DecodeHex(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if err := VerifyDigest(s.algorithm, s.publicKey, digest, sig); err != nil {
+		return nil, fmt.Errorf("remote signer returned a bad signature: %w", err)
+	}
+	return sig, nil
+}
+
+// Close releases idle connections to the signer service
+func (s *RemoteSigner) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// do performs a JSON request against the signer service
+func (s *RemoteSigner) do(ctx context.Context, method, url string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr remoteErrorResponse
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("signer returned %d: %s", resp.StatusCode, apiErr.Error)
+		}
+		return fmt.Errorf("signer returned %d", resp.StatusCode)
+	}
+	return json.Unmarshal(data, out)
+}
+
+// NewSignerHandler serves the remote signer protocol for a set of local signers.
+// It is the reference implementation of the protocol and a stand-in for tests and local development.
+func NewSignerHandler(signers map[string]Signer, token string) http.Handler {
+	writeJSON := func(w http.ResponseWriter, status int, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+
+	authorize := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if token != "" {
+				got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+					writeJSON(w, http.StatusUnauthorized, remoteErrorResponse{Error: "unauthorized"})
+					return
+				}
+			}
+			next(w, r)
+		}
+	}
+
+	lookup := func(w http.ResponseWriter, r *http.Request) (Signer, bool) {
+		signer, ok := signers[r.PathValue("id")]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, remoteErrorResponse{Error: "unknown key"})
+		}
+		return signer, ok
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/keys/{id}", authorize(func(w http.ResponseWriter, r *http.Request) {
+		signer, ok := lookup(w, r)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, remoteKeyResponse{
+			Algorithm: signer.Algorithm(),
+			PublicKey: "0x" + hex.EncodeToString(signer.PublicKey()),
+		})
+	}))
+	mux.HandleFunc("POST /v1/keys/{id}/sign", authorize(func(w http.ResponseWriter, r *http.Request) {
+		signer, ok := lookup(w, r)
+		if !ok {
+			return
+		}
+		var req remoteSignRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, remoteErrorResponse{Error: "malformed request"})
+			return
+		}
+		digest, err := DecodeHex(req.Digest)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, remoteErrorResponse{Error: err.Error()})
+			return
+		}
+		sig, err := signer.Sign(r.Context(), digest)
+		if err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, remoteErrorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, remoteSignResponse{Signature: "0x" + hex.EncodeToString(sig)})
+	}))
+	return mux
+}
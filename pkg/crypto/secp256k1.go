@@ -0,0 +1,215 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secpecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// personalMessagePrefix is prepended to messages signed with personal_sign (EIP-191)
+const personalMessagePrefix = "\x19Ethereum Signed Message:\n"
+
+// Secp256k1Signer signs Ethereum-compatible digests with a secp256k1 key.
+// Signatures are deterministic (RFC 6979), low-S and encoded as 65-byte r||s||v.
+type Secp256k1Signer struct {
+	privateKey *secp256k1.PrivateKey
+}
+
+// NewSecp256k1Signer creates a signer from a secp256k1 private key
+func NewSecp256k1Signer(privKey *secp256k1.PrivateKey) *Secp256k1Signer {
+	return &Secp256k1Signer{
+		privateKey: privKey,
+	}
+}
+
+// GenerateSecp256k1Signer creates a signer with a freshly generated key
+func GenerateSecp256k1Signer() (*Secp256k1Signer, error) {
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("key generation failed: %w", err)
+	}
+	return NewSecp256k1Signer(key), nil
+}
+
+// Secp256k1SignerFromBytes creates a signer from a 32-byte private key
+func Secp256k1SignerFromBytes(privKey []byte) (*Secp256k1Signer, error) {
+	if len(privKey) != 32 {
+		return nil, fmt.Errorf("invalid private key length: %d", len(privKey))
+	}
+	var scalar secp256k1.ModNScalar
+	if overflow := scalar.SetByteSlice(privKey); overflow || scalar.IsZero() {
+		return nil, fmt.Errorf("private key out of range")
+	}
+	return NewSecp256k1Signer(secp256k1.NewPrivateKey(&scalar)), nil
+}
+
+// Secp256k1SignerFromHex creates a signer from a hex-encoded private key (0x prefix optional)
+func Secp256k1SignerFromHex(privKey string) (*Secp256k1Signer, error) {
+	b, err := DecodeHex(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return Secp256k1SignerFromBytes(b)
+}
+
+// PrivateKeyBytes returns the 32-byte private key
+func (s *Secp256k1Signer) PrivateKeyBytes() []byte {
+	return s.privateKey.Serialize()
+}
+
+// PublicKey returns the signer's public key
+func (s *Secp256k1Signer) PublicKey() *ecdsa.PublicKey {
+	return s.privateKey.PubKey().ToECDSA()
+}
+
+// Address returns the EIP-55 checksummed Ethereum address of the signer
+func (s *Secp256k1Signer) Address() string {
+	return PubkeyToAddress(s.PublicKey())
+}
+
+// SignHash signs a 32-byte digest as-is (raw-hash mode) and returns r||s||v with v in {0, 1}
+func (s *Secp256k1Signer) SignHash(hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash must be 32 bytes, got %d", len(hash))
+	}
+
+	compact := secpecdsa.SignCompact(s.privateKey, hash, false)
+	v := compact[0] - 27
+	if v > 1 {
+		// The overflow recovery codes cannot be expressed in Ethereum's v
+		return nil, fmt.Errorf("signing failed: unsupported recovery code %d", v)
+	}
+
+	sig := make([]byte, 65)
+	copy(sig, compact[1:])
+	sig[64] = v
+	return sig, nil
+}
+
+// SignMessage hashes data with keccak-256 and returns a 0x-hex r||s||v signature
+func (s *Secp256k1Signer) SignMessage(data []byte) (string, error) {
+	sig, err := s.SignHash(Keccak256(data))
+	if err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(sig), nil
+}
+
+// PersonalSign signs data using the EIP-191 personal_sign scheme with v in {27, 28},
+// matching the output of eth_sign / personal_sign in wallets
+func (s *Secp256k1Signer) PersonalSign(data []byte) (string, error) {
+	sig, err := s.SignHash(PersonalMessageHash(data))
+	if err != nil {
+		return "", err
+	}
+	sig[64] += 27
+	return "0x" + hex.EncodeToString(sig), nil
+}
+
+// SignTypedData signs an EIP-712 payload with v in {27, 28}
+func (s *Secp256k1Signer) SignTypedData(td *TypedData) (string, error) {
+	hash, err := td.SigningHash()
+	if err != nil {
+		return "", err
+	}
+	sig, err := s.SignHash(hash)
+	if err != nil {
+		return "", err
+	}
+	sig[64] += 27
+	return "0x" + hex.EncodeToString(sig), nil
+}
+
+// PersonalMessageHash returns keccak256("\x19Ethereum Signed Message:\n" || len(data) || data)
+func PersonalMessageHash(data []byte) []byte {
+	prefix := personalMessagePrefix + strconv.Itoa(len(data))
+	return Keccak256([]byte(prefix), data)
+}
+
+// RecoverSecp256k1 recovers the public key that produced a 65-byte r||s||v signature over hash.
+// v may be either 0/1 or 27/28.
+func RecoverSecp256k1(hash, sig []byte) (*ecdsa.PublicKey, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash must be 32 bytes, got %d", len(hash))
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("%w: expected 65 bytes, got %d", ErrInvalidSignature, len(sig))
+	}
+
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return nil, fmt.Errorf("%w: invalid recovery id %d", ErrInvalidSignature, sig[64])
+	}
+
+	compact := make([]byte, 65)
+	compact[0] = 27 + v
+	copy(compact[1:], sig[:64])
+
+	pub, _, err := secpecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return pub.ToECDSA(), nil
+}
+
+// RecoverAddress recovers the Ethereum address that produced a signature over hash
+func RecoverAddress(hash []byte, signature string) (string, error) {
+	sig, err := DecodeHex(signature)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	pub, err := RecoverSecp256k1(hash, sig)
+	if err != nil {
+		return "", err
+	}
+	return PubkeyToAddress(pub), nil
+}
+
+// VerifyPersonalSignature checks that a personal_sign signature over data was produced by address
+func VerifyPersonalSignature(address string, data []byte, signature string) error {
+	recovered, err := RecoverAddress(PersonalMessageHash(data), signature)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(recovered, address) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// PubkeyToAddress derives the EIP-55 checksummed Ethereum address of a secp256k1 public key
+func PubkeyToAddress(pub *ecdsa.PublicKey) string {
+	uncompressed := make([]byte, 64)
+	pub.X.FillBytes(uncompressed[:32])
+	pub.Y.FillBytes(uncompressed[32:])
+	return ChecksumAddress(Keccak256(uncompressed)[12:])
+}
+
+// ChecksumAddress encodes a 20-byte address with EIP-55 mixed-case checksum
+func ChecksumAddress(addr []byte) string {
+	lower := hex.EncodeToString(addr)
+	hash := Keccak256([]byte(lower))
+
+	out := []byte(lower)
+	for i, c := range out {
+		if c < 'a' {
+			continue
+		}
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= 8 {
+			out[i] = c - 32
+		}
+	}
+	return "0x" + string(out)
+}
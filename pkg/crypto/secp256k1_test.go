@@ -0,0 +1,98 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksumAddress(t *testing.T) {
+	// Test vectors from EIP-55
+	addresses := []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	}
+	for _, addr := range addresses {
+		raw, err := DecodeHex(strings.ToLower(addr))
+		require.NoError(t, err)
+		assert.Equal(t, addr, ChecksumAddress(raw))
+	}
+}
+
+func TestSecp256k1Address(t *testing.T) {
+	key := make([]byte, 32)
+	key[31] = 1
+	signer, err := Secp256k1SignerFromBytes(key)
+	require.NoError(t, err)
+	assert.Equal(t, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", signer.Address())
+
+	_, err = Secp256k1SignerFromBytes(make([]byte, 32))
+	assert.Error(t, err)
+	_, err = Secp256k1SignerFromHex("0x1234")
+	assert.Error(t, err)
+}
+
+func TestSecp256k1SignTypedDataVector(t *testing.T) {
+	// The EIP-712 reference signature of the Mail example by keccak256("cow")
+	signer, err := Secp256k1SignerFromBytes(Keccak256([]byte("cow")))
+	require.NoError(t, err)
+	assert.Equal(t, "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826", signer.Address())
+
+	sig, err := signer.SignTypedData(mailTypedData())
+	require.NoError(t, err)
+	assert.Equal(t, "0x"+
+		"4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d"+
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562"+
+		"1c", sig)
+}
+
+func TestSecp256k1PersonalSignRecover(t *testing.T) {
+	signer, err := GenerateSecp256k1Signer()
+	require.NoError(t, err)
+
+	msg := []byte("approve payroll batch 42")
+	sig, err := signer.PersonalSign(msg)
+	require.NoError(t, err)
+
+	raw, err := DecodeHex(sig)
+	require.NoError(t, err)
+	assert.Contains(t, []byte{27, 28}, raw[64])
+
+	assert.NoError(t, VerifyPersonalSignature(signer.Address(), msg, sig))
+	assert.ErrorIs(t, VerifyPersonalSignature(signer.Address(), []byte("other"), sig), ErrSignatureMismatch)
+	assert.Error(t, VerifyPersonalSignature(signer.Address(), msg, "0x1234"))
+}
+
+func TestSecp256k1RawHashRecover(t *testing.T) {
+	signer, err := GenerateSecp256k1Signer()
+	require.NoError(t, err)
+
+	hash := Keccak256([]byte("raw"))
+	sig, err := signer.SignHash(hash)
+	require.NoError(t, err)
+	assert.Len(t, sig, 65)
+
+	pub, err := RecoverSecp256k1(hash, sig)
+	require.NoError(t, err)
+	assert.True(t, pub.Equal(signer.PublicKey()))
+
+	// The generic recovery path agrees with the native one
+	parsed, err := ParseSignatureFormat(secp256k1.S256(), sig, FormatRecoverable)
+	require.NoError(t, err)
+	generic, err := RecoverPublicKey(secp256k1.S256(), hash, parsed)
+	require.NoError(t, err)
+	assert.True(t, generic.Equal(signer.PublicKey()))
+
+	_, err = signer.SignHash([]byte("short"))
+	assert.Error(t, err)
+
+	msgSig, err := signer.SignMessage([]byte("raw"))
+	require.NoError(t, err)
+	assert.Equal(t, "0x"+hex.EncodeToString(sig), msgSig)
+}
@@ -0,0 +1,189 @@
+package crypto
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// SignatureFormat selects how an ECDSA signature is serialized
+type SignatureFormat string
+
+const (
+	// FormatRS is the fixed-width r||s encoding, each half padded to the curve size
+	FormatRS SignatureFormat = "rs"
+	// FormatDER is the ASN.1 DER SEQUENCE { r INTEGER, s INTEGER } encoding
+	FormatDER SignatureFormat = "der"
+	// FormatRecoverable is the fixed-width r||s||v encoding (65 bytes on 256-bit curves)
+	FormatRecoverable SignatureFormat = "recoverable"
+)
+
+var (
+	ErrInvalidSignature  = errors.New("invalid signature")
+	ErrSignatureMismatch = errors.New("signature does not match public key")
+)
+
+// Signature is a parsed ECDSA signature.
+// V is the public-key recovery ID (0 or 1) and is only set for recoverable signatures.
+type Signature struct {
+	R *big.Int
+	S *big.Int
+	V byte
+}
+
+// curveByteSize returns the byte length of a scalar on the curve
+func curveByteSize(curve elliptic.Curve) int {
+	return (curve.Params().N.BitLen() + 7) / 8
+}
+
+// Encode serializes the signature in the requested format
+func (sig *Signature) Encode(curve elliptic.Curve, format SignatureFormat) ([]byte, error) {
+	switch format {
+	case FormatRS:
+		return sig.rs(curve), nil
+	case FormatDER:
+		return asn1.Marshal(struct{ R, S *big.Int }{sig.R, sig.S})
+	case FormatRecoverable:
+		if sig.V > 1 {
+			return nil, fmt.Errorf("invalid recovery id: %d", sig.V)
+		}
+		return append(sig.rs(curve), sig.V), nil
+	default:
+		return nil, fmt.Errorf("unsupported signature format: %s", format)
+	}
+}
+
+// rs returns the fixed-width r||s encoding
+func (sig *Signature) rs(curve elliptic.Curve) []byte {
+	size := curveByteSize(curve)
+	out := make([]byte, 2*size)
+	sig.R.FillBytes(out[:size])
+	sig.S.FillBytes(out[size:])
+	return out
+}
+
+// IsLowS reports whether s is in the lower half of the curve order
+func (sig *Signature) IsLowS(curve elliptic.Curve) bool {
+	halfN := new(big.Int).Rsh(curve.Params().N, 1)
+	return sig.S.Cmp(halfN) <= 0
+}
+
+// NormalizeS replaces s with n-s when s is in the upper half of the curve order.
+// Both values verify, so normalizing removes signature malleability.
+// The recovery ID is flipped accordingly.
+func (sig *Signature) NormalizeS(curve elliptic.Curve) {
+	if sig.IsLowS(curve) {
+		return
+	}
+	sig.S = new(big.Int).Sub(curve.Params().N, sig.S)
+	sig.V ^= 1
+}
+
+// validate checks that r and s are within [1, n-1]
+func (sig *Signature) validate(curve elliptic.Curve) error {
+	n := curve.Params().N
+	if sig.R == nil || sig.S == nil {
+		return fmt.Errorf("%w: missing r or s", ErrInvalidSignature)
+	}
+	if sig.R.Sign() <= 0 || sig.R.Cmp(n) >= 0 {
+		return fmt.Errorf("%w: r out of range", ErrInvalidSignature)
+	}
+	if sig.S.Sign() <= 0 || sig.S.Cmp(n) >= 0 {
+		return fmt.Errorf("%w: s out of range", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// ParseSignature decodes a signature, detecting the format from its length:
+// 2*size bytes is r||s, 2*size+1 bytes is r||s||v, anything else is parsed as DER.
+func ParseSignature(curve elliptic.Curve, data []byte) (*Signature, error) {
+	size := curveByteSize(curve)
+	switch len(data) {
+	case 2 * size:
+		return parseRS(curve, data)
+	case 2*size + 1:
+		return parseRecoverable(curve, data)
+	default:
+		return parseDER(curve, data)
+	}
+}
+
+// ParseSignatureFormat decodes a signature in an explicit format
+func ParseSignatureFormat(curve elliptic.Curve, data []byte, format SignatureFormat) (*Signature, error) {
+	size := curveByteSize(curve)
+	switch format {
+	case FormatRS:
+		if len(data) != 2*size {
+			return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidSignature, 2*size, len(data))
+		}
+		return parseRS(curve, data)
+	case FormatRecoverable:
+		if len(data) != 2*size+1 {
+			return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidSignature, 2*size+1, len(data))
+		}
+		return parseRecoverable(curve, data)
+	case FormatDER:
+		return parseDER(curve, data)
+	default:
+		return nil, fmt.Errorf("unsupported signature format: %s", format)
+	}
+}
+
+func parseRS(curve elliptic.Curve, data []byte) (*Signature, error) {
+	size := len(data) / 2
+	sig := &Signature{
+		R: new(big.Int).SetBytes(data[:size]),
+		S: new(big.Int).SetBytes(data[size:]),
+	}
+	if err := sig.validate(curve); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+func parseRecoverable(curve elliptic.Curve, data []byte) (*Signature, error) {
+	sig, err := parseRS(curve, data[:len(data)-1])
+	if err != nil {
+		return nil, err
+	}
+	v := data[len(data)-1]
+	// Accept both raw recovery IDs and Ethereum's 27/28 convention
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return nil, fmt.Errorf("%w: invalid recovery id %d", ErrInvalidSignature, data[len(data)-1])
+	}
+	sig.V = v
+	return sig, nil
+}
+
+func parseDER(curve elliptic.Curve, data []byte) (*Signature, error) {
+	var parsed struct{ R, S *big.Int }
+	rest, err := asn1.Unmarshal(data, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed DER: %v", ErrInvalidSignature, err)
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%w: trailing data after DER signature", ErrInvalidSignature)
+	}
+	sig := &Signature{R: parsed.R, S: parsed.S}
+	if err := sig.validate(curve); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// DecodeHex decodes a hex string with an optional 0x prefix
+func DecodeHex(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,118 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSigner(t *testing.T) *ECDSASigner {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return NewSigner(key)
+}
+
+func TestSignAndVerifyFormats(t *testing.T) {
+	signer := newTestSigner(t)
+	data := []byte("transfer 1000 USDC")
+
+	tests := []struct {
+		format SignatureFormat
+		length int
+	}{
+		{FormatRS, 64},
+		{FormatRecoverable, 65},
+		{FormatDER, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			sig, err := signer.SignMessageWithFormat(data, tt.format)
+			require.NoError(t, err)
+
+			raw, err := DecodeHex(sig)
+			require.NoError(t, err)
+			if tt.length > 0 {
+				assert.Len(t, raw, tt.length)
+			}
+
+			assert.NoError(t, VerifyMessage(signer.PublicKey(), data, sig))
+			// The 0x prefix is optional
+			assert.NoError(t, VerifyMessage(signer.PublicKey(), data, sig[2:]))
+			assert.ErrorIs(t, VerifyMessage(signer.PublicKey(), []byte("tampered"), sig), ErrSignatureMismatch)
+		})
+	}
+}
+
+func TestSignatureFixedWidthPadding(t *testing.T) {
+	curve := elliptic.P256()
+	sig := &Signature{R: big.NewInt(1), S: big.NewInt(2)}
+
+	encoded, err := sig.Encode(curve, FormatRS)
+	require.NoError(t, err)
+	assert.Len(t, encoded, 64)
+
+	parsed, err := ParseSignature(curve, encoded)
+	require.NoError(t, err)
+	assert.Equal(t, 0, parsed.R.Cmp(big.NewInt(1)))
+	assert.Equal(t, 0, parsed.S.Cmp(big.NewInt(2)))
+}
+
+func TestSignatureLowS(t *testing.T) {
+	signer := newTestSigner(t)
+	hash := sha256.Sum256([]byte("payload"))
+
+	for i := 0; i < 20; i++ {
+		sig, err := signer.SignHash(hash[:], false)
+		require.NoError(t, err)
+		assert.True(t, sig.IsLowS(elliptic.P256()))
+	}
+
+	n := elliptic.P256().Params().N
+	high := &Signature{R: big.NewInt(5), S: new(big.Int).Sub(n, big.NewInt(1))}
+	high.NormalizeS(elliptic.P256())
+	assert.Equal(t, 0, high.S.Cmp(big.NewInt(1)))
+	assert.Equal(t, byte(1), high.V)
+}
+
+func TestRecoverPublicKey(t *testing.T) {
+	signer := newTestSigner(t)
+	hash := sha256.Sum256([]byte("recover me"))
+
+	sig, err := signer.SignHash(hash[:], true)
+	require.NoError(t, err)
+
+	pub, err := RecoverPublicKey(elliptic.P256(), hash[:], sig)
+	require.NoError(t, err)
+	assert.True(t, pub.Equal(signer.PublicKey()))
+}
+
+func TestVerifyMessageMalformed(t *testing.T) {
+	signer := newTestSigner(t)
+	pub := signer.PublicKey()
+	data := []byte("data")
+
+	inputs := []string{
+		"",
+		"0x",
+		"zz",
+		"0x00",
+		"0x" + hex.EncodeToString(make([]byte, 64)),
+		"0x" + hex.EncodeToString(append(make([]byte, 64), 7)),
+	}
+	for _, input := range inputs {
+		assert.NotPanics(t, func() {
+			assert.Error(t, VerifyMessage(pub, data, input))
+			assert.False(t, VerifySignature(pub, data, input))
+		})
+	}
+
+	assert.Error(t, VerifyMessage(nil, data, "0x00"))
+}
@@ -6,48 +6,103 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"math/big"
 )
 
-// Signer handles cryptographic signing operations for transactions
-type Signer struct {
+// MessageSigner is implemented by every key type that can authorize intents
+type MessageSigner interface {
+	// SignMessage signs data and returns the 0x-prefixed hex signature
+	SignMessage(data []byte) (string, error)
+}
+
+var (
+	_ MessageSigner = (*ECDSASigner)(nil)
+	_ MessageSigner = (*Secp256k1Signer)(nil)
+	_ MessageSigner = (*Ed25519Signer)(nil)
+)
+
+// ECDSASigner handles cryptographic signing operations for transactions
+type ECDSASigner struct {
 	privateKey *ecdsa.PrivateKey
 }
 
 // NewSigner creates a new signer with a given private key
-func NewSigner(privKey *ecdsa.PrivateKey) *Signer {
-	return &Signer{
+func NewSigner(privKey *ecdsa.PrivateKey) *ECDSASigner {
+	return &ECDSASigner{
 		privateKey: privKey,
 	}
 }
 
-// SignMessage signs arbitrary data and returns hex-encoded signature
-func (s *Signer) SignMessage(data []byte) (string, error) {
+// SignMessage signs arbitrary data and returns a hex-encoded fixed-width r||s signature
+func (s *ECDSASigner) SignMessage(data []byte) (string, error) {
+	return s.SignMessageWithFormat(data, FormatRS)
+}
+
+// SignMessageWithFormat signs arbitrary data and returns the hex-encoded signature in the given format
+func (s *ECDSASigner) SignMessageWithFormat(data []byte, format SignatureFormat) (string, error) {
 	hash := sha256.Sum256(data)
 
-	r, sig, err := ecdsa.Sign(rand.Reader, s.privateKey, hash[:])
+	sig, err := s.SignHash(hash[:], format == FormatRecoverable)
 	if err != nil {
-		return "", fmt.Errorf("signing failed: %w", err)
+		return "", err
 	}
 
-	// Encode signature as hex
-	signature := append(r.Bytes(), sig.Bytes()...)
-	return "0x" + hex.EncodeToString(signature), nil
+	encoded, err := sig.Encode(s.privateKey.Curve, format)
+	if err != nil {
+		return "", fmt.Errorf("encoding signature failed: %w", err)
+	}
+	return "0x" + hex.EncodeToString(encoded), nil
 }
 
-// VerifySignature verifies a signature against public key
-func VerifySignature(pubKey *ecdsa.PublicKey, data []byte, signature string) bool {
-	hash := sha256.Sum256(data)
+// SignHash signs a precomputed digest and returns a low-S signature.
+// The recovery ID is only computed when withRecoveryID is set, as it costs a key recovery.
+func (s *ECDSASigner) SignHash(hash []byte, withRecoveryID bool) (*Signature, error) {
+	r, sVal, err := ecdsa.Sign(rand.Reader, s.privateKey, hash)
+	if err != nil {
+		return nil, fmt.Errorf("signing failed: %w", err)
+	}
+
+	sig := &Signature{R: r, S: sVal}
+	if withRecoveryID {
+		v, err := recoveryID(&s.privateKey.PublicKey, hash, sig)
+		if err != nil {
+			return nil, err
+		}
+		sig.V = v
+	}
+	sig.NormalizeS(s.privateKey.Curve)
+	return sig, nil
+}
+
+// PublicKey returns the signer's public key
+func (s *ECDSASigner) PublicKey() *ecdsa.PublicKey {
+	return &s.privateKey.PublicKey
+}
+
+// VerifyMessage verifies a hex-encoded signature (r||s, r||s||v or DER) over data.
+// The 0x prefix is optional. A non-nil error describes why verification failed.
+func VerifyMessage(pubKey *ecdsa.PublicKey, data []byte, signature string) error {
+	if pubKey == nil {
+		return fmt.Errorf("public key is required")
+	}
+
+	sigBytes, err := DecodeHex(signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
 
-	// Decode hex signature
-	sigBytes, err := hex.DecodeString(signature[2:]) // Remove 0x prefix
+	sig, err := ParseSignature(pubKey.Curve, sigBytes)
 	if err != nil {
-		return false
+		return err
 	}
 
-	// Split into r and s
-	r := new(big.Int).SetBytes(sigBytes[:len(sigBytes)/2])
-	s := new(big.Int).SetBytes(sigBytes[len(sigBytes)/2:])
+	hash := sha256.Sum256(data)
+	if !ecdsa.Verify(pubKey, hash[:], sig.R, sig.S) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
 
-	return ecdsa.Verify(pubKey, hash[:], r, s)
+// VerifySignature verifies a signature against public key
+func VerifySignature(pubKey *ecdsa.PublicKey, data []byte, signature string) bool {
+	return VerifyMessage(pubKey, data, signature) == nil
 }
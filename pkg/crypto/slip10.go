@@ -0,0 +1,75 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+)
+
+// Ed25519ExtendedKey is a SLIP-10 Ed25519 extended private key. Ed25519 supports
+// hardened derivation only, so every path segment is hardened.
+type Ed25519ExtendedKey struct {
+	key       []byte
+	chainCode []byte
+	depth     uint8
+}
+
+// NewEd25519MasterKey derives the SLIP-10 Ed25519 master key from a seed
+func NewEd25519MasterKey(seed []byte) (*Ed25519ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length: %d", len(seed))
+	}
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	return &Ed25519ExtendedKey{key: sum[:32], chainCode: sum[32:]}, nil
+}
+
+// Child derives the hardened child at index; non-hardened indices are hardened implicitly
+func (k *Ed25519ExtendedKey) Child(index uint32) *Ed25519ExtendedKey {
+	data := make([]byte, 0, 37)
+	data = append(data, 0x00)
+	data = append(data, k.key...)
+	data = binary.BigEndian.AppendUint32(data, index|HardenedOffset)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	zeroBytes(data)
+
+	return &Ed25519ExtendedKey{key: sum[:32], chainCode: sum[32:], depth: k.depth + 1}
+}
+
+// DerivePath derives a descendant from a path such as "m/44'/501'/0'/0'".
+// Every segment must be hardened.
+func (k *Ed25519ExtendedKey) DerivePath(path string) (*Ed25519ExtendedKey, error) {
+	indices, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, index := range indices {
+		if index < HardenedOffset {
+			return nil, fmt.Errorf("ed25519 only supports hardened derivation: %s", path)
+		}
+		key = key.Child(index)
+	}
+	return key, nil
+}
+
+// PrivateKey returns the 32-byte Ed25519 seed of the key
+func (k *Ed25519ExtendedKey) PrivateKey() []byte {
+	return append([]byte(nil), k.key...)
+}
+
+// ChainCode returns the 32-byte chain code
+func (k *Ed25519ExtendedKey) ChainCode() []byte {
+	return append([]byte(nil), k.chainCode...)
+}
+
+// Signer returns an Ed25519 signer for the key
+func (k *Ed25519ExtendedKey) Signer() *Ed25519Signer {
+	return NewEd25519Signer(ed25519.NewKeyFromSeed(k.key))
+}
@@ -0,0 +1,212 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/mr-tron/base58"
+)
+
+// solanaOffchainDomain prefixes off-chain messages so they can never be valid transactions
+const solanaOffchainDomain = "\xffsolana offchain"
+
+// SolanaMessageFormat is the off-chain message format byte
+type SolanaMessageFormat byte
+
+const (
+	SolanaRestrictedASCII SolanaMessageFormat = 0
+	SolanaLimitedUTF8     SolanaMessageFormat = 1
+	SolanaExtendedUTF8    SolanaMessageFormat = 2
+)
+
+const (
+	// solanaMaxLedgerMessage is the largest message hardware wallets accept
+	solanaMaxLedgerMessage = 1212
+	// solanaMaxOffchainMessage is the largest message in the v0 format
+	solanaMaxOffchainMessage = 65515
+)
+
+// SolanaOffchainMessage serializes a v0 off-chain message:
+// signing domain || version || format || u16 length (LE) || message
+func SolanaOffchainMessage(message []byte) ([]byte, error) {
+	format, err := solanaMessageFormat(message)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(solanaOffchainDomain)
+	buf.WriteByte(0) // header version
+	buf.WriteByte(byte(format))
+	buf.Write(binary.LittleEndian.AppendUint16(nil, uint16(len(message))))
+	buf.Write(message)
+	return buf.Bytes(), nil
+}
+
+// solanaMessageFormat selects the most restrictive format the message fits in
+func solanaMessageFormat(message []byte) (SolanaMessageFormat, error) {
+	if len(message) == 0 {
+		return 0, fmt.Errorf("message is empty")
+	}
+	if !utf8.Valid(message) {
+		return 0, fmt.Errorf("message is not valid UTF-8")
+	}
+	if len(message) > solanaMaxOffchainMessage {
+		return 0, fmt.Errorf("message too long: %d bytes", len(message))
+	}
+	if len(message) > solanaMaxLedgerMessage {
+		return SolanaExtendedUTF8, nil
+	}
+	for _, c := range message {
+		if c < 0x20 || c > 0x7e {
+			return SolanaLimitedUTF8, nil
+		}
+	}
+	return SolanaRestrictedASCII, nil
+}
+
+// SignSolanaMessage signs an off-chain message and returns the base58 signature
+func (s *Ed25519Signer) SignSolanaMessage(message []byte) (string, error) {
+	serialized, err := SolanaOffchainMessage(message)
+	if err != nil {
+		return "", err
+	}
+	return base58.Encode(s.Sign(serialized)), nil
+}
+
+// VerifySolanaMessage verifies a base58 off-chain message signature from a Solana address
+func VerifySolanaMessage(address string, message []byte, signature string) error {
+	pubKey, err := ParseSolanaAddress(address)
+	if err != nil {
+		return err
+	}
+	serialized, err := SolanaOffchainMessage(message)
+	if err != nil {
+		return err
+	}
+	return VerifyEd25519(pubKey, serialized, signature)
+}
+
+// solanaTransaction is a wire-format transaction split into its signature slots and message
+type solanaTransaction struct {
+	signatures [][]byte
+	message    []byte
+	// signers are the account keys required to sign, in signature slot order
+	signers []ed25519.PublicKey
+}
+
+// decodeCompactU16 reads Solana's variable-length u16 (1-3 bytes, 7 bits per byte)
+func decodeCompactU16(data []byte) (int, int, error) {
+	value := 0
+	for i := 0; i < 3; i++ {
+		if i >= len(data) {
+			return 0, 0, fmt.Errorf("truncated compact-u16")
+		}
+		b := data[i]
+		value |= int(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return value, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("compact-u16 overflow")
+}
+
+// parseSolanaTransaction decodes the signature slots and required signers of a transaction.
+// Both legacy and versioned (v0) messages are supported.
+func parseSolanaTransaction(tx []byte) (*solanaTransaction, error) {
+	count, n, err := decodeCompactU16(tx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature count: %w", err)
+	}
+	offset := n
+	if len(tx) < offset+count*ed25519.SignatureSize {
+		return nil, fmt.Errorf("transaction truncated in signatures")
+	}
+
+	parsed := &solanaTransaction{}
+	for i := 0; i < count; i++ {
+		parsed.signatures = append(parsed.signatures, tx[offset:offset+ed25519.SignatureSize])
+		offset += ed25519.SignatureSize
+	}
+	parsed.message = tx[offset:]
+
+	msg := parsed.message
+	if len(msg) > 0 && msg[0]&0x80 != 0 {
+		if version := msg[0] & 0x7f; version != 0 {
+			return nil, fmt.Errorf("unsupported message version: %d", version)
+		}
+		msg = msg[1:]
+	}
+	if len(msg) < 3 {
+		return nil, fmt.Errorf("message header truncated")
+	}
+	required := int(msg[0])
+	if required != count {
+		return nil, fmt.Errorf("message requires %d signatures, transaction has %d slots", required, count)
+	}
+
+	keyCount, n, err := decodeCompactU16(msg[3:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid account key count: %w", err)
+	}
+	keys := msg[3+n:]
+	if keyCount < required || len(keys) < keyCount*ed25519.PublicKeySize {
+		return nil, fmt.Errorf("message truncated in account keys")
+	}
+	for i := 0; i < required; i++ {
+		parsed.signers = append(parsed.signers, ed25519.PublicKey(keys[i*ed25519.PublicKeySize:(i+1)*ed25519.PublicKeySize]))
+	}
+	return parsed, nil
+}
+
+// SolanaTransactionMessage returns the serialized message of a wire-format transaction,
+// which is the payload every required signer signs
+func SolanaTransactionMessage(tx []byte) ([]byte, error) {
+	parsed, err := parseSolanaTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.message, nil
+}
+
+// SignSolanaTransaction signs a wire-format transaction, placing each signature in the
+// slot of the matching required signer. The input is not modified.
+func SignSolanaTransaction(tx []byte, signers ...*Ed25519Signer) ([]byte, error) {
+	out := append([]byte(nil), tx...)
+	parsed, err := parseSolanaTransaction(out)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, signer := range signers {
+		slot := -1
+		for i, key := range parsed.signers {
+			if key.Equal(signer.PublicKey()) {
+				slot = i
+				break
+			}
+		}
+		if slot < 0 {
+			return nil, fmt.Errorf("%s is not a required signer of this transaction", signer.Address())
+		}
+		copy(parsed.signatures[slot], signer.Sign(parsed.message))
+	}
+	return out, nil
+}
+
+// VerifySolanaTransaction checks every signature slot of a wire-format transaction
+func VerifySolanaTransaction(tx []byte) error {
+	parsed, err := parseSolanaTransaction(tx)
+	if err != nil {
+		return err
+	}
+	for i, key := range parsed.signers {
+		if !ed25519.Verify(key, parsed.message, parsed.signatures[i]) {
+			return fmt.Errorf("%w: signer %s", ErrSignatureMismatch, base58.Encode(key))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/useeasycash/ecash-sdk-core/pkg/types"
+)
+
+// stealthMetaAddressPrefix marks an ERC-5564 secp256k1 stealth meta-address
+const stealthMetaAddressPrefix = "st:eth:0x"
+
+// ErrNotStealthRecipient is returned when an announcement is for another meta-address
+var ErrNotStealthRecipient = errors.New("announcement is not addressed to these stealth keys")
+
+// StealthMetaAddress is the long-lived public identity a recipient publishes. Senders
+// derive a fresh one-time address from it for every payment (ERC-5564, scheme 1).
+type StealthMetaAddress struct {
+	SpendingPubKey *secp256k1.PublicKey
+	ViewingPubKey  *secp256k1.PublicKey
+}
+
+// ParseStealthMetaAddress decodes "st:eth:0x<spending pubkey><viewing pubkey>" with
+// 33-byte compressed keys
+func ParseStealthMetaAddress(s string) (*StealthMetaAddress, error) {
+	if !strings.HasPrefix(s, stealthMetaAddressPrefix) {
+		return nil, fmt.Errorf("invalid stealth meta-address prefix: %s", s)
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(s, stealthMetaAddressPrefix))
+	if err != nil || len(raw) != 66 {
+		return nil, fmt.Errorf("invalid stealth meta-address: %s", s)
+	}
+	spending, err := secp256k1.ParsePubKey(raw[:33])
+	if err != nil {
+		return nil, fmt.Errorf("invalid stealth spending key: %w", err)
+	}
+	viewing, err := secp256k1.ParsePubKey(raw[33:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid stealth viewing key: %w", err)
+	}
+	return &StealthMetaAddress{SpendingPubKey: spending, ViewingPubKey: viewing}, nil
+}
+
+// String encodes the meta-address in its published form
+func (m *StealthMetaAddress) String() string {
+	return stealthMetaAddressPrefix +
+		hex.EncodeToString(m.SpendingPubKey.SerializeCompressed()) +
+		hex.EncodeToString(m.ViewingPubKey.SerializeCompressed())
+}
+
+// StealthAnnouncement is what a sender publishes alongside a stealth payment so the
+// recipient can find it: the one-time address, the ephemeral public key and a view tag
+// that lets scanners skip most foreign announcements after one hash
+type StealthAnnouncement struct {
+	Address         string
+	EphemeralPubKey []byte // 33-byte compressed
+	ViewTag         byte
+}
+
+// StealthAnnouncementFromRequest extracts the announcement of a stealth payment
+// request whose one-time recipient has been derived
+func StealthAnnouncementFromRequest(req *types.TransactionRequest) (*StealthAnnouncement, error) {
+	if req.Stealth == nil || req.Stealth.EphemeralPubKey == "" {
+		return nil, fmt.Errorf("request is not a derived stealth payment")
+	}
+	ephemeral, err := DecodeHex(req.Stealth.EphemeralPubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &StealthAnnouncement{Address: req.Recipient, EphemeralPubKey: ephemeral, ViewTag: req.Stealth.ViewTag}, nil
+}
+
+// GenerateStealthAddress derives a fresh one-time address for a meta-address
+func GenerateStealthAddress(meta *StealthMetaAddress) (*StealthAnnouncement, error) {
+	ephemeral, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("ephemeral key generation failed: %w", err)
+	}
+	defer ephemeral.Zero()
+
+	secret := stealthSecret(&ephemeral.Key, meta.ViewingPubKey)
+	var hashed secp256k1.ModNScalar
+	hashed.SetByteSlice(secret)
+
+	return &StealthAnnouncement{
+		Address:         stealthAddress(meta.SpendingPubKey, &hashed),
+		EphemeralPubKey: ephemeral.PubKey().SerializeCompressed(),
+		ViewTag:         secret[0],
+	}, nil
+}
+
+// stealthSecret returns keccak256 of the compressed ECDH point k*P
+func stealthSecret(k *secp256k1.ModNScalar, pub *secp256k1.PublicKey) []byte {
+	var p, shared secp256k1.JacobianPoint
+	pub.AsJacobian(&p)
+	secp256k1.ScalarMultNonConst(k, &p, &shared)
+	shared.ToAffine()
+	return Keccak256(secp256k1.NewPublicKey(&shared.X, &shared.Y).SerializeCompressed())
+}
+
+// stealthAddress returns the address of the one-time key P = S + hashed*G
+func stealthAddress(spending *secp256k1.PublicKey, hashed *secp256k1.ModNScalar) string {
+	var s, tweak, result secp256k1.JacobianPoint
+	spending.AsJacobian(&s)
+	secp256k1.ScalarBaseMultNonConst(hashed, &tweak)
+	secp256k1.AddNonConst(&s, &tweak, &result)
+	result.ToAffine()
+	return PubkeyToAddress(secp256k1.NewPublicKey(&result.X, &result.Y).ToECDSA())
+}
+
+// StealthKeys are a recipient's stealth spending and viewing keys. The viewing key
+// alone is enough to scan for payments; spending them also needs the spending key.
+type StealthKeys struct {
+	spending *secp256k1.PrivateKey
+	viewing  *secp256k1.PrivateKey
+}
+
+// GenerateStealthKeys creates a fresh spending and viewing key pair
+func GenerateStealthKeys() (*StealthKeys, error) {
+	spending, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("key generation failed: %w", err)
+	}
+	viewing, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("key generation failed: %w", err)
+	}
+	return &StealthKeys{spending: spending, viewing: viewing}, nil
+}
+
+// StealthKeysFromSigners builds stealth keys from two secp256k1 signers, e.g. keys
+// derived from an HD wallet
+func StealthKeysFromSigners(spending, viewing *Secp256k1Signer) *StealthKeys {
+	return &StealthKeys{spending: spending.privateKey, viewing: viewing.privateKey}
+}
+
+// MetaAddress returns the meta-address to publish
+func (k *StealthKeys) MetaAddress() *StealthMetaAddress {
+	return &StealthMetaAddress{SpendingPubKey: k.spending.PubKey(), ViewingPubKey: k.viewing.PubKey()}
+}
+
+// match checks an announcement and returns its hashed shared secret
+func (k *StealthKeys) match(ann *StealthAnnouncement) (*secp256k1.ModNScalar, error) {
+	ephemeral, err := secp256k1.ParsePubKey(ann.EphemeralPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral public key: %w", err)
+	}
+	secret := stealthSecret(&k.viewing.Key, ephemeral)
+	if secret[0] != ann.ViewTag {
+		return nil, ErrNotStealthRecipient
+	}
+	var hashed secp256k1.ModNScalar
+	hashed.SetByteSlice(secret)
+
+	if !strings.EqualFold(stealthAddress(k.spending.PubKey(), &hashed), ann.Address) {
+		return nil, ErrNotStealthRecipient
+	}
+	return &hashed, nil
+}
+
+// Scan returns the announcements addressed to these keys
+func (k *StealthKeys) Scan(announcements []StealthAnnouncement) []StealthAnnouncement {
+	var found []StealthAnnouncement
+	for i := range announcements {
+		if _, err := k.match(&announcements[i]); err == nil {
+			found = append(found, announcements[i])
+		}
+	}
+	return found
+}
+
+// Signer returns the signer controlling the one-time address of an announcement
+func (k *StealthKeys) Signer(ann *StealthAnnouncement) (*Secp256k1Signer, error) {
+	hashed, err := k.match(ann)
+	if err != nil {
+		return nil, err
+	}
+	// p = s + hash(v*R)
+	var key secp256k1.ModNScalar
+	key.Set(&k.spending.Key).Add(hashed)
+	if key.IsZero() {
+		return nil, fmt.Errorf("derived stealth key is invalid")
+	}
+	return NewSecp256k1Signer(secp256k1.NewPrivateKey(&key)), nil
+}
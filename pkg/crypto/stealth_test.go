@@ -0,0 +1,74 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/useeasycash/ecash-sdk-core/pkg/types"
+)
+
+func TestStealthAddressRoundTrip(t *testing.T) {
+	recipient, err := GenerateStealthKeys()
+	require.NoError(t, err)
+	other, err := GenerateStealthKeys()
+	require.NoError(t, err)
+
+	meta, err := ParseStealthMetaAddress(recipient.MetaAddress().String())
+	require.NoError(t, err)
+	assert.Equal(t, recipient.MetaAddress().String(), meta.String())
+
+	// Every payment gets a different one-time address
+	first, err := GenerateStealthAddress(meta)
+	require.NoError(t, err)
+	second, err := GenerateStealthAddress(meta)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Address, second.Address)
+	assert.Regexp(t, `^0x[0-9a-fA-F]{40}$`, first.Address)
+
+	foreign, err := GenerateStealthAddress(other.MetaAddress())
+	require.NoError(t, err)
+
+	found := recipient.Scan([]StealthAnnouncement{*first, *foreign, *second})
+	require.Len(t, found, 2)
+	assert.Equal(t, first.Address, found[0].Address)
+	assert.Equal(t, second.Address, found[1].Address)
+
+	// The derived signer controls the one-time address
+	signer, err := recipient.Signer(first)
+	require.NoError(t, err)
+	assert.Equal(t, first.Address, signer.Address())
+
+	_, err = other.Signer(first)
+	assert.ErrorIs(t, err, ErrNotStealthRecipient)
+}
+
+func TestStealthAnnouncementFromRequest(t *testing.T) {
+	recipient, err := GenerateStealthKeys()
+	require.NoError(t, err)
+	ann, err := GenerateStealthAddress(recipient.MetaAddress())
+	require.NoError(t, err)
+
+	req := &types.TransactionRequest{
+		Recipient: ann.Address,
+		Stealth: &types.StealthPayment{
+			MetaAddress:     recipient.MetaAddress().String(),
+			EphemeralPubKey: "0x" + hex.EncodeToString(ann.EphemeralPubKey),
+			ViewTag:         ann.ViewTag,
+		},
+	}
+	parsed, err := StealthAnnouncementFromRequest(req)
+	require.NoError(t, err)
+	assert.Len(t, recipient.Scan([]StealthAnnouncement{*parsed}), 1)
+
+	_, err = StealthAnnouncementFromRequest(&types.TransactionRequest{})
+	assert.Error(t, err)
+}
+
+func TestParseStealthMetaAddressErrors(t *testing.T) {
+	for _, s := range []string{"", "st:eth:0x1234", "0x" + string(make([]byte, 132)), "st:sol:0x00"} {
+		_, err := ParseStealthMetaAddress(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,192 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	web3Version    = 3
+	web3Cipher     = "aes-128-ctr"
+	web3KDFPBKDF2  = "pbkdf2"
+	web3PBKDF2PRF  = "hmac-sha256"
+	web3MaxPBKDF2C = 10_000_000
+)
+
+// web3Key is a Web3 Secret Storage Definition v3 key file, as written by geth and most wallets
+type web3Key struct {
+	Version int        `json:"version"`
+	ID      string     `json:"id"`
+	Address string     `json:"address,omitempty"`
+	Crypto  web3Crypto `json:"crypto"`
+}
+
+type web3Crypto struct {
+	Cipher       string           `json:"cipher"`
+	CipherText   string           `json:"ciphertext"`
+	CipherParams web3CipherParams `json:"cipherparams"`
+	KDF          string           `json:"kdf"`
+	KDFParams    web3KDFParams    `json:"kdfparams"`
+	MAC          string           `json:"mac"`
+}
+
+type web3CipherParams struct {
+	IV string `json:"iv"`
+}
+
+// web3KDFParams holds the union of the scrypt and pbkdf2 parameters
+type web3KDFParams struct {
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+	N     int    `json:"n,omitempty"`
+	R     int    `json:"r,omitempty"`
+	P     int    `json:"p,omitempty"`
+	C     int    `json:"c,omitempty"`
+	PRF   string `json:"prf,omitempty"`
+}
+
+// DecryptWeb3Key decrypts a Web3 Secret Storage v3 key file and returns the secp256k1 signer
+func DecryptWeb3Key(data []byte, passphrase string) (*Secp256k1Signer, error) {
+	var key web3Key
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("failed to parse key file: %w", err)
+	}
+	if key.Version != web3Version {
+		return nil, fmt.Errorf("unsupported key file version: %d", key.Version)
+	}
+	if key.Crypto.Cipher != web3Cipher {
+		return nil, fmt.Errorf("unsupported cipher: %s", key.Crypto.Cipher)
+	}
+
+	params := key.Crypto.KDFParams
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	if params.DKLen < 32 || params.DKLen > 64 {
+		return nil, fmt.Errorf("invalid dklen: %d", params.DKLen)
+	}
+
+	var derived []byte
+	switch key.Crypto.KDF {
+	case kdfScrypt:
+		derived, err = ScryptParams{N: params.N, R: params.R, P: params.P}.deriveKey([]byte(passphrase), salt, params.DKLen)
+		if err != nil {
+			return nil, err
+		}
+	case web3KDFPBKDF2:
+		if params.PRF != web3PBKDF2PRF {
+			return nil, fmt.Errorf("unsupported prf: %s", params.PRF)
+		}
+		if params.C <= 0 || params.C > web3MaxPBKDF2C {
+			return nil, fmt.Errorf("invalid pbkdf2 iteration count: %d", params.C)
+		}
+		derived, err = pbkdf2.Key(sha256.New, passphrase, salt, params.C, params.DKLen)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kdf: %s", key.Crypto.KDF)
+	}
+	defer zeroBytes(derived)
+
+	ciphertext, err := hex.DecodeString(key.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	mac, err := hex.DecodeString(key.Crypto.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mac: %w", err)
+	}
+	if !hmac.Equal(Keccak256(derived[16:32], ciphertext), mac) {
+		return nil, ErrWrongPassphrase
+	}
+
+	iv, err := hex.DecodeString(key.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, fmt.Errorf("invalid iv: %w", err)
+	}
+	privKey, err := aesCTR(derived[:16], iv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(privKey)
+
+	signer, err := Secp256k1SignerFromBytes(privKey)
+	if err != nil {
+		return nil, err
+	}
+	if key.Address != "" && !strings.EqualFold(strings.TrimPrefix(key.Address, "0x"), strings.TrimPrefix(signer.Address(), "0x")) {
+		return nil, fmt.Errorf("key file address does not match private key")
+	}
+	return signer, nil
+}
+
+// EncryptWeb3Key encrypts a secp256k1 key as a Web3 Secret Storage v3 key file
+func EncryptWeb3Key(signer *Secp256k1Signer, passphrase string, params ScryptParams) ([]byte, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	derived, err := params.deriveKey([]byte(passphrase), salt, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(derived)
+
+	privKey := signer.PrivateKeyBytes()
+	defer zeroBytes(privKey)
+	ciphertext, err := aesCTR(derived[:16], iv, privKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key := web3Key{
+		Version: web3Version,
+		ID:      uuid.New().String(),
+		Address: strings.ToLower(strings.TrimPrefix(signer.Address(), "0x")),
+		Crypto: web3Crypto{
+			Cipher:       web3Cipher,
+			CipherText:   hex.EncodeToString(ciphertext),
+			CipherParams: web3CipherParams{IV: hex.EncodeToString(iv)},
+			KDF:          kdfScrypt,
+			KDFParams: web3KDFParams{
+				DKLen: 32,
+				Salt:  hex.EncodeToString(salt),
+				N:     params.N,
+				R:     params.R,
+				P:     params.P,
+			},
+			MAC: hex.EncodeToString(Keccak256(derived[16:32], ciphertext)),
+		},
+	}
+	return json.MarshalIndent(key, "", "  ")
+}
+
+func aesCTR(key, iv, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid iv length: %d", len(iv))
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
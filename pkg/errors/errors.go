@@ -12,6 +12,10 @@ const (
 	ErrProofGeneration   ErrorCode = "PROOF_GENERATION_FAILED"
 	ErrAgentUnavailable  ErrorCode = "AGENT_UNAVAILABLE"
 	ErrTimeout           ErrorCode = "TIMEOUT"
+	ErrSigningFailed     ErrorCode = "SIGNING_FAILED"
+	ErrApprovalRequired  ErrorCode = "APPROVAL_REQUIRED"
+	ErrDoubleSpend       ErrorCode = "DOUBLE_SPEND"
+	ErrStorageFailure    ErrorCode = "STORAGE_FAILURE"
 )
 
 // SDKError is a structured error type for better error handling
@@ -13,6 +13,14 @@ type Metrics struct {
 	FailedTransactions     int64
 	TotalFeePaid           float64
 	AverageLatency         time.Duration
+
+	// Proof generation
+	ProofsGenerated    int64
+	ProofFailures      int64
+	ProofCacheHits     int64
+	ProofQueueDepth    int64
+	MaxProofQueueDepth int64
+	AverageProvingTime time.Duration
 }
 
 var globalMetrics = &Metrics{}
@@ -39,6 +47,38 @@ func (m *Metrics) RecordTransaction(success bool, fee float64, latency time.Dura
 	m.AverageLatency = (m.AverageLatency + latency) / 2
 }
 
+// RecordProof records a finished call to the prover and its proving time
+func (m *Metrics) RecordProof(success bool, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if success {
+		m.ProofsGenerated++
+	} else {
+		m.ProofFailures++
+	}
+	m.AverageProvingTime = (m.AverageProvingTime + duration) / 2
+}
+
+// RecordProofCacheHit records a proof job served from the proof cache without proving
+func (m *Metrics) RecordProofCacheHit() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ProofCacheHits++
+}
+
+// RecordProofQueueDepth records the number of proof jobs waiting for a prover
+func (m *Metrics) RecordProofQueueDepth(depth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ProofQueueDepth = int64(depth)
+	if m.ProofQueueDepth > m.MaxProofQueueDepth {
+		m.MaxProofQueueDepth = m.ProofQueueDepth
+	}
+}
+
 // GetStats returns current statistics
 func (m *Metrics) GetStats() map[string]interface{} {
 	m.mu.RLock()
@@ -51,6 +91,12 @@ func (m *Metrics) GetStats() map[string]interface{} {
 		"total_fee_paid":          m.TotalFeePaid,
 		"average_latency_ms":      m.AverageLatency.Milliseconds(),
 		"success_rate":            float64(m.SuccessfulTransactions) / float64(m.TotalTransactions),
+		"proofs_generated":        m.ProofsGenerated,
+		"proof_failures":          m.ProofFailures,
+		"proof_cache_hits":        m.ProofCacheHits,
+		"proof_queue_depth":       m.ProofQueueDepth,
+		"max_proof_queue_depth":   m.MaxProofQueueDepth,
+		"average_proving_time_ms": m.AverageProvingTime.Milliseconds(),
 	}
 }
 
@@ -64,4 +110,10 @@ func (m *Metrics) Reset() {
 	m.FailedTransactions = 0
 	m.TotalFeePaid = 0
 	m.AverageLatency = 0
+	m.ProofsGenerated = 0
+	m.ProofFailures = 0
+	m.ProofCacheHits = 0
+	m.ProofQueueDepth = 0
+	m.MaxProofQueueDepth = 0
+	m.AverageProvingTime = 0
 }
@@ -29,8 +29,23 @@ type TransactionRequest struct {
 	Recipient   string     `json:"recipient,omitempty"`
 	SourceChain ChainID    `json:"source_chain"`
 	TargetChain ChainID    `json:"target_chain,omitempty"`
+	// Stealth pays a stealth meta-address; the client derives a one-time Recipient
+	Stealth *StealthPayment `json:"stealth,omitempty"`
 	// Privacy options
 	IsShielded bool `json:"is_shielded"`
+	// Nullifiers of the notes a shielded intent spends, and the note tree root (anchor)
+	// they were proven against; an empty anchor means the current root
+	Nullifiers []string `json:"nullifiers,omitempty"`
+	Anchor     string   `json:"anchor,omitempty"`
+	// RecipientKey is the recipient's shielded receiving address; when set the output
+	// note is encrypted to it together with Memo
+	RecipientKey string `json:"recipient_key,omitempty"`
+	Memo         string `json:"memo,omitempty"`
+	// Signature authorizes the intent; when empty the client signs its own copy of the
+	// request with the configured signer
+	Signature string `json:"signature,omitempty"`
+	// Approval carries the M-of-N approvals required for transfers above the approval limit
+	Approval *ApprovalBundle `json:"approval,omitempty"`
 }
 
 // Validation methods
@@ -44,10 +59,41 @@ func (r *TransactionRequest) Validate() error {
 	return nil
 }
 
+// StealthPayment addresses a payment to a stealth meta-address. EphemeralPubKey and
+// ViewTag are set in the response once the one-time recipient is derived and must be
+// published so the recipient can find the payment.
+type StealthPayment struct {
+	MetaAddress     string `json:"meta_address"`
+	EphemeralPubKey string `json:"ephemeral_pub_key,omitempty"` // hex encoded
+	ViewTag         uint8  `json:"view_tag"`
+}
+
+// Approval is a single approver's signature over the intent digest
+type Approval struct {
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"` // hex encoded
+	Signature string `json:"signature"`  // hex encoded
+}
+
+// ApprovalBundle aggregates the approvals collected for one intent
+type ApprovalBundle struct {
+	Digest    string     `json:"digest"` // hex encoded intent digest the approvals sign
+	Threshold int        `json:"threshold"`
+	Approvals []Approval `json:"approvals"`
+}
+
 // TransactionResponse is the result of an intent execution
 type TransactionResponse struct {
 	TxHash      string `json:"tx_hash"`
 	Status      string `json:"status"`
 	BlockHeight uint64 `json:"block_height"`
 	FeeUsed     string `json:"fee_used"`
+	// EncryptedNote is the output note encrypted to the request's RecipientKey
+	EncryptedNote string `json:"encrypted_note,omitempty"`
+	// Proof is the hex-encoded proof envelope of a shielded spend
+	Proof string `json:"proof,omitempty"`
+	// Recipient and Stealth are the one-time address and announcement derived for a
+	// stealth payment
+	Recipient string          `json:"recipient,omitempty"`
+	Stealth   *StealthPayment `json:"stealth,omitempty"`
 }
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 
+	"github.com/useeasycash/ecash-sdk-core/pkg/crypto"
 	"github.com/useeasycash/ecash-sdk-core/pkg/types"
 )
 
@@ -40,6 +41,37 @@ func ValidateAmount(amount string) error {
 	return nil
 }
 
+// ValidateStealthPayment checks the meta-address of a stealth payment. Stealth
+// addresses are secp256k1 EVM addresses, so both chains must be EVM chains. Before the
+// one-time address is derived the request must not name a recipient; afterwards it
+// must carry both the recipient and the ephemeral key.
+func ValidateStealthPayment(req *types.TransactionRequest) error {
+	for _, chain := range []types.ChainID{req.SourceChain, req.TargetChain} {
+		if chain == "" {
+			continue
+		}
+		if _, err := crypto.EVMChainID(chain); err != nil {
+			return fmt.Errorf("stealth payments require EVM chains: %w", err)
+		}
+	}
+	if _, err := crypto.ParseStealthMetaAddress(req.Stealth.MetaAddress); err != nil {
+		return err
+	}
+	if req.Stealth.EphemeralPubKey == "" {
+		if req.Recipient != "" {
+			return fmt.Errorf("recipient is derived from the stealth meta-address and must be empty")
+		}
+		return nil
+	}
+	if req.Recipient == "" {
+		return fmt.Errorf("stealth ephemeral key given without a recipient")
+	}
+	if key, err := crypto.DecodeHex(req.Stealth.EphemeralPubKey); err != nil || len(key) != 33 {
+		return fmt.Errorf("invalid stealth ephemeral key")
+	}
+	return nil
+}
+
 // ValidateChain checks if a chain ID is supported
 func ValidateChain(chain types.ChainID) error {
 	validChains := map[types.ChainID]bool{
@@ -77,5 +109,11 @@ func ValidateTransactionRequest(req *types.TransactionRequest) error {
 		}
 	}
 
+	if req.Stealth != nil {
+		if err := ValidateStealthPayment(req); err != nil {
+			return fmt.Errorf("stealth validation failed: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -1,9 +1,12 @@
 package validator
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/useeasycash/ecash-sdk-core/pkg/crypto"
 	"github.com/useeasycash/ecash-sdk-core/pkg/types"
 )
 
@@ -61,3 +64,35 @@ func TestValidateChain(t *testing.T) {
 	assert.NoError(t, ValidateChain(types.ChainEthereum))
 	assert.Error(t, ValidateChain(types.ChainID("invalid")))
 }
+
+func TestValidateStealthPayment(t *testing.T) {
+	keys, err := crypto.GenerateStealthKeys()
+	require.NoError(t, err)
+	meta := keys.MetaAddress().String()
+
+	req := &types.TransactionRequest{Amount: "10", Asset: "USDC", SourceChain: types.ChainBase,
+		Stealth: &types.StealthPayment{MetaAddress: meta}}
+	assert.NoError(t, ValidateTransactionRequest(req))
+
+	// A visible recipient cannot be combined with an underived stealth payment
+	req.Recipient = "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+	assert.Error(t, ValidateTransactionRequest(req))
+
+	req.Stealth.EphemeralPubKey = "0x" + strings.Repeat("02", 33)
+	assert.NoError(t, ValidateTransactionRequest(req))
+	req.Stealth.EphemeralPubKey = "0x02"
+	assert.Error(t, ValidateTransactionRequest(req))
+
+	req.Stealth = &types.StealthPayment{MetaAddress: "st:eth:0x1234"}
+	req.Recipient = ""
+	assert.Error(t, ValidateTransactionRequest(req))
+
+	// Stealth addresses only exist on EVM chains
+	req.Stealth = &types.StealthPayment{MetaAddress: meta}
+	req.SourceChain = types.ChainSolana
+	assert.Error(t, ValidateTransactionRequest(req))
+	req.SourceChain, req.TargetChain = types.ChainBase, types.ChainSolana
+	assert.Error(t, ValidateTransactionRequest(req))
+	req.TargetChain = types.ChainEthereum
+	assert.NoError(t, ValidateTransactionRequest(req))
+}
@@ -0,0 +1,36 @@
+package zk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AmountDecimals is the fixed-point precision of committed amounts: "1.5" is committed
+// as 1_500_000 base units
+const AmountDecimals = 6
+
+// ParseAmount converts a non-negative decimal amount string into base units
+func ParseAmount(amount string) (uint64, error) {
+	whole, frac, _ := strings.Cut(amount, ".")
+	if whole == "" || len(frac) > AmountDecimals || strings.ContainsAny(whole+frac, "+-") {
+		return 0, fmt.Errorf("invalid amount: %q", amount)
+	}
+	frac += strings.Repeat("0", AmountDecimals-len(frac))
+
+	units, err := strconv.ParseUint(whole+frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", amount, err)
+	}
+	return units, nil
+}
+
+// FormatAmount converts base units back into a decimal amount string
+func FormatAmount(units uint64) string {
+	s := fmt.Sprintf("%0*d", AmountDecimals+1, units)
+	whole, frac := s[:len(s)-AmountDecimals], strings.TrimRight(s[len(s)-AmountDecimals:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
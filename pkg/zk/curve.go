@@ -0,0 +1,180 @@
+package zk
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Commitments and proofs work over the secp256k1 group. Points are kept in affine
+// form (Z = 1) after every operation so they can be compared and encoded directly.
+
+type (
+	scalar = secp256k1.ModNScalar
+	point  = secp256k1.JacobianPoint
+)
+
+// pointSize is the length of a compressed point encoding
+const pointSize = 33
+
+// hashToPoint maps a label to a curve point with an unknown discrete logarithm
+// using try-and-increment, so generators are verifiably nothing-up-my-sleeve
+func hashToPoint(label string) point {
+	var buf [4]byte
+	for counter := uint32(0); ; counter++ {
+		binary.BigEndian.PutUint32(buf[:], counter)
+		h := sha256.New()
+		h.Write([]byte(label))
+		h.Write(buf[:])
+
+		var x, y secp256k1.FieldVal
+		if overflow := x.SetByteSlice(h.Sum(nil)); overflow {
+			continue
+		}
+		if !secp256k1.DecompressY(&x, false, &y) {
+			continue
+		}
+		var p point
+		p.X.Set(&x)
+		p.Y.Set(&y)
+		p.Z.SetInt(1)
+		return p
+	}
+}
+
+// baseMul returns k*G
+func baseMul(k *scalar) point {
+	var result point
+	secp256k1.ScalarBaseMultNonConst(k, &result)
+	result.ToAffine()
+	return result
+}
+
+// mul returns k*p
+func mul(k *scalar, p *point) point {
+	var result point
+	secp256k1.ScalarMultNonConst(k, p, &result)
+	result.ToAffine()
+	return result
+}
+
+// add returns a+b
+func add(a, b *point) point {
+	var result point
+	secp256k1.AddNonConst(a, b, &result)
+	result.ToAffine()
+	return result
+}
+
+// neg returns -p
+func neg(p *point) point {
+	var result point
+	result.Set(p)
+	result.Y.Negate(1).Normalize()
+	return result
+}
+
+// sub returns a-b
+func sub(a, b *point) point {
+	negB := neg(b)
+	return add(a, &negB)
+}
+
+// multiScalarMul returns sum(scalars[i] * points[i])
+func multiScalarMul(scalars []scalar, points []point) point {
+	var acc point
+	for i := range scalars {
+		if scalars[i].IsZero() {
+			continue
+		}
+		var term, next point
+		secp256k1.ScalarMultNonConst(&scalars[i], &points[i], &term)
+		secp256k1.AddNonConst(&acc, &term, &next)
+		acc = next
+	}
+	acc.ToAffine()
+	return acc
+}
+
+func isIdentity(p *point) bool {
+	return (p.X.IsZero() && p.Y.IsZero()) || p.Z.IsZero()
+}
+
+func pointsEqual(a, b *point) bool {
+	if isIdentity(a) || isIdentity(b) {
+		return isIdentity(a) && isIdentity(b)
+	}
+	return a.X.Equals(&b.X) && a.Y.Equals(&b.Y)
+}
+
+// encodePoint returns the 33-byte compressed encoding; the identity encodes as all zeros
+func encodePoint(p *point) []byte {
+	if isIdentity(p) {
+		return make([]byte, pointSize)
+	}
+	return secp256k1.NewPublicKey(&p.X, &p.Y).SerializeCompressed()
+}
+
+// decodePoint parses an encoding produced by encodePoint
+func decodePoint(b []byte) (point, error) {
+	var p point
+	if len(b) != pointSize {
+		return p, fmt.Errorf("invalid point length: %d", len(b))
+	}
+	if isZero(b) {
+		return p, nil
+	}
+	key, err := secp256k1.ParsePubKey(b)
+	if err != nil {
+		return p, fmt.Errorf("invalid point: %w", err)
+	}
+	key.AsJacobian(&p)
+	return p, nil
+}
+
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// scalarFromUint64 returns v as a scalar
+func scalarFromUint64(v uint64) scalar {
+	var buf [32]byte
+	binary.BigEndian.PutUint64(buf[24:], v)
+	var s scalar
+	s.SetBytes(&buf)
+	return s
+}
+
+// randomScalar returns a uniformly random non-zero scalar
+func randomScalar() (scalar, error) {
+	var s scalar
+	var buf [32]byte
+	for {
+		if _, err := rand.Read(buf[:]); err != nil {
+			return s, fmt.Errorf("failed to read randomness: %w", err)
+		}
+		if overflow := s.SetBytes(&buf); overflow == 0 && !s.IsZero() {
+			return s, nil
+		}
+	}
+}
+
+// decodeScalar parses a canonical 32-byte big-endian scalar
+func decodeScalar(b []byte) (scalar, error) {
+	var s scalar
+	if len(b) != 32 {
+		return s, fmt.Errorf("invalid scalar length: %d", len(b))
+	}
+	if overflow := s.SetByteSlice(b); overflow {
+		return s, fmt.Errorf("scalar out of range")
+	}
+	return s, nil
+}
@@ -0,0 +1,113 @@
+package zk
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidDisclosure is returned when a disclosure does not match the published note
+var ErrInvalidDisclosure = errors.New("invalid disclosure")
+
+// DisclosureKey is the shared secret of a single note. Revealing it lets a third party
+// decrypt that note and nothing else: other notes use other ephemeral keys, and
+// spending needs the nullifier key.
+type DisclosureKey [32]byte
+
+// MarshalText encodes the key as 0x-prefixed hex
+func (k DisclosureKey) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(k[:])), nil
+}
+
+// UnmarshalText decodes a key from 0x-prefixed hex
+func (k *DisclosureKey) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil || len(b) != len(k) {
+		return fmt.Errorf("invalid disclosure key encoding")
+	}
+	copy(k[:], b)
+	return nil
+}
+
+// Disclosure proves the amount and recipient of one shielded output to a third party.
+// Proof shows that Key is the X25519 agreement of the note's ephemeral key and the
+// recipient address, so Key decrypts exactly what was sent to that address; the key
+// commitment in the note rules out a ciphertext that also opens under another key; and
+// the decrypted note must open the published commitment to the stated amount. Notes do
+// not identify their sender, so a disclosure says nothing about who paid.
+type Disclosure struct {
+	Note      *EncryptedNote    `json:"note"`
+	Recipient ReceivingAddress  `json:"recipient"`
+	Asset     string            `json:"asset"`
+	Amount    uint64            `json:"amount"` // base units, see AmountDecimals
+	Key       DisclosureKey     `json:"key"`
+	Proof     SharedSecretProof `json:"proof"`
+}
+
+// Disclose builds a disclosure for a note the account sent or received
+func (vk *ViewingKey) Disclose(enc *EncryptedNote) (*Disclosure, error) {
+	d, err := disclose(enc, vk.Incoming.priv, vk.Address(), false)
+	if errors.Is(err, ErrNotForKey) {
+		var recipient ReceivingAddress
+		var ephemeral *ecdh.PrivateKey
+		if recipient, ephemeral, err = vk.Outgoing.open(enc); err == nil {
+			d, err = disclose(enc, ephemeral, recipient, true)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("note is not in the account's history: %w", err)
+	}
+	return d, nil
+}
+
+// disclose decrypts enc with priv, which is the recipient's receiving key or, for the
+// sender, the note's ephemeral key, and proves the shared secret it agrees
+func disclose(enc *EncryptedNote, priv *ecdh.PrivateKey, recipient ReceivingAddress, sender bool) (*Disclosure, error) {
+	peer := enc.EphemeralKey
+	if sender {
+		peer = recipient
+	}
+	shared, err := agree(priv, peer)
+	if err != nil {
+		return nil, err
+	}
+	note, _, err := openNote(enc, shared, recipient)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := proveSharedSecret(priv, peer[:], shared, sender)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Disclosure{Note: enc, Recipient: recipient, Asset: note.Asset, Amount: note.Amount, Proof: proof}
+	copy(d.Key[:], shared)
+	return d, nil
+}
+
+// VerifyDisclosure checks a disclosure against the note as published on the ledger.
+// Auditors must take published from their own copy of the ledger, not from the discloser.
+func VerifyDisclosure(d *Disclosure, published *EncryptedNote) error {
+	if d.Note == nil || published == nil {
+		return fmt.Errorf("%w: missing note", ErrInvalidDisclosure)
+	}
+	if !bytes.Equal(d.Note.Bytes(), published.Bytes()) {
+		return fmt.Errorf("%w: note differs from the published note", ErrInvalidDisclosure)
+	}
+	if err := d.Proof.verify(published.EphemeralKey[:], d.Recipient[:], d.Key[:]); err != nil {
+		return fmt.Errorf("%w: key is not agreed with the recipient: %v", ErrInvalidDisclosure, err)
+	}
+
+	note, _, err := openNote(published, d.Key[:], d.Recipient)
+	if err != nil {
+		return fmt.Errorf("%w: note does not decrypt for the recipient: %v", ErrInvalidDisclosure, err)
+	}
+	if note.Asset != d.Asset || note.Amount != d.Amount {
+		return fmt.Errorf("%w: note holds %s %s, disclosed %s %s", ErrInvalidDisclosure,
+			FormatAmount(note.Amount), note.Asset, FormatAmount(d.Amount), d.Asset)
+	}
+	return nil
+}
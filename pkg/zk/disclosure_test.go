@@ -0,0 +1,134 @@
+package zk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSpendingKey(t *testing.T) SpendingKey {
+	sk, err := NewSpendingKey()
+	require.NoError(t, err)
+	return sk
+}
+
+// sendTestNote encrypts a note of amount from sender to recipient
+func sendTestNote(t *testing.T, sender, recipient *ViewingKey, amount, leaf uint64) *EncryptedNote {
+	note, err := NewNote("USDC", amount)
+	require.NoError(t, err)
+	note.LeafIndex = leaf
+	enc, err := EncryptNote(recipient.Address(), &sender.Outgoing, note, []byte("payroll"))
+	require.NoError(t, err)
+	return enc
+}
+
+func TestSpendingKeyDerivation(t *testing.T) {
+	sk := newTestSpendingKey(t)
+	assert.Equal(t, sk.ViewingKey().String(), sk.ViewingKey().String())
+	assert.NotEqual(t, NullifierKey(sk), sk.NullifierKey())
+
+	vk, err := ParseViewingKey(sk.ViewingKey().String())
+	require.NoError(t, err)
+	assert.Equal(t, sk.ViewingKey().Address(), vk.Address())
+	assert.Equal(t, sk.ViewingKey().Outgoing, vk.Outgoing)
+
+	_, err = ParseViewingKey("0x1234")
+	assert.Error(t, err)
+}
+
+func TestViewingKeyScan(t *testing.T) {
+	alice, bob, carol := newTestSpendingKey(t).ViewingKey(), newTestSpendingKey(t).ViewingKey(), newTestSpendingKey(t).ViewingKey()
+	published := []*EncryptedNote{
+		sendTestNote(t, alice, bob, 100, 0),
+		sendTestNote(t, bob, alice, 250, 1),
+		sendTestNote(t, carol, bob, 75, 2),
+	}
+
+	// An auditor holding Alice's viewing key sees her sent and received notes only
+	history, err := alice.Scan(published)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	assert.True(t, history[0].Outgoing)
+	assert.Equal(t, bob.Address(), history[0].Counterparty)
+	assert.Equal(t, uint64(100), history[0].Note.Amount)
+	assert.Equal(t, "payroll", string(history[0].Memo))
+
+	assert.False(t, history[1].Outgoing)
+	assert.Equal(t, uint64(250), history[1].Note.Amount)
+}
+
+func TestDisclosure(t *testing.T) {
+	alice, bob := newTestSpendingKey(t).ViewingKey(), newTestSpendingKey(t).ViewingKey()
+	published := sendTestNote(t, alice, bob, 1_000_000, 5)
+
+	// Sender and recipient can both disclose the payment
+	for _, vk := range []*ViewingKey{alice, bob} {
+		d, err := vk.Disclose(published)
+		require.NoError(t, err)
+		assert.Equal(t, bob.Address(), d.Recipient)
+		assert.Equal(t, uint64(1_000_000), d.Amount)
+		require.NoError(t, VerifyDisclosure(d, published))
+	}
+
+	_, err := newTestSpendingKey(t).ViewingKey().Disclose(published)
+	assert.ErrorIs(t, err, ErrNotForKey)
+
+	d, err := alice.Disclose(published)
+	require.NoError(t, err)
+
+	// The disclosure survives a JSON round trip
+	data, err := json.Marshal(d)
+	require.NoError(t, err)
+	var decoded Disclosure
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.NoError(t, VerifyDisclosure(&decoded, published))
+
+	// Claims about a different amount, recipient, key or note are rejected
+	wrongAmount := *d
+	wrongAmount.Amount = 2_000_000
+	assert.ErrorIs(t, VerifyDisclosure(&wrongAmount, published), ErrInvalidDisclosure)
+
+	wrongRecipient := *d
+	wrongRecipient.Recipient = alice.Address()
+	assert.ErrorIs(t, VerifyDisclosure(&wrongRecipient, published), ErrInvalidDisclosure)
+
+	wrongProof := *d
+	wrongProof.Proof[40] ^= 1
+	assert.ErrorIs(t, VerifyDisclosure(&wrongProof, published), ErrInvalidDisclosure)
+
+	wrongKey := *d
+	wrongKey.Key[0] ^= 1
+	assert.ErrorIs(t, VerifyDisclosure(&wrongKey, published), ErrInvalidDisclosure)
+
+	other := sendTestNote(t, alice, bob, 1_000_000, 6)
+	assert.ErrorIs(t, VerifyDisclosure(d, other), ErrInvalidDisclosure)
+}
+
+func TestDisclosureCannotRedirectNote(t *testing.T) {
+	alice, bob, carol := newTestSpendingKey(t).ViewingKey(), newTestSpendingKey(t).ViewingKey(), newTestSpendingKey(t).ViewingKey()
+	published := sendTestNote(t, alice, bob, 1_000_000, 5)
+
+	// The sender holds the ephemeral key and can prove its agreement with any address,
+	// but the note is committed to the key derived for Bob
+	_, ephemeral, err := alice.Outgoing.open(published)
+	require.NoError(t, err)
+	carolAddr := carol.Address()
+	forged := &Disclosure{Note: published, Recipient: carolAddr, Asset: "USDC", Amount: 1_000_000}
+	shared, err := agree(ephemeral, carolAddr)
+	require.NoError(t, err)
+	copy(forged.Key[:], shared)
+	forged.Proof, err = proveSharedSecret(ephemeral, carolAddr[:], shared, true)
+	require.NoError(t, err)
+	require.NoError(t, forged.Proof.verify(published.EphemeralKey[:], carolAddr[:], shared))
+	require.NoError(t, err)
+	assert.ErrorIs(t, VerifyDisclosure(forged, published), ErrInvalidDisclosure)
+
+	// A note whose key commitment does not match is not decrypted at all
+	tampered := *published
+	tampered.KeyCommitment[0] ^= 1
+	_, _, err = bob.Incoming.Decrypt(&tampered)
+	assert.ErrorIs(t, err, ErrNotForKey)
+}
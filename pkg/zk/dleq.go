@@ -0,0 +1,223 @@
+package zk
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+)
+
+// Shared secrets are tied to the keys that agreed them with a Chaum-Pedersen proof of
+// discrete-log equality over edwards25519. X25519 keys and secrets are Montgomery
+// u-coordinates; each is lifted to an Edwards point that must lie in the prime-order
+// subgroup, so a secret offset by a small-order point cannot satisfy the statement
+// meant for the true one. The sign lost with the u-coordinate is carried as a flag.
+
+const (
+	sharedSecretProofSize   = 1 + 32 + 32
+	sharedSecretProofDomain = "EasyCash/SharedSecretProof/v1"
+
+	proofBySender = 1 << 0 // the prover holds the ephemeral key rather than the address key
+	proofNegated  = 1 << 1 // the lifted shared secret is the negation of the agreed point
+)
+
+var (
+	errSharedSecretProof = errors.New("shared secret proof does not verify")
+
+	// cofactorBase generates the prime-order subgroup the proofs work in
+	cofactorBase = new(edwards25519.Point).MultByCofactor(edwards25519.NewGeneratorPoint())
+	// orderMinusOne is l-1, the largest canonical scalar
+	orderMinusOne = new(edwards25519.Scalar).Negate(scalarOne())
+)
+
+func scalarOne() *edwards25519.Scalar {
+	one := [32]byte{1}
+	s, _ := edwards25519.NewScalar().SetCanonicalBytes(one[:])
+	return s
+}
+
+// SharedSecretProof proves that a shared secret is the X25519 agreement of a note's
+// ephemeral key and a receiving address without revealing either private key. Either
+// side of the agreement can make it: the sender with the ephemeral key, or the
+// recipient with the receiving key.
+type SharedSecretProof [sharedSecretProofSize]byte
+
+// MarshalText encodes the proof as 0x-prefixed hex
+func (p SharedSecretProof) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(p[:])), nil
+}
+
+// UnmarshalText decodes a proof from 0x-prefixed hex
+func (p *SharedSecretProof) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil || len(b) != len(p) {
+		return fmt.Errorf("invalid shared secret proof encoding")
+	}
+	copy(p[:], b)
+	return nil
+}
+
+// x25519Point lifts a canonical X25519 u-coordinate to edwards25519, rejecting points
+// off the curve and points with a small-order component. Honest keys and secrets are
+// multiples of the base point and always pass.
+func x25519Point(u []byte) (*edwards25519.Point, error) {
+	fe, err := new(field.Element).SetBytes(u)
+	if err != nil || !bytes.Equal(fe.Bytes(), u) {
+		return nil, fmt.Errorf("non-canonical x25519 point")
+	}
+	// The birational map to edwards25519: y = (u - 1) / (u + 1)
+	one := new(field.Element).One()
+	num := new(field.Element).Subtract(fe, one)
+	den := new(field.Element).Add(fe, one)
+	y := new(field.Element).Multiply(num, den.Invert(den))
+
+	p, err := new(edwards25519.Point).SetBytes(y.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("x25519 point is not on the curve")
+	}
+	// [l]P = [l-1]P + P is the identity only in the prime-order subgroup
+	lp := new(edwards25519.Point).ScalarMult(orderMinusOne, p)
+	if lp.Add(lp, p).Equal(edwards25519.NewIdentityPoint()) != 1 {
+		return nil, fmt.Errorf("x25519 point has a small-order component")
+	}
+	p.MultByCofactor(p)
+	if p.Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return nil, fmt.Errorf("x25519 point has small order")
+	}
+	return p, nil
+}
+
+// sharedSecretStatement is log_G(X) = log_H(Y): X is the prover's public key, H the
+// other party's and Y the shared secret
+type sharedSecretStatement struct {
+	X, H, Y *edwards25519.Point
+}
+
+func newSharedSecretStatement(public, peer, shared []byte) (*sharedSecretStatement, error) {
+	var st sharedSecretStatement
+	var err error
+	if st.X, err = x25519Point(public); err != nil {
+		return nil, err
+	}
+	if st.H, err = x25519Point(peer); err != nil {
+		return nil, err
+	}
+	if st.Y, err = x25519Point(shared); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// challenge binds the proof to its flags, the keys as published and the commitments
+func sharedSecretChallenge(flags byte, public, peer, shared []byte, r1, r2 *edwards25519.Point) *edwards25519.Scalar {
+	t := newTranscript(sharedSecretProofDomain)
+	t.appendBytes("flags", []byte{flags})
+	t.appendBytes("public", public)
+	t.appendBytes("peer", peer)
+	t.appendBytes("shared", shared)
+	t.appendBytes("r1", r1.Bytes())
+	t.appendBytes("r2", r2.Bytes())
+	c, err := new(edwards25519.Scalar).SetUniformBytes(t.wideChallenge("c"))
+	if err != nil {
+		// wideChallenge always returns 64 bytes
+		panic(err)
+	}
+	return c
+}
+
+// proveSharedSecret proves shared = X25519(priv, peer). sender says whether priv is the
+// note's ephemeral key, so the verifier knows which public key is the prover's.
+func proveSharedSecret(priv *ecdh.PrivateKey, peer, shared []byte, sender bool) (SharedSecretProof, error) {
+	var proof SharedSecretProof
+	public := priv.PublicKey().Bytes()
+	st, err := newSharedSecretStatement(public, peer, shared)
+	if err != nil {
+		return proof, err
+	}
+	w, err := new(edwards25519.Scalar).SetBytesWithClamping(priv.Bytes())
+	if err != nil {
+		return proof, err
+	}
+
+	// The lift picks one of ±X and ±Y: fix the witness sign so X = wG, then flag
+	// whether Y = wH or -wH
+	var flags byte
+	if sender {
+		flags |= proofBySender
+	}
+	if st.X.Equal(new(edwards25519.Point).ScalarMult(w, cofactorBase)) != 1 {
+		w.Negate(w)
+	}
+	if st.Y.Equal(new(edwards25519.Point).ScalarMult(w, st.H)) != 1 {
+		flags |= proofNegated
+		st.Y.Negate(st.Y)
+	}
+	if st.X.Equal(new(edwards25519.Point).ScalarMult(w, cofactorBase)) != 1 ||
+		st.Y.Equal(new(edwards25519.Point).ScalarMult(w, st.H)) != 1 {
+		return proof, fmt.Errorf("shared secret is not the agreement of the keys")
+	}
+
+	var nonce [64]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return proof, fmt.Errorf("failed to generate proof nonce: %w", err)
+	}
+	k, err := new(edwards25519.Scalar).SetUniformBytes(nonce[:])
+	if err != nil {
+		return proof, err
+	}
+	r1 := new(edwards25519.Point).ScalarMult(k, cofactorBase)
+	r2 := new(edwards25519.Point).ScalarMult(k, st.H)
+	c := sharedSecretChallenge(flags, public, peer, shared, r1, r2)
+
+	// s = k - c*w
+	negC := new(edwards25519.Scalar).Negate(c)
+	s := new(edwards25519.Scalar).MultiplyAdd(negC, w, k)
+
+	proof[0] = flags
+	copy(proof[1:33], c.Bytes())
+	copy(proof[33:], s.Bytes())
+	return proof, nil
+}
+
+// verify checks that shared is the X25519 agreement of ephemeral and addr
+func (p SharedSecretProof) verify(ephemeral, addr, shared []byte) error {
+	flags := p[0]
+	if flags&^(proofBySender|proofNegated) != 0 {
+		return fmt.Errorf("%w: unknown flags %#x", errSharedSecretProof, flags)
+	}
+	public, peer := addr, ephemeral
+	if flags&proofBySender != 0 {
+		public, peer = ephemeral, addr
+	}
+	st, err := newSharedSecretStatement(public, peer, shared)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errSharedSecretProof, err)
+	}
+	if flags&proofNegated != 0 {
+		st.Y.Negate(st.Y)
+	}
+
+	c, err := new(edwards25519.Scalar).SetCanonicalBytes(p[1:33])
+	if err != nil {
+		return fmt.Errorf("%w: %v", errSharedSecretProof, err)
+	}
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(p[33:])
+	if err != nil {
+		return fmt.Errorf("%w: %v", errSharedSecretProof, err)
+	}
+
+	// r1 = sG + cX and r2 = sH + cY reproduce the prover's commitments
+	scalars := []*edwards25519.Scalar{s, c}
+	r1 := new(edwards25519.Point).VarTimeMultiScalarMult(scalars, []*edwards25519.Point{cofactorBase, st.X})
+	r2 := new(edwards25519.Point).VarTimeMultiScalarMult(scalars, []*edwards25519.Point{st.H, st.Y})
+	if sharedSecretChallenge(flags, public, peer, shared, r1, r2).Equal(c) != 1 {
+		return errSharedSecretProof
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package zk
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"filippo.io/edwards25519/field"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedSecretProof(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		receiving, err := ecdh.X25519().GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		eph, addr := ephemeral.PublicKey().Bytes(), receiving.PublicKey().Bytes()
+		shared, err := ephemeral.ECDH(receiving.PublicKey())
+		require.NoError(t, err)
+
+		// Either side of the agreement can prove it
+		bySender, err := proveSharedSecret(ephemeral, addr, shared, true)
+		require.NoError(t, err)
+		require.NoError(t, bySender.verify(eph, addr, shared))
+		byRecipient, err := proveSharedSecret(receiving, eph, shared, false)
+		require.NoError(t, err)
+		require.NoError(t, byRecipient.verify(eph, addr, shared))
+
+		// The proof does not carry over to another secret or another key
+		other, err := ecdh.X25519().GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		assert.Error(t, bySender.verify(eph, addr, other.PublicKey().Bytes()))
+		assert.Error(t, byRecipient.verify(eph, other.PublicKey().Bytes(), shared))
+
+		flipped := bySender
+		flipped[0] ^= proofBySender
+		assert.Error(t, flipped.verify(eph, addr, shared))
+	}
+}
+
+func TestSharedSecretProofRejectsWrongSecret(t *testing.T) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	peer, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	wrong, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, err = proveSharedSecret(priv, peer.PublicKey().Bytes(), wrong.PublicKey().Bytes(), false)
+	assert.Error(t, err)
+
+	// Small-order and non-canonical points are not accepted as keys
+	var zero, nonCanonical [32]byte
+	for i := range nonCanonical {
+		nonCanonical[i] = 0xff
+	}
+	_, err = x25519Point(zero[:])
+	assert.Error(t, err)
+	_, err = x25519Point(nonCanonical[:])
+	assert.Error(t, err)
+}
+
+func TestSharedSecretProofRejectsTorsionOffset(t *testing.T) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	receiving, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	eph, addr := ephemeral.PublicKey().Bytes(), receiving.PublicKey().Bytes()
+	shared, err := ephemeral.ECDH(receiving.PublicKey())
+	require.NoError(t, err)
+
+	// 1/u is the true secret plus the point of order 2; after the cofactor it would
+	// make the same statement, so a sender could disclose a key the recipient never agrees
+	u, err := new(field.Element).SetBytes(shared)
+	require.NoError(t, err)
+	offset := new(field.Element).Invert(u).Bytes()
+
+	_, err = x25519Point(offset)
+	assert.Error(t, err)
+	_, err = proveSharedSecret(ephemeral, addr, offset, true)
+	assert.Error(t, err)
+
+	proof, err := proveSharedSecret(ephemeral, addr, shared, true)
+	require.NoError(t, err)
+	require.NoError(t, proof.verify(eph, addr, shared))
+	assert.Error(t, proof.verify(eph, addr, offset))
+}
+
+func TestSharedSecretProofJSON(t *testing.T) {
+	var proof SharedSecretProof
+	_, err := rand.Read(proof[:])
+	require.NoError(t, err)
+
+	data, err := json.Marshal(proof)
+	require.NoError(t, err)
+	var decoded SharedSecretProof
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, proof, decoded)
+
+	assert.Error(t, json.Unmarshal([]byte(`"0x1234"`), &decoded))
+}
@@ -0,0 +1,432 @@
+package zk
+
+import (
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// MemoSize is the maximum length of an encrypted memo. Memos are padded to this
+	// size so the ciphertext length does not reveal them.
+	MemoSize = 512
+	// MaxAssetLength is the longest asset symbol a note plaintext can carry
+	MaxAssetLength = 32
+
+	notePlaintextVersion = 0x01
+	notePlaintextSize    = 1 + 8 + 32 + 1 + MaxAssetLength + 2 + MemoSize
+	encryptedNoteHeader  = pointSize + 8 + 32 + 32
+	outCiphertextSize    = 32 + 32 + chacha20poly1305.Overhead
+	noteEncryptionInfo   = "EasyCash/NoteEncryption/v1"
+	noteKeyCommitment    = "EasyCash/NoteKeyCommitment/v1"
+)
+
+// ErrNotForKey is returned when trial decryption finds a note addressed to another key
+var ErrNotForKey = errors.New("note is not addressed to this key")
+
+// ReceivingKey is the X25519 secret that decrypts notes sent to its ReceivingAddress
+type ReceivingKey struct {
+	priv *ecdh.PrivateKey
+}
+
+// NewReceivingKey returns a random receiving key
+func NewReceivingKey() (*ReceivingKey, error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate receiving key: %w", err)
+	}
+	return &ReceivingKey{priv: priv}, nil
+}
+
+// ReceivingKeyFromBytes wraps a 32-byte X25519 private key
+func ReceivingKeyFromBytes(b []byte) (*ReceivingKey, error) {
+	priv, err := ecdh.X25519().NewPrivateKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid receiving key: %w", err)
+	}
+	return &ReceivingKey{priv: priv}, nil
+}
+
+// Address returns the public address senders encrypt notes to
+func (k *ReceivingKey) Address() ReceivingAddress {
+	var addr ReceivingAddress
+	copy(addr[:], k.priv.PublicKey().Bytes())
+	return addr
+}
+
+// MarshalText encodes the key as 0x-prefixed hex
+func (k *ReceivingKey) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(k.priv.Bytes())), nil
+}
+
+// UnmarshalText decodes a key from 0x-prefixed hex
+func (k *ReceivingKey) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid receiving key encoding")
+	}
+	parsed, err := ReceivingKeyFromBytes(b)
+	if err != nil {
+		return err
+	}
+	*k = *parsed
+	return nil
+}
+
+// ReceivingAddress is the X25519 public key a shielded recipient publishes
+type ReceivingAddress [32]byte
+
+// ParseReceivingAddress decodes a 0x-prefixed hex receiving address, rejecting
+// addresses no sender could agree a key with
+func ParseReceivingAddress(s string) (ReceivingAddress, error) {
+	var addr ReceivingAddress
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != len(addr) {
+		return addr, fmt.Errorf("invalid receiving address: %s", s)
+	}
+	copy(addr[:], b)
+	if err := addr.validate(); err != nil {
+		return ReceivingAddress{}, err
+	}
+	return addr, nil
+}
+
+// validate checks that key agreement with the address succeeds. Points of small order
+// agree an all-zero secret with every key, which X25519 refuses.
+func (a ReceivingAddress) validate() error {
+	peer, err := ecdh.X25519().NewPublicKey(a[:])
+	if err != nil {
+		return fmt.Errorf("invalid receiving address: %w", err)
+	}
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	if _, err := priv.ECDH(peer); err != nil {
+		return fmt.Errorf("receiving address %s has small order", a)
+	}
+	return nil
+}
+
+// String returns the address as 0x-prefixed hex
+func (a ReceivingAddress) String() string {
+	return "0x" + hex.EncodeToString(a[:])
+}
+
+// MarshalText encodes the address as 0x-prefixed hex
+func (a ReceivingAddress) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText decodes an address from 0x-prefixed hex
+func (a *ReceivingAddress) UnmarshalText(text []byte) error {
+	parsed, err := ParseReceivingAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// EncryptedNote delivers a note to its recipient alongside the commitment it opens.
+// The ciphertext is authenticated together with the commitment and leaf index, so it
+// cannot be re-attached to another output. KeyCommitment binds it to the one key it was
+// sealed with, since ChaCha20-Poly1305 alone lets a sender craft a ciphertext that
+// opens under two keys. OutCiphertext lets the sender's outgoing viewing key recover
+// the recipient and the note later.
+type EncryptedNote struct {
+	Commitment    *Commitment
+	LeafIndex     uint64
+	EphemeralKey  [32]byte
+	KeyCommitment [32]byte
+	OutCiphertext []byte
+	Ciphertext    []byte
+}
+
+// EncryptNote encrypts note and an optional memo to addr with X25519 and
+// ChaCha20-Poly1305. The note must already have its leaf index. With a nil ovk the
+// outgoing ciphertext is sealed under a random key and nobody can recover it.
+func EncryptNote(addr ReceivingAddress, ovk *OutgoingViewingKey, note *Note, memo []byte) (*EncryptedNote, error) {
+	plaintext, err := encodeNotePlaintext(note, memo)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	recipient, err := ecdh.X25519().NewPublicKey(addr[:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid receiving address: %w", err)
+	}
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, fmt.Errorf("key agreement failed: %w", err)
+	}
+
+	enc := &EncryptedNote{Commitment: note.Commitment, LeafIndex: note.LeafIndex}
+	copy(enc.EphemeralKey[:], ephemeral.PublicKey().Bytes())
+
+	key, err := noteKey(shared, enc.EphemeralKey, addr)
+	if err != nil {
+		return nil, err
+	}
+	enc.KeyCommitment = commitNoteKey(key)
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	// Each key encrypts a single message, so a fixed nonce is safe
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	enc.Ciphertext = aead.Seal(nil, nonce, plaintext, enc.associatedData())
+
+	if ovk == nil {
+		var random OutgoingViewingKey
+		if _, err := rand.Read(random[:]); err != nil {
+			return nil, err
+		}
+		ovk = &random
+	}
+	outCipher, err := ovk.cipher(enc)
+	if err != nil {
+		return nil, err
+	}
+	// The ephemeral key rather than the shared secret lets the sender prove the agreement later
+	enc.OutCiphertext = outCipher.Seal(nil, nonce, append(addr[:], ephemeral.Bytes()...), enc.associatedData())
+	return enc, nil
+}
+
+// Decrypt trial-decrypts an encrypted note. It returns ErrNotForKey when the note is
+// addressed to someone else, and an error when the plaintext does not open the commitment.
+func (k *ReceivingKey) Decrypt(enc *EncryptedNote) (*Note, []byte, error) {
+	shared, err := k.sharedSecret(enc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return openNote(enc, shared, k.Address())
+}
+
+// sharedSecret agrees the note's shared secret with its ephemeral key
+func (k *ReceivingKey) sharedSecret(enc *EncryptedNote) ([]byte, error) {
+	return agree(k.priv, enc.EphemeralKey)
+}
+
+// agree computes the X25519 shared secret of priv and peer, reporting unusable peers
+// as ErrNotForKey
+func agree(priv *ecdh.PrivateKey, peer [32]byte) ([]byte, error) {
+	pub, err := ecdh.X25519().NewPublicKey(peer[:])
+	if err != nil {
+		return nil, ErrNotForKey
+	}
+	shared, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, ErrNotForKey
+	}
+	return shared, nil
+}
+
+// openNote decrypts the note ciphertext with the shared secret agreed with addr and
+// checks the plaintext opens the commitment
+func openNote(enc *EncryptedNote, shared []byte, addr ReceivingAddress) (*Note, []byte, error) {
+	key, err := noteKey(shared, enc.EphemeralKey, addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if commitment := commitNoteKey(key); subtle.ConstantTimeCompare(commitment[:], enc.KeyCommitment[:]) != 1 {
+		return nil, nil, ErrNotForKey
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	plaintext, err := aead.Open(nil, nonce, enc.Ciphertext, enc.associatedData())
+	if err != nil {
+		return nil, nil, ErrNotForKey
+	}
+
+	note, memo, err := decodeNotePlaintext(plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	if enc.Commitment == nil || !enc.Commitment.Verify(note.Amount, note.Blinding) {
+		return nil, nil, fmt.Errorf("decrypted note does not open its commitment")
+	}
+	note.Commitment = enc.Commitment
+	note.LeafIndex = enc.LeafIndex
+	return note, memo, nil
+}
+
+// noteKey derives the one-time ChaCha20-Poly1305 key from the shared secret
+func noteKey(shared []byte, ephemeral [32]byte, addr ReceivingAddress) ([]byte, error) {
+	info := noteEncryptionInfo + string(ephemeral[:]) + string(addr[:])
+	return hkdf.Key(sha256.New, shared, nil, info, chacha20poly1305.KeySize)
+}
+
+// commitNoteKey hashes a note key so the ciphertext only opens under that key
+func commitNoteKey(key []byte) [32]byte {
+	return sha256.Sum256(append([]byte(noteKeyCommitment), key...))
+}
+
+func (enc *EncryptedNote) associatedData() []byte {
+	var commitment []byte
+	if enc.Commitment != nil {
+		commitment = enc.Commitment.Bytes()
+	}
+	return binary.BigEndian.AppendUint64(commitment, enc.LeafIndex)
+}
+
+// Bytes serializes the encrypted note as
+// commitment || leaf index || ephemeral key || key commitment || outgoing ciphertext || ciphertext
+func (enc *EncryptedNote) Bytes() []byte {
+	out := make([]byte, 0, encryptedNoteHeader+len(enc.OutCiphertext)+len(enc.Ciphertext))
+	out = append(out, enc.Commitment.Bytes()...)
+	out = binary.BigEndian.AppendUint64(out, enc.LeafIndex)
+	out = append(out, enc.EphemeralKey[:]...)
+	out = append(out, enc.KeyCommitment[:]...)
+	out = append(out, enc.OutCiphertext...)
+	return append(out, enc.Ciphertext...)
+}
+
+// String returns the serialized note as 0x-prefixed hex
+func (enc *EncryptedNote) String() string {
+	return "0x" + hex.EncodeToString(enc.Bytes())
+}
+
+// ParseEncryptedNote decodes an encrypted note from Bytes
+func ParseEncryptedNote(data []byte) (*EncryptedNote, error) {
+	if len(data) != encryptedNoteHeader+outCiphertextSize+notePlaintextSize+chacha20poly1305.Overhead {
+		return nil, fmt.Errorf("invalid encrypted note length: %d", len(data))
+	}
+	c, err := ParseCommitment(data[:pointSize])
+	if err != nil {
+		return nil, err
+	}
+	enc := &EncryptedNote{
+		Commitment:    c,
+		LeafIndex:     binary.BigEndian.Uint64(data[pointSize : pointSize+8]),
+		OutCiphertext: append([]byte(nil), data[encryptedNoteHeader:encryptedNoteHeader+outCiphertextSize]...),
+		Ciphertext:    append([]byte(nil), data[encryptedNoteHeader+outCiphertextSize:]...),
+	}
+	copy(enc.EphemeralKey[:], data[pointSize+8:pointSize+40])
+	copy(enc.KeyCommitment[:], data[pointSize+40:encryptedNoteHeader])
+	return enc, nil
+}
+
+// ParseEncryptedNoteString decodes an encrypted note from 0x-prefixed hex
+func ParseEncryptedNoteString(s string) (*EncryptedNote, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted note encoding: %w", err)
+	}
+	return ParseEncryptedNote(data)
+}
+
+// MarshalText encodes the encrypted note as 0x-prefixed hex
+func (enc *EncryptedNote) MarshalText() ([]byte, error) {
+	return []byte(enc.String()), nil
+}
+
+// UnmarshalText decodes an encrypted note from 0x-prefixed hex
+func (enc *EncryptedNote) UnmarshalText(text []byte) error {
+	parsed, err := ParseEncryptedNoteString(string(text))
+	if err != nil {
+		return err
+	}
+	*enc = *parsed
+	return nil
+}
+
+// encodeNotePlaintext lays out version || amount || blinding || asset || memo with the
+// asset and memo padded to fixed sizes
+func encodeNotePlaintext(note *Note, memo []byte) ([]byte, error) {
+	if len(note.Asset) > MaxAssetLength {
+		return nil, fmt.Errorf("asset symbol longer than %d bytes", MaxAssetLength)
+	}
+	if len(memo) > MemoSize {
+		return nil, fmt.Errorf("memo longer than %d bytes", MemoSize)
+	}
+
+	out := make([]byte, notePlaintextSize)
+	out[0] = notePlaintextVersion
+	binary.BigEndian.PutUint64(out[1:9], note.Amount)
+	copy(out[9:41], note.Blinding[:])
+	out[41] = byte(len(note.Asset))
+	copy(out[42:42+MaxAssetLength], note.Asset)
+	memoStart := 42 + MaxAssetLength
+	binary.BigEndian.PutUint16(out[memoStart:memoStart+2], uint16(len(memo)))
+	copy(out[memoStart+2:], memo)
+	return out, nil
+}
+
+func decodeNotePlaintext(data []byte) (*Note, []byte, error) {
+	if len(data) != notePlaintextSize || data[0] != notePlaintextVersion {
+		return nil, nil, fmt.Errorf("unsupported note plaintext")
+	}
+	note := &Note{Amount: binary.BigEndian.Uint64(data[1:9])}
+	copy(note.Blinding[:], data[9:41])
+
+	assetLen := int(data[41])
+	memoStart := 42 + MaxAssetLength
+	memoLen := int(binary.BigEndian.Uint16(data[memoStart : memoStart+2]))
+	if assetLen > MaxAssetLength || memoLen > MemoSize {
+		return nil, nil, fmt.Errorf("malformed note plaintext")
+	}
+	note.Asset = string(data[42 : 42+assetLen])
+
+	var memo []byte
+	if memoLen > 0 {
+		memo = append(memo, data[memoStart+2:memoStart+2+memoLen]...)
+	}
+	return note, memo, nil
+}
+
+// ScannedNote is a note found by trial decryption
+type ScannedNote struct {
+	Note    *Note
+	Memo    []byte
+	Address ReceivingAddress // the receiving address the note was sent to
+}
+
+// NoteScanner trial-decrypts published notes with a set of receiving keys to find the
+// ones addressed to the wallet
+type NoteScanner struct {
+	keys []*ReceivingKey
+}
+
+// NewNoteScanner returns a scanner for the given receiving keys
+func NewNoteScanner(keys ...*ReceivingKey) *NoteScanner {
+	return &NoteScanner{keys: keys}
+}
+
+// Scan returns the notes addressed to any of the scanner's keys. Notes for other keys
+// are skipped; notes that decrypt but fail to open their commitment are reported.
+func (s *NoteScanner) Scan(notes []*EncryptedNote) ([]ScannedNote, error) {
+	var found []ScannedNote
+	var errs []error
+	for _, enc := range notes {
+		for _, key := range s.keys {
+			note, memo, err := key.Decrypt(enc)
+			if errors.Is(err, ErrNotForKey) {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("leaf %d: %w", enc.LeafIndex, err))
+				break
+			}
+			found = append(found, ScannedNote{Note: note, Memo: memo, Address: key.Address()})
+			break
+		}
+	}
+	return found, errors.Join(errs...)
+}
@@ -0,0 +1,144 @@
+package zk
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestReceivingKey(t *testing.T) *ReceivingKey {
+	rk, err := NewReceivingKey()
+	require.NoError(t, err)
+	return rk
+}
+
+func TestEncryptNoteRoundTrip(t *testing.T) {
+	rk := newTestReceivingKey(t)
+	note, err := NewNote("USDC", 1_500_000)
+	require.NoError(t, err)
+	note.LeafIndex = 42
+
+	enc, err := EncryptNote(rk.Address(), nil, note, []byte("invoice 2024-117"))
+	require.NoError(t, err)
+
+	parsed, err := ParseEncryptedNoteString(enc.String())
+	require.NoError(t, err)
+
+	got, memo, err := rk.Decrypt(parsed)
+	require.NoError(t, err)
+	assert.Equal(t, "USDC", got.Asset)
+	assert.Equal(t, note.Amount, got.Amount)
+	assert.Equal(t, note.Blinding, got.Blinding)
+	assert.Equal(t, uint64(42), got.LeafIndex)
+	assert.Equal(t, "invoice 2024-117", string(memo))
+
+	// Ciphertext length does not depend on the memo
+	short, err := EncryptNote(rk.Address(), nil, note, nil)
+	require.NoError(t, err)
+	assert.Len(t, short.Bytes(), len(enc.Bytes()))
+
+	_, _, err = newTestReceivingKey(t).Decrypt(parsed)
+	assert.ErrorIs(t, err, ErrNotForKey)
+
+	_, err = EncryptNote(rk.Address(), nil, note, make([]byte, MemoSize+1))
+	assert.Error(t, err)
+}
+
+func TestEncryptedNoteBoundToOutput(t *testing.T) {
+	rk := newTestReceivingKey(t)
+	note, err := NewNote("USDC", 10)
+	require.NoError(t, err)
+	enc, err := EncryptNote(rk.Address(), nil, note, nil)
+	require.NoError(t, err)
+
+	// Moving the ciphertext to another leaf or commitment breaks authentication
+	moved := *enc
+	moved.LeafIndex = 1
+	_, _, err = rk.Decrypt(&moved)
+	assert.ErrorIs(t, err, ErrNotForKey)
+
+	other, err := NewNote("USDC", 10)
+	require.NoError(t, err)
+	swapped := *enc
+	swapped.Commitment = other.Commitment
+	_, _, err = rk.Decrypt(&swapped)
+	assert.ErrorIs(t, err, ErrNotForKey)
+}
+
+func TestNoteScanner(t *testing.T) {
+	ours, theirs := newTestReceivingKey(t), newTestReceivingKey(t)
+
+	state := NewShieldedState()
+	var published []*EncryptedNote
+	for i, addr := range []ReceivingAddress{theirs.Address(), ours.Address(), theirs.Address(), ours.Address()} {
+		note, err := NewNote("USDC", uint64(100*(i+1)))
+		require.NoError(t, err)
+		note.LeafIndex, err = state.AddCommitment(note.Commitment)
+		require.NoError(t, err)
+		enc, err := EncryptNote(addr, nil, note, nil)
+		require.NoError(t, err)
+		published = append(published, enc)
+	}
+
+	found, err := NewNoteScanner(ours).Scan(published)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, uint64(200), found[0].Note.Amount)
+	assert.Equal(t, uint64(400), found[1].Note.Amount)
+	assert.Equal(t, ours.Address(), found[0].Address)
+
+	// The wallet records scanned notes once
+	w := newTestWallet(t)
+	w.SetViewingKey(&ViewingKey{Incoming: ours})
+	received, err := w.Receive(state, published...)
+	require.NoError(t, err)
+	assert.Len(t, received, 2)
+	assert.Equal(t, uint64(600), w.Balance("USDC"))
+
+	received, err = w.Receive(state, published...)
+	require.NoError(t, err)
+	assert.Empty(t, received)
+	assert.Equal(t, uint64(600), w.Balance("USDC"))
+
+	// Notes outside the receiver's tree are not spendable and so not recorded
+	other := newTestWallet(t)
+	other.SetViewingKey(&ViewingKey{Incoming: ours})
+	received, err = other.Receive(NewShieldedState(), published...)
+	assert.ErrorIs(t, err, ErrNoteNotInTree)
+	assert.Empty(t, received)
+	assert.Zero(t, other.Balance("USDC"))
+}
+
+func TestReceivingKeyPersistence(t *testing.T) {
+	rk := newTestReceivingKey(t)
+	data, err := json.Marshal(rk)
+	require.NoError(t, err)
+	var decoded ReceivingKey
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, rk.Address(), decoded.Address())
+
+	w := newTestWallet(t)
+	w.SetViewingKey(&ViewingKey{Incoming: rk})
+	path := filepath.Join(t.TempDir(), "notes.json")
+	require.NoError(t, w.SaveFile(path))
+	loaded, err := LoadNoteWalletFile(path)
+	require.NoError(t, err)
+	require.NotNil(t, loaded.ViewingKey())
+	assert.Equal(t, rk.Address(), loaded.ViewingKey().Address())
+
+	addr, err := ParseReceivingAddress(rk.Address().String())
+	require.NoError(t, err)
+	assert.Equal(t, rk.Address(), addr)
+	_, err = ParseReceivingAddress("0x1234")
+	assert.Error(t, err)
+
+	// Points of small order cannot agree a key, so nothing could be encrypted to them
+	_, err = ParseReceivingAddress("0x" + strings.Repeat("00", 32))
+	assert.Error(t, err)
+	_, err = ParseReceivingAddress("0x01" + strings.Repeat("00", 31))
+	assert.Error(t, err)
+}
@@ -0,0 +1,340 @@
+package zk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// EnvelopeVersion is the envelope format written by this SDK
+	EnvelopeVersion = 1
+
+	// ProofSystemBulletproofs identifies Bulletproofs range proofs over secp256k1
+	// Pedersen commitments, the format produced by every Prover in this package
+	ProofSystemBulletproofs = "bulletproofs/secp256k1"
+)
+
+// envelopeMagic starts every binary envelope
+var envelopeMagic = []byte("ECPE")
+
+// maxEnvelopeLabel bounds the proof system and circuit identifiers
+const maxEnvelopeLabel = 1<<8 - 1
+
+// ProofEnvelope carries a proof together with everything needed to verify it later:
+// the proof system and circuit it was created for, the hash of the verification key
+// and the public inputs it is bound to. Decoding is strict so an envelope written by
+// one SDK version is either understood exactly by another or rejected.
+type ProofEnvelope struct {
+	Version     uint8
+	ProofSystem string
+	CircuitID   string
+	KeyHash     []byte
+	Inputs      PublicInputs
+	Proof       []byte
+}
+
+// NewProofEnvelope wraps raw proof bytes created under vk for the given inputs
+func NewProofEnvelope(vk *VerificationKey, inputs PublicInputs, proof []byte) *ProofEnvelope {
+	return &ProofEnvelope{
+		Version:     EnvelopeVersion,
+		ProofSystem: ProofSystemBulletproofs,
+		CircuitID:   vk.CircuitID,
+		KeyHash:     vk.Hash(),
+		Inputs:      inputs,
+		Proof:       append([]byte(nil), proof...),
+	}
+}
+
+// validate checks the fields both encodings require
+func (e *ProofEnvelope) validate() error {
+	if e.Version != EnvelopeVersion {
+		return fmt.Errorf("unsupported envelope version %d", e.Version)
+	}
+	if e.ProofSystem == "" || len(e.ProofSystem) > maxEnvelopeLabel {
+		return fmt.Errorf("invalid proof system %q", e.ProofSystem)
+	}
+	if e.CircuitID == "" || len(e.CircuitID) > maxEnvelopeLabel {
+		return fmt.Errorf("invalid circuit id %q", e.CircuitID)
+	}
+	if len(e.KeyHash) != sha256.Size {
+		return fmt.Errorf("verification key hash is %d bytes, expected %d", len(e.KeyHash), sha256.Size)
+	}
+	if len(e.Proof) == 0 {
+		return fmt.Errorf("proof is empty")
+	}
+	if _, err := e.Inputs.encode(); err != nil {
+		return fmt.Errorf("public inputs: %w", err)
+	}
+	return nil
+}
+
+// Bytes encodes the envelope as magic, version, length-prefixed proof system and
+// circuit id, key hash, and length-prefixed public inputs and proof
+func (e *ProofEnvelope) Bytes() []byte {
+	inputs, _ := e.Inputs.encode()
+
+	out := append([]byte(nil), envelopeMagic...)
+	out = append(out, e.Version)
+	out = append(out, byte(len(e.ProofSystem)))
+	out = append(out, e.ProofSystem...)
+	out = append(out, byte(len(e.CircuitID)))
+	out = append(out, e.CircuitID...)
+	out = append(out, e.KeyHash...)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(inputs)))
+	out = append(out, inputs...)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(e.Proof)))
+	return append(out, e.Proof...)
+}
+
+// String returns the 0x-prefixed hex encoding of Bytes
+func (e *ProofEnvelope) String() string {
+	return "0x" + hex.EncodeToString(e.Bytes())
+}
+
+// ID returns a short digest of the encoded envelope for logs
+func (e *ProofEnvelope) ID() string {
+	sum := sha256.Sum256(e.Bytes())
+	return "0x" + hex.EncodeToString(sum[:8])
+}
+
+// envelopeReader consumes a binary envelope front to back
+type envelopeReader struct {
+	b   []byte
+	err error
+}
+
+func (r *envelopeReader) next(n int, field string) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.b) {
+		r.err = fmt.Errorf("truncated %s", field)
+		return nil
+	}
+	out := r.b[:n]
+	r.b = r.b[n:]
+	return out
+}
+
+func (r *envelopeReader) label(field string) string {
+	n := r.next(1, field+" length")
+	if n == nil {
+		return ""
+	}
+	return string(r.next(int(n[0]), field))
+}
+
+func (r *envelopeReader) field(field string) []byte {
+	n := r.next(4, field+" length")
+	if n == nil {
+		return nil
+	}
+	return r.next(int(binary.BigEndian.Uint32(n)), field)
+}
+
+// ParseProofEnvelope decodes an envelope produced by Bytes. Unknown versions, bad
+// lengths and trailing bytes are rejected as *VerificationError with FailureMalformed.
+func ParseProofEnvelope(b []byte) (*ProofEnvelope, error) {
+	r := &envelopeReader{b: b}
+	if magic := r.next(len(envelopeMagic), "magic"); r.err == nil && !bytes.Equal(magic, envelopeMagic) {
+		return nil, verificationError(FailureMalformed, "not a proof envelope")
+	}
+	version := r.next(1, "version")
+	if r.err == nil && version[0] != EnvelopeVersion {
+		return nil, verificationError(FailureMalformed, "unsupported envelope version %d", version[0])
+	}
+
+	e := &ProofEnvelope{Version: EnvelopeVersion}
+	e.ProofSystem = r.label("proof system")
+	e.CircuitID = r.label("circuit id")
+	e.KeyHash = append([]byte(nil), r.next(sha256.Size, "verification key hash")...)
+	inputs := r.field("public inputs")
+	e.Proof = append([]byte(nil), r.field("proof")...)
+	if r.err != nil {
+		return nil, &VerificationError{Reason: FailureMalformed, Err: r.err}
+	}
+	if len(r.b) != 0 {
+		return nil, verificationError(FailureMalformed, "%d trailing bytes after envelope", len(r.b))
+	}
+
+	var err error
+	if e.Inputs, err = decodePublicInputs(inputs); err != nil {
+		return nil, &VerificationError{Reason: FailureMalformed, Err: err}
+	}
+	if err := e.validate(); err != nil {
+		return nil, &VerificationError{Reason: FailureMalformed, Err: err}
+	}
+	return e, nil
+}
+
+// ParseProofEnvelopeString decodes the 0x-prefixed hex form returned by String
+func ParseProofEnvelopeString(s string) (*ProofEnvelope, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, &VerificationError{Reason: FailureMalformed, Err: fmt.Errorf("invalid hex: %w", err)}
+	}
+	return ParseProofEnvelope(b)
+}
+
+// decodePublicInputs is the exact inverse of PublicInputs.encode
+func decodePublicInputs(b []byte) (PublicInputs, error) {
+	var in PublicInputs
+	if len(b) < pointSize+32+2 {
+		return in, fmt.Errorf("public inputs are %d bytes, too short", len(b))
+	}
+	var err error
+	if in.AmountCommitment, err = ParseCommitment(b[:pointSize]); err != nil {
+		return in, fmt.Errorf("amount: %w", err)
+	}
+	copy(in.Nullifier[:], b[pointSize:pointSize+32])
+	recipientLen := int(binary.BigEndian.Uint16(b[pointSize+32:]))
+	if rest := b[pointSize+34:]; len(rest) != recipientLen {
+		return in, fmt.Errorf("recipient is %d bytes, header says %d", len(rest), recipientLen)
+	}
+	in.Recipient = string(b[pointSize+34:])
+	return in, nil
+}
+
+// envelopeJSON is the JSON form of an envelope; pointers tell missing fields apart
+type envelopeJSON struct {
+	Version     *uint8        `json:"version"`
+	ProofSystem *string       `json:"proof_system"`
+	CircuitID   *string       `json:"circuit_id"`
+	KeyHash     *string       `json:"vk_hash"`
+	Inputs      *PublicInputs `json:"public_inputs"`
+	Proof       *string       `json:"proof"`
+}
+
+// MarshalJSON encodes the envelope with hex key hash and proof
+func (e *ProofEnvelope) MarshalJSON() ([]byte, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	keyHash := "0x" + hex.EncodeToString(e.KeyHash)
+	proof := "0x" + hex.EncodeToString(e.Proof)
+	return json.Marshal(envelopeJSON{
+		Version:     &e.Version,
+		ProofSystem: &e.ProofSystem,
+		CircuitID:   &e.CircuitID,
+		KeyHash:     &keyHash,
+		Inputs:      &e.Inputs,
+		Proof:       &proof,
+	})
+}
+
+// ParseProofEnvelopeJSON decodes the JSON form of an envelope, rejecting unknown or
+// missing fields and trailing data as *VerificationError with FailureMalformed
+func ParseProofEnvelopeJSON(data []byte) (*ProofEnvelope, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var raw envelopeJSON
+	if err := dec.Decode(&raw); err != nil {
+		return nil, &VerificationError{Reason: FailureMalformed, Err: err}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, verificationError(FailureMalformed, "trailing data after envelope")
+	}
+
+	switch {
+	case raw.Version == nil:
+		return nil, verificationError(FailureMalformed, "missing version")
+	case raw.ProofSystem == nil:
+		return nil, verificationError(FailureMalformed, "missing proof_system")
+	case raw.CircuitID == nil:
+		return nil, verificationError(FailureMalformed, "missing circuit_id")
+	case raw.KeyHash == nil:
+		return nil, verificationError(FailureMalformed, "missing vk_hash")
+	case raw.Inputs == nil:
+		return nil, verificationError(FailureMalformed, "missing public_inputs")
+	case raw.Proof == nil:
+		return nil, verificationError(FailureMalformed, "missing proof")
+	}
+
+	keyHash, err := hex.DecodeString(strings.TrimPrefix(*raw.KeyHash, "0x"))
+	if err != nil {
+		return nil, &VerificationError{Reason: FailureMalformed, Err: fmt.Errorf("vk_hash: %w", err)}
+	}
+	proof, err := hex.DecodeString(strings.TrimPrefix(*raw.Proof, "0x"))
+	if err != nil {
+		return nil, &VerificationError{Reason: FailureMalformed, Err: fmt.Errorf("proof: %w", err)}
+	}
+	decoded := ProofEnvelope{
+		Version:     *raw.Version,
+		ProofSystem: *raw.ProofSystem,
+		CircuitID:   *raw.CircuitID,
+		KeyHash:     keyHash,
+		Inputs:      *raw.Inputs,
+		Proof:       proof,
+	}
+	if err := decoded.validate(); err != nil {
+		return nil, &VerificationError{Reason: FailureMalformed, Err: err}
+	}
+	return &decoded, nil
+}
+
+// UnmarshalJSON decodes an envelope with ParseProofEnvelopeJSON
+func (e *ProofEnvelope) UnmarshalJSON(data []byte) error {
+	decoded, err := ParseProofEnvelopeJSON(data)
+	if err != nil {
+		return err
+	}
+	*e = *decoded
+	return nil
+}
+
+// Verify checks that the envelope was created for vk and the expected inputs, then
+// verifies the proof it carries. Failures are reported as *VerificationError.
+func (e *ProofEnvelope) Verify(vk *VerificationKey, expected PublicInputs) error {
+	if err := e.validate(); err != nil {
+		return &VerificationError{Reason: FailureMalformed, Err: err}
+	}
+	if e.ProofSystem != ProofSystemBulletproofs {
+		return verificationError(FailureMalformed, "unsupported proof system %q", e.ProofSystem)
+	}
+	if e.CircuitID != vk.CircuitID || !bytes.Equal(e.KeyHash, vk.Hash()) {
+		return verificationError(FailureKeyMismatch, "envelope is for %s key %x, verifying with %s key %x",
+			e.CircuitID, e.KeyHash, vk.CircuitID, vk.Hash())
+	}
+	if !e.Inputs.equal(&expected) {
+		return &VerificationError{Reason: FailureInputMismatch, Err: inputMismatch(&e.Inputs, &expected)}
+	}
+	proof, err := ParseSolvencyProof(e.Proof)
+	if err != nil {
+		return err
+	}
+	return proof.Verify(vk, expected)
+}
+
+// VerifySpentNotes checks that the enveloped proof's balance is the sum of the spent
+// notes' commitments, see SolvencyProof.VerifySpentNotes
+func (e *ProofEnvelope) VerifySpentNotes(spent []*Commitment) error {
+	proof, err := ParseSolvencyProof(e.Proof)
+	if err != nil {
+		return err
+	}
+	return proof.VerifySpentNotes(spent)
+}
+
+// Envelope wraps a proof returned by ProveSolvency for storage or transmission
+func (pg *ProofGenerator) Envelope(proof string, inputs PublicInputs) (*ProofEnvelope, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(proof, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proof hex: %w", err)
+	}
+	envelope := NewProofEnvelope(pg.prover.VerificationKey(), inputs, raw)
+	if err := envelope.validate(); err != nil {
+		return nil, err
+	}
+	return envelope, nil
+}
+
+// VerifyEnvelope verifies an enveloped proof under the generator's key
+func (pg *ProofGenerator) VerifyEnvelope(envelope *ProofEnvelope, inputs PublicInputs) error {
+	return envelope.Verify(pg.prover.VerificationKey(), inputs)
+}
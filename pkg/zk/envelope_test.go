@@ -0,0 +1,112 @@
+package zk
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEnvelope(t *testing.T) (*ProofGenerator, *ProofEnvelope, PublicInputs) {
+	t.Helper()
+	pg := NewProofGenerator(NewReferenceProver(NewVerificationKey(SolvencyCircuitID)))
+	inputs := &PublicInputs{Nullifier: Nullifier{3}, Recipient: "0xabc"}
+	proof, err := pg.GenerateSolvencyProof(context.Background(), "10", "4", inputs)
+	require.NoError(t, err)
+	envelope, err := pg.Envelope(proof, *inputs)
+	require.NoError(t, err)
+	return pg, envelope, *inputs
+}
+
+func TestProofEnvelopeEncodings(t *testing.T) {
+	pg, envelope, inputs := testEnvelope(t)
+	assert.Equal(t, uint8(EnvelopeVersion), envelope.Version)
+	assert.Equal(t, ProofSystemBulletproofs, envelope.ProofSystem)
+	assert.Equal(t, SolvencyCircuitID, envelope.CircuitID)
+	require.NoError(t, pg.VerifyEnvelope(envelope, inputs))
+
+	decoded, err := ParseProofEnvelopeString(envelope.String())
+	require.NoError(t, err)
+	assert.Equal(t, envelope, decoded)
+	assert.Equal(t, envelope.ID(), decoded.ID())
+	require.NoError(t, pg.VerifyEnvelope(decoded, inputs))
+
+	data, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	var fromJSON ProofEnvelope
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	assert.Equal(t, envelope, &fromJSON)
+	require.NoError(t, pg.VerifyEnvelope(&fromJSON, inputs))
+}
+
+func TestProofEnvelopeStrictDecoding(t *testing.T) {
+	_, envelope, _ := testEnvelope(t)
+	encoded := envelope.Bytes()
+
+	malformed := map[string][]byte{
+		"empty":          nil,
+		"magic":          append([]byte("XXXX"), encoded[4:]...),
+		"version":        append(append(append([]byte(nil), encoded[:4]...), 2), encoded[5:]...),
+		"truncated":      encoded[:len(encoded)-1],
+		"trailing bytes": append(append([]byte(nil), encoded...), 0),
+	}
+	for name, b := range malformed {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseProofEnvelope(b)
+			requireFailure(t, err, FailureMalformed)
+		})
+	}
+
+	var fields map[string]json.RawMessage
+	data, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &fields))
+
+	mutate := func(f func(map[string]json.RawMessage)) []byte {
+		copied := make(map[string]json.RawMessage, len(fields))
+		for k, v := range fields {
+			copied[k] = v
+		}
+		f(copied)
+		out, err := json.Marshal(copied)
+		require.NoError(t, err)
+		return out
+	}
+	invalidJSON := map[string][]byte{
+		"unknown field":   mutate(func(m map[string]json.RawMessage) { m["extra"] = json.RawMessage(`1`) }),
+		"missing field":   mutate(func(m map[string]json.RawMessage) { delete(m, "vk_hash") }),
+		"future version":  mutate(func(m map[string]json.RawMessage) { m["version"] = json.RawMessage(`2`) }),
+		"short key hash":  mutate(func(m map[string]json.RawMessage) { m["vk_hash"] = json.RawMessage(`"0x00"`) }),
+		"trailing data":   append(append([]byte(nil), data...), []byte(`{}`)...),
+		"unknown nested":  mutate(func(m map[string]json.RawMessage) { m["public_inputs"] = json.RawMessage(`{"extra":1}`) }),
+		"missing amounts": mutate(func(m map[string]json.RawMessage) { m["public_inputs"] = json.RawMessage(`{}`) }),
+	}
+	for name, b := range invalidJSON {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseProofEnvelopeJSON(b)
+			requireFailure(t, err, FailureMalformed)
+		})
+	}
+}
+
+func TestProofEnvelopeVerify(t *testing.T) {
+	pg, envelope, inputs := testEnvelope(t)
+
+	other := inputs
+	other.Recipient = "0xdef"
+	requireFailure(t, pg.VerifyEnvelope(envelope, other), FailureInputMismatch)
+
+	otherKey := NewProofGenerator(NewReferenceProver(NewVerificationKey("easycash/solvency/v2")))
+	requireFailure(t, otherKey.VerifyEnvelope(envelope, inputs), FailureKeyMismatch)
+
+	unknown := *envelope
+	unknown.ProofSystem = "groth16/bn254"
+	requireFailure(t, pg.VerifyEnvelope(&unknown, inputs), FailureMalformed)
+
+	// Relabelling the envelope does not make the proof inside verify for other inputs
+	relabelled := *envelope
+	relabelled.Inputs = other
+	requireFailure(t, pg.VerifyEnvelope(&relabelled, other), FailureInputMismatch)
+}
@@ -0,0 +1,190 @@
+package zk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrUnknownCircuit  = errors.New("circuit is not in the manifest")
+	ErrCircuitTampered = errors.New("circuit does not match its manifest hash")
+)
+
+// CircuitManifestEntry pins one circuit version to the hashes of its artifact and
+// verification key. Relative paths are resolved against the manifest's directory.
+type CircuitManifestEntry struct {
+	CircuitID       string `json:"circuit_id"`
+	Artifact        string `json:"artifact,omitempty"`      // circuit artifact for the external prover
+	ArtifactHash    string `json:"artifact_hash,omitempty"` // hex sha256 of the artifact file
+	VerificationKey string `json:"verification_key,omitempty"`
+	KeyHash         string `json:"vk_hash"` // hex VerificationKey.Hash; required even for the built-in key
+}
+
+// circuitManifestFile is the JSON layout of a manifest
+type circuitManifestFile struct {
+	Circuits []CircuitManifestEntry `json:"circuits"`
+}
+
+// Circuit is a manifest entry whose artifact and verification key have been checked
+type Circuit struct {
+	ID              string
+	ArtifactPath    string
+	VerificationKey *VerificationKey
+
+	artifactHash []byte
+}
+
+// CheckArtifact re-hashes the circuit artifact, failing with ErrCircuitTampered if
+// it changed since the manifest was written
+func (c *Circuit) CheckArtifact() error {
+	if c.ArtifactPath == "" {
+		return nil
+	}
+	sum, err := HashCircuitArtifact(c.ArtifactPath)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sum, c.artifactHash) {
+		return fmt.Errorf("%w: %s artifact %s hashes to %x, expected %x", ErrCircuitTampered, c.ID, c.ArtifactPath, sum, c.artifactHash)
+	}
+	return nil
+}
+
+// HashCircuitArtifact returns the sha256 of an artifact file as recorded in manifests
+func HashCircuitArtifact(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open circuit artifact: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("failed to read circuit artifact: %w", err)
+	}
+	return h.Sum(nil), nil
+}
+
+// CircuitManifest holds the verified circuits of a manifest file, so several circuit
+// versions can be proven and verified side by side
+type CircuitManifest struct {
+	circuits map[string]*Circuit
+	order    []string
+}
+
+// LoadCircuitManifest reads a manifest and checks every artifact and verification key
+// against its recorded hash. Any mismatch fails the load with ErrCircuitTampered.
+func LoadCircuitManifest(path string) (*CircuitManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read circuit manifest: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var file circuitManifestFile
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse circuit manifest %s: %w", path, err)
+	}
+	if len(file.Circuits) == 0 {
+		return nil, fmt.Errorf("circuit manifest %s lists no circuits", path)
+	}
+
+	dir := filepath.Dir(path)
+	m := &CircuitManifest{circuits: make(map[string]*Circuit)}
+	for _, entry := range file.Circuits {
+		if _, dup := m.circuits[entry.CircuitID]; dup {
+			return nil, fmt.Errorf("circuit manifest %s lists %s twice", path, entry.CircuitID)
+		}
+		circuit, err := loadCircuit(dir, entry)
+		if err != nil {
+			return nil, err
+		}
+		m.circuits[circuit.ID] = circuit
+		m.order = append(m.order, circuit.ID)
+	}
+	return m, nil
+}
+
+// loadCircuit verifies one manifest entry
+func loadCircuit(dir string, entry CircuitManifestEntry) (*Circuit, error) {
+	if entry.CircuitID == "" {
+		return nil, fmt.Errorf("circuit manifest entry has no circuit id")
+	}
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+
+	circuit := &Circuit{ID: entry.CircuitID, ArtifactPath: resolve(entry.Artifact)}
+	if circuit.ArtifactPath != "" {
+		var err error
+		if circuit.artifactHash, err = decodeManifestHash(entry.ArtifactHash); err != nil {
+			return nil, fmt.Errorf("circuit %s artifact_hash: %w", entry.CircuitID, err)
+		}
+		if err := circuit.CheckArtifact(); err != nil {
+			return nil, err
+		}
+	}
+
+	keyHash, err := decodeManifestHash(entry.KeyHash)
+	if err != nil {
+		return nil, fmt.Errorf("circuit %s vk_hash: %w", entry.CircuitID, err)
+	}
+	vk := NewVerificationKey(entry.CircuitID)
+	if entry.VerificationKey != "" {
+		if vk, err = LoadVerificationKey(resolve(entry.VerificationKey)); err != nil {
+			return nil, err
+		}
+	}
+	if vk.CircuitID != entry.CircuitID {
+		return nil, fmt.Errorf("%w: %s verification key is for %s", ErrCircuitTampered, entry.CircuitID, vk.CircuitID)
+	}
+	if !bytes.Equal(vk.Hash(), keyHash) {
+		return nil, fmt.Errorf("%w: %s verification key hashes to %x, expected %x", ErrCircuitTampered, entry.CircuitID, vk.Hash(), keyHash)
+	}
+	circuit.VerificationKey = vk
+	return circuit, nil
+}
+
+// decodeManifestHash decodes a hex sha256 digest with optional 0x prefix
+func decodeManifestHash(s string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != sha256.Size {
+		return nil, fmt.Errorf("expected a hex sha256 digest, got %q", s)
+	}
+	return b, nil
+}
+
+// Circuit returns a verified circuit by id
+func (m *CircuitManifest) Circuit(id string) (*Circuit, error) {
+	circuit, ok := m.circuits[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCircuit, id)
+	}
+	return circuit, nil
+}
+
+// Circuits returns the circuit ids in manifest order
+func (m *CircuitManifest) Circuits() []string {
+	return append([]string(nil), m.order...)
+}
+
+// VerifyEnvelope verifies an enveloped proof under the key of whichever manifest
+// circuit it names, so proofs from every listed version stay verifiable
+func (m *CircuitManifest) VerifyEnvelope(envelope *ProofEnvelope, inputs PublicInputs) error {
+	circuit, ok := m.circuits[envelope.CircuitID]
+	if !ok {
+		return &VerificationError{Reason: FailureKeyMismatch, Err: fmt.Errorf("%w: %s", ErrUnknownCircuit, envelope.CircuitID)}
+	}
+	return envelope.Verify(circuit.VerificationKey, inputs)
+}
@@ -0,0 +1,142 @@
+package zk
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestManifest writes a manifest listing the built-in v1 circuit and a v2
+// circuit with its own key file, and returns its path
+func writeTestManifest(t *testing.T, edit func(entries []CircuitManifestEntry)) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{"v1.wasm": "circuit v1", "v2.wasm": "circuit v2"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	v2 := NewVerificationKey("easycash/solvency/v2")
+	data, err := json.Marshal(v2)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "v2.vk.json"), data, 0600))
+
+	hash := func(name string) string {
+		sum, err := HashCircuitArtifact(filepath.Join(dir, name))
+		require.NoError(t, err)
+		return hex.EncodeToString(sum)
+	}
+	entries := []CircuitManifestEntry{
+		{
+			CircuitID:    SolvencyCircuitID,
+			Artifact:     "v1.wasm",
+			ArtifactHash: hash("v1.wasm"),
+			KeyHash:      hex.EncodeToString(NewVerificationKey(SolvencyCircuitID).Hash()),
+		},
+		{
+			CircuitID:       v2.CircuitID,
+			Artifact:        "v2.wasm",
+			ArtifactHash:    "0x" + hash("v2.wasm"),
+			VerificationKey: "v2.vk.json",
+			KeyHash:         hex.EncodeToString(v2.Hash()),
+		},
+	}
+	if edit != nil {
+		edit(entries)
+	}
+	data, err = json.Marshal(circuitManifestFile{Circuits: entries})
+	require.NoError(t, err)
+	path := filepath.Join(dir, "manifest.json")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func TestCircuitManifest(t *testing.T) {
+	path := writeTestManifest(t, nil)
+	manifest, err := LoadCircuitManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{SolvencyCircuitID, "easycash/solvency/v2"}, manifest.Circuits())
+
+	_, err = manifest.Circuit("easycash/solvency/v3")
+	assert.ErrorIs(t, err, ErrUnknownCircuit)
+
+	// Proofs from either version verify through the manifest
+	for _, id := range manifest.Circuits() {
+		circuit, err := manifest.Circuit(id)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Dir(path), filepath.Dir(circuit.ArtifactPath))
+
+		pg := NewProofGenerator(NewReferenceProver(circuit.VerificationKey))
+		inputs := &PublicInputs{Recipient: "0xabc"}
+		proof, err := pg.GenerateSolvencyProof(context.Background(), "10", "4", inputs)
+		require.NoError(t, err)
+		envelope, err := pg.Envelope(proof, *inputs)
+		require.NoError(t, err)
+		assert.NoError(t, manifest.VerifyEnvelope(envelope, *inputs))
+	}
+
+	// Selecting a version through the prover config
+	prover, err := NewProver(ProverConfig{ManifestPath: path, CircuitID: "easycash/solvency/v2"})
+	require.NoError(t, err)
+	assert.Equal(t, "easycash/solvency/v2", prover.VerificationKey().CircuitID)
+
+	_, err = NewProver(ProverConfig{ManifestPath: path, KeyPath: "vk.json"})
+	assert.Error(t, err)
+}
+
+func TestCircuitManifestRejectsTampering(t *testing.T) {
+	t.Run("artifact", func(t *testing.T) {
+		path := writeTestManifest(t, nil)
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "v2.wasm"), []byte("patched"), 0600))
+		_, err := LoadCircuitManifest(path)
+		assert.ErrorIs(t, err, ErrCircuitTampered)
+	})
+
+	t.Run("verification key", func(t *testing.T) {
+		path := writeTestManifest(t, nil)
+		data, err := json.Marshal(NewVerificationKey("easycash/solvency/v3"))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "v2.vk.json"), data, 0600))
+		_, err = LoadCircuitManifest(path)
+		assert.ErrorIs(t, err, ErrCircuitTampered)
+	})
+
+	t.Run("key hash", func(t *testing.T) {
+		path := writeTestManifest(t, func(entries []CircuitManifestEntry) {
+			entries[0].KeyHash = hex.EncodeToString(NewVerificationKey("easycash/other/v1").Hash())
+		})
+		_, err := LoadCircuitManifest(path)
+		assert.ErrorIs(t, err, ErrCircuitTampered)
+	})
+
+	t.Run("missing hash", func(t *testing.T) {
+		path := writeTestManifest(t, func(entries []CircuitManifestEntry) { entries[1].ArtifactHash = "" })
+		_, err := LoadCircuitManifest(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("after load", func(t *testing.T) {
+		t.Setenv("ECASH_TEST_PROVER", "reference")
+		path := writeTestManifest(t, nil)
+		prover, err := NewProver(ProverConfig{
+			Backend:      BackendExternal,
+			ManifestPath: path,
+			Command:      os.Args[0],
+			Args:         []string{"-test.run=^TestHelperProverProcess$"},
+		})
+		require.NoError(t, err)
+		pg := NewProofGenerator(prover)
+
+		inputs := &PublicInputs{Recipient: "0xabc"}
+		_, err = pg.GenerateSolvencyProof(context.Background(), "10", "4", inputs)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "v1.wasm"), []byte("patched"), 0600))
+		_, err = pg.GenerateSolvencyProof(context.Background(), "10", "4", inputs)
+		assert.ErrorIs(t, err, ErrCircuitTampered)
+	})
+}
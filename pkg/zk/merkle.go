@@ -0,0 +1,302 @@
+package zk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultTreeDepth holds up to 2^32 note commitments
+	DefaultTreeDepth = 32
+
+	// DefaultRootHistory is how many recent roots remain valid spend anchors
+	DefaultRootHistory = 100
+)
+
+var (
+	ErrTreeFull    = errors.New("note commitment tree is full")
+	ErrUnknownLeaf = errors.New("leaf index out of range")
+)
+
+// MerkleHash is a node of the note commitment tree
+type MerkleHash [32]byte
+
+// String returns the 0x-prefixed hex encoding of the hash
+func (h MerkleHash) String() string {
+	return "0x" + hex.EncodeToString(h[:])
+}
+
+// MarshalText encodes the hash as 0x-prefixed hex
+func (h MerkleHash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText decodes a hash from 0x-prefixed hex
+func (h *MerkleHash) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil || len(b) != len(h) {
+		return fmt.Errorf("invalid merkle hash: %q", text)
+	}
+	copy(h[:], b)
+	return nil
+}
+
+// ParseMerkleHash decodes a 0x-prefixed hex hash
+func ParseMerkleHash(s string) (MerkleHash, error) {
+	var h MerkleHash
+	err := h.UnmarshalText([]byte(s))
+	return h, err
+}
+
+// Leaves and inner nodes are hashed with distinct prefixes so a leaf can never be
+// passed off as an inner node
+func hashLeaf(c *Commitment) MerkleHash {
+	return sha256.Sum256(append([]byte{0x00}, c.Bytes()...))
+}
+
+func hashNode(left, right MerkleHash) MerkleHash {
+	buf := make([]byte, 0, 65)
+	buf = append(buf, 0x01)
+	buf = append(buf, left[:]...)
+	buf = append(buf, right[:]...)
+	return sha256.Sum256(buf)
+}
+
+// zeroHashes returns the roots of empty subtrees of height 0..depth
+func zeroHashes(depth int) []MerkleHash {
+	zeros := make([]MerkleHash, depth+1)
+	for level := 1; level <= depth; level++ {
+		zeros[level] = hashNode(zeros[level-1], zeros[level-1])
+	}
+	return zeros
+}
+
+// MerkleTree is an append-only Merkle tree of note commitments. Every node is kept so
+// membership witnesses can be produced for any leaf. It is not safe for concurrent
+// use; ShieldedState adds locking.
+type MerkleTree struct {
+	depth   int
+	zeros   []MerkleHash
+	layers  [][]MerkleHash // layers[0] are leaves, layers[depth] the root
+	history []MerkleHash   // recent roots, oldest first
+	maxHist int
+}
+
+// NewMerkleTree returns an empty tree of the given depth remembering historySize roots
+func NewMerkleTree(depth, historySize int) (*MerkleTree, error) {
+	if depth < 1 || depth > 64 {
+		return nil, fmt.Errorf("invalid tree depth: %d", depth)
+	}
+	if historySize < 1 {
+		return nil, fmt.Errorf("invalid root history size: %d", historySize)
+	}
+	t := &MerkleTree{
+		depth:   depth,
+		zeros:   zeroHashes(depth),
+		layers:  make([][]MerkleHash, depth+1),
+		maxHist: historySize,
+	}
+	t.history = []MerkleHash{t.Root()}
+	return t, nil
+}
+
+// Depth returns the tree depth
+func (t *MerkleTree) Depth() int {
+	return t.depth
+}
+
+// Size returns the number of leaves
+func (t *MerkleTree) Size() uint64 {
+	return uint64(len(t.layers[0]))
+}
+
+// Root returns the current root
+func (t *MerkleTree) Root() MerkleHash {
+	if len(t.layers[t.depth]) == 0 {
+		return t.zeros[t.depth]
+	}
+	return t.layers[t.depth][0]
+}
+
+// Append adds a note commitment and returns its leaf index
+func (t *MerkleTree) Append(c *Commitment) (uint64, error) {
+	if !t.hasRoom(1) {
+		return 0, ErrTreeFull
+	}
+	index := t.Size()
+	t.appendLeaf(hashLeaf(c))
+	return index, nil
+}
+
+// hasLeaf reports whether commitment c is the leaf at index
+func (t *MerkleTree) hasLeaf(index uint64, c *Commitment) bool {
+	return c != nil && index < t.Size() && t.layers[0][index] == hashLeaf(c)
+}
+
+// hasRoom reports whether n more leaves fit in the tree
+func (t *MerkleTree) hasRoom(n uint64) bool {
+	return t.depth == 64 || t.Size()+n <= 1<<t.depth
+}
+
+func (t *MerkleTree) appendLeaf(leaf MerkleHash) {
+	t.layers[0] = append(t.layers[0], leaf)
+	index := len(t.layers[0]) - 1
+
+	for level := 0; level < t.depth; level++ {
+		left, right := index&^1, index|1
+		l := t.layers[level][left]
+		r := t.zeros[level]
+		if right < len(t.layers[level]) {
+			r = t.layers[level][right]
+		}
+		index >>= 1
+		parent := hashNode(l, r)
+		if index < len(t.layers[level+1]) {
+			t.layers[level+1][index] = parent
+		} else {
+			t.layers[level+1] = append(t.layers[level+1], parent)
+		}
+	}
+
+	t.history = append(t.history, t.Root())
+	if len(t.history) > t.maxHist {
+		t.history = t.history[len(t.history)-t.maxHist:]
+	}
+}
+
+// IsKnownRoot reports whether root is the current root or one of the recent ones
+func (t *MerkleTree) IsKnownRoot(root MerkleHash) bool {
+	for _, known := range t.history {
+		if known == root {
+			return true
+		}
+	}
+	return false
+}
+
+// RootHistory returns the remembered roots, oldest first
+func (t *MerkleTree) RootHistory() []MerkleHash {
+	return append([]MerkleHash(nil), t.history...)
+}
+
+// MerkleWitness proves that a leaf is part of the tree with a given root
+type MerkleWitness struct {
+	Index    uint64       `json:"index"`
+	Siblings []MerkleHash `json:"siblings"` // bottom-up
+	Root     MerkleHash   `json:"root"`
+}
+
+// Witness returns the membership witness of a leaf against the current root
+func (t *MerkleTree) Witness(index uint64) (*MerkleWitness, error) {
+	if index >= t.Size() {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownLeaf, index)
+	}
+	w := &MerkleWitness{Index: index, Siblings: make([]MerkleHash, t.depth), Root: t.Root()}
+	pos := int(index)
+	for level := 0; level < t.depth; level++ {
+		sibling := pos ^ 1
+		if sibling < len(t.layers[level]) {
+			w.Siblings[level] = t.layers[level][sibling]
+		} else {
+			w.Siblings[level] = t.zeros[level]
+		}
+		pos >>= 1
+	}
+	return w, nil
+}
+
+// WitnessAt returns the membership witness of a leaf against root, which must be the
+// current root or one of the recent ones. Leaves appended after root are not covered.
+func (t *MerkleTree) WitnessAt(index uint64, root MerkleHash) (*MerkleWitness, error) {
+	size, ok := t.sizeAt(root)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAnchor, root)
+	}
+	if index >= size {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownLeaf, index)
+	}
+	w := &MerkleWitness{Index: index, Siblings: make([]MerkleHash, t.depth), Root: root}
+	pos := index
+	for level := 0; level < t.depth; level++ {
+		w.Siblings[level] = t.nodeAt(level, pos^1, size)
+		pos >>= 1
+	}
+	return w, nil
+}
+
+// sizeAt returns how many leaves the tree held when root was its root. Every append
+// records one root, so a root's age in the history is the number of leaves added since.
+func (t *MerkleTree) sizeAt(root MerkleHash) (uint64, bool) {
+	for i := len(t.history) - 1; i >= 0; i-- {
+		if t.history[i] == root {
+			return t.Size() - uint64(len(t.history)-1-i), true
+		}
+	}
+	return 0, false
+}
+
+// nodeAt returns the node at level and pos of the tree holding only its first size leaves
+func (t *MerkleTree) nodeAt(level int, pos, size uint64) MerkleHash {
+	first := pos << level
+	if first >= size {
+		return t.zeros[level]
+	}
+	if size-first >= 1<<level {
+		// The subtree was already complete, so the stored node is unchanged
+		return t.layers[level][pos]
+	}
+	return hashNode(t.nodeAt(level-1, 2*pos, size), t.nodeAt(level-1, 2*pos+1, size))
+}
+
+// Verify checks that the witness links commitment c to its root
+func (w *MerkleWitness) Verify(c *Commitment) bool {
+	node := hashLeaf(c)
+	index := w.Index
+	for _, sibling := range w.Siblings {
+		if index&1 == 0 {
+			node = hashNode(node, sibling)
+		} else {
+			node = hashNode(sibling, node)
+		}
+		index >>= 1
+	}
+	return index == 0 && node == w.Root
+}
+
+// merkleSnapshot is the persisted form of a tree; inner nodes are rebuilt on load
+type merkleSnapshot struct {
+	Depth       int          `json:"depth"`
+	HistorySize int          `json:"history_size"`
+	Leaves      []MerkleHash `json:"leaves"`
+	RootHistory []MerkleHash `json:"root_history"`
+}
+
+func (t *MerkleTree) snapshot() merkleSnapshot {
+	return merkleSnapshot{
+		Depth:       t.depth,
+		HistorySize: t.maxHist,
+		Leaves:      append([]MerkleHash(nil), t.layers[0]...),
+		RootHistory: t.RootHistory(),
+	}
+}
+
+func restoreMerkleTree(s merkleSnapshot) (*MerkleTree, error) {
+	t, err := NewMerkleTree(s.Depth, s.HistorySize)
+	if err != nil {
+		return nil, err
+	}
+	if s.Depth < 64 && uint64(len(s.Leaves)) > 1<<s.Depth {
+		return nil, ErrTreeFull
+	}
+	for _, leaf := range s.Leaves {
+		t.appendLeaf(leaf)
+	}
+	if len(s.RootHistory) == 0 || len(s.RootHistory) > s.HistorySize || s.RootHistory[len(s.RootHistory)-1] != t.Root() {
+		return nil, fmt.Errorf("snapshot root history does not match its leaves")
+	}
+	t.history = append([]MerkleHash(nil), s.RootHistory...)
+	return t, nil
+}
@@ -0,0 +1,111 @@
+package zk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCommitments(t *testing.T, n int) []*Commitment {
+	out := make([]*Commitment, n)
+	for i := range out {
+		c, _, err := CommitRandom(uint64(i))
+		require.NoError(t, err)
+		out[i] = c
+	}
+	return out
+}
+
+func TestMerkleTreeWitnesses(t *testing.T) {
+	tree, err := NewMerkleTree(4, 3)
+	require.NoError(t, err)
+	empty := tree.Root()
+
+	commitments := testCommitments(t, 5)
+	for i, c := range commitments {
+		index, err := tree.Append(c)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(i), index)
+	}
+	assert.NotEqual(t, empty, tree.Root())
+
+	for i, c := range commitments {
+		w, err := tree.Witness(uint64(i))
+		require.NoError(t, err)
+		assert.Equal(t, tree.Root(), w.Root)
+		assert.True(t, w.Verify(c), "leaf %d", i)
+		assert.False(t, w.Verify(commitments[(i+1)%len(commitments)]), "leaf %d", i)
+	}
+
+	_, err = tree.Witness(5)
+	assert.ErrorIs(t, err, ErrUnknownLeaf)
+}
+
+func TestMerkleTreeRootHistory(t *testing.T) {
+	tree, err := NewMerkleTree(4, 3)
+	require.NoError(t, err)
+	commitments := testCommitments(t, 4)
+
+	var roots []MerkleHash
+	for _, c := range commitments {
+		_, err := tree.Append(c)
+		require.NoError(t, err)
+		roots = append(roots, tree.Root())
+	}
+
+	// Only the three most recent roots remain valid anchors
+	assert.False(t, tree.IsKnownRoot(roots[0]))
+	for _, root := range roots[1:] {
+		assert.True(t, tree.IsKnownRoot(root))
+	}
+	assert.Len(t, tree.RootHistory(), 3)
+}
+
+func TestMerkleTreeWitnessAt(t *testing.T) {
+	tree, err := NewMerkleTree(4, 8)
+	require.NoError(t, err)
+	commitments := testCommitments(t, 7)
+
+	// Record every leaf's witness while each root is current
+	var roots []MerkleHash
+	var witnesses [][]*MerkleWitness
+	for _, c := range commitments {
+		_, err := tree.Append(c)
+		require.NoError(t, err)
+		roots = append(roots, tree.Root())
+		var current []*MerkleWitness
+		for i := uint64(0); i < tree.Size(); i++ {
+			w, err := tree.Witness(i)
+			require.NoError(t, err)
+			current = append(current, w)
+		}
+		witnesses = append(witnesses, current)
+	}
+
+	// The current tree rebuilds the same witnesses for the older roots
+	for r, root := range roots {
+		for i, want := range witnesses[r] {
+			w, err := tree.WitnessAt(uint64(i), root)
+			require.NoError(t, err)
+			assert.Equal(t, want, w, "leaf %d at root %d", i, r)
+			assert.True(t, w.Verify(commitments[i]))
+		}
+		_, err := tree.WitnessAt(uint64(r+1), root)
+		assert.ErrorIs(t, err, ErrUnknownLeaf)
+	}
+
+	_, err = tree.WitnessAt(0, MerkleHash{1})
+	assert.ErrorIs(t, err, ErrUnknownAnchor)
+}
+
+func TestMerkleTreeFull(t *testing.T) {
+	tree, err := NewMerkleTree(1, 1)
+	require.NoError(t, err)
+	for _, c := range testCommitments(t, 2) {
+		_, err := tree.Append(c)
+		require.NoError(t, err)
+	}
+	_, err = tree.Append(testCommitments(t, 1)[0])
+	assert.ErrorIs(t, err, ErrTreeFull)
+}
@@ -0,0 +1,93 @@
+package zk
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// NullifierKey is the secret that derives a wallet's nullifiers. Only its holder can
+// tell when a note has been spent.
+type NullifierKey [32]byte
+
+// NewNullifierKey returns a random nullifier key
+func NewNullifierKey() (NullifierKey, error) {
+	var nk NullifierKey
+	if _, err := rand.Read(nk[:]); err != nil {
+		return nk, fmt.Errorf("failed to generate nullifier key: %w", err)
+	}
+	return nk, nil
+}
+
+// MarshalText encodes the key as 0x-prefixed hex
+func (nk NullifierKey) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(nk[:])), nil
+}
+
+// UnmarshalText decodes a key from 0x-prefixed hex
+func (nk *NullifierKey) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil || len(b) != len(nk) {
+		return fmt.Errorf("invalid nullifier key encoding")
+	}
+	copy(nk[:], b)
+	return nil
+}
+
+// Nullifier derives the nullifier of the note at leafIndex. It is unlinkable to the
+// commitment without the key, and unique per note position.
+func (nk NullifierKey) Nullifier(c *Commitment, leafIndex uint64) Nullifier {
+	h := sha256.New()
+	h.Write([]byte("EasyCash/Nullifier/v1"))
+	h.Write(nk[:])
+	h.Write(c.Bytes())
+	h.Write(binary.BigEndian.AppendUint64(nil, leafIndex))
+	var n Nullifier
+	copy(n[:], h.Sum(nil))
+	return n
+}
+
+// OutputBlinding derives the blinding factor of the output note paying amount to
+// recipient from the notes identified by spend. Retrying the same spend yields the same
+// commitment, so its proof can be reused, while the blinding stays secret.
+func (nk NullifierKey) OutputBlinding(spend Nullifier, amount uint64, recipient string) Blinding {
+	h := sha256.New()
+	h.Write([]byte("EasyCash/OutputBlinding/v1"))
+	h.Write(nk[:])
+	h.Write(spend[:])
+	h.Write(binary.BigEndian.AppendUint64(nil, amount))
+	h.Write([]byte(recipient))
+	var s scalar
+	s.SetByteSlice(h.Sum(nil))
+	return Blinding(s.Bytes())
+}
+
+// Note is a shielded amount of an asset owned by the wallet. The amount and blinding
+// factor open the commitment stored at LeafIndex of the note commitment tree.
+type Note struct {
+	Asset      string      `json:"asset"`
+	Amount     uint64      `json:"amount"` // base units, see AmountDecimals
+	Blinding   Blinding    `json:"blinding"`
+	Commitment *Commitment `json:"commitment"`
+	LeafIndex  uint64      `json:"leaf_index"`
+	Nullifier  Nullifier   `json:"nullifier"`
+	Spent      bool        `json:"spent"`
+}
+
+// NewNote creates a note for amount with a fresh blinding factor; it gets its leaf index
+// once the commitment is added to the tree
+func NewNote(asset string, amount uint64) (*Note, error) {
+	c, blinding, err := CommitRandom(amount)
+	if err != nil {
+		return nil, err
+	}
+	return &Note{Asset: asset, Amount: amount, Blinding: blinding, Commitment: c}, nil
+}
+
+// NewNoteWithBlinding creates a note for amount committed with blinding
+func NewNoteWithBlinding(asset string, amount uint64, blinding Blinding) *Note {
+	return &Note{Asset: asset, Amount: amount, Blinding: blinding, Commitment: Commit(amount, blinding)}
+}
@@ -0,0 +1,105 @@
+package zk
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrDoubleSpend is returned when a nullifier has already been spent or is being spent
+var ErrDoubleSpend = errors.New("note already spent")
+
+// ParseNullifier decodes a 0x-prefixed hex nullifier
+func ParseNullifier(s string) (Nullifier, error) {
+	var n Nullifier
+	err := n.UnmarshalText([]byte(s))
+	return n, err
+}
+
+// NullifierDigest binds a proof to the set of nullifiers a transaction spends.
+// A single nullifier is used as is.
+func NullifierDigest(nullifiers []Nullifier) Nullifier {
+	if len(nullifiers) == 1 {
+		return nullifiers[0]
+	}
+	sorted := append([]Nullifier(nil), nullifiers...)
+	sort.Slice(sorted, func(i, j int) bool { return string(sorted[i][:]) < string(sorted[j][:]) })
+
+	h := sha256.New()
+	h.Write([]byte("EasyCash/NullifierDigest/v1"))
+	for _, n := range sorted {
+		h.Write(n[:])
+	}
+	var digest Nullifier
+	copy(digest[:], h.Sum(nil))
+	return digest
+}
+
+// NullifierSet records spent nullifiers. Spends are reserved first and committed once the
+// transaction succeeds, so concurrent spends of the same note cannot both pass the check.
+// It is not safe for concurrent use; ShieldedState adds locking.
+type NullifierSet struct {
+	spent   map[Nullifier]struct{}
+	pending map[Nullifier]struct{}
+}
+
+func NewNullifierSet() *NullifierSet {
+	return &NullifierSet{
+		spent:   make(map[Nullifier]struct{}),
+		pending: make(map[Nullifier]struct{}),
+	}
+}
+
+// IsSpent reports whether the nullifier has been committed as spent
+func (s *NullifierSet) IsSpent(n Nullifier) bool {
+	_, ok := s.spent[n]
+	return ok
+}
+
+// Len returns the number of spent nullifiers
+func (s *NullifierSet) Len() int {
+	return len(s.spent)
+}
+
+// reserve marks nullifiers as pending; it fails without side effects if any is spent,
+// pending or repeated
+func (s *NullifierSet) reserve(nullifiers []Nullifier) error {
+	seen := make(map[Nullifier]struct{}, len(nullifiers))
+	for _, n := range nullifiers {
+		_, spent := s.spent[n]
+		_, pending := s.pending[n]
+		_, repeated := seen[n]
+		if spent || pending || repeated {
+			return fmt.Errorf("%w: %s", ErrDoubleSpend, n)
+		}
+		seen[n] = struct{}{}
+	}
+	for _, n := range nullifiers {
+		s.pending[n] = struct{}{}
+	}
+	return nil
+}
+
+func (s *NullifierSet) release(nullifiers []Nullifier) {
+	for _, n := range nullifiers {
+		delete(s.pending, n)
+	}
+}
+
+func (s *NullifierSet) commit(nullifiers []Nullifier) {
+	for _, n := range nullifiers {
+		delete(s.pending, n)
+		s.spent[n] = struct{}{}
+	}
+}
+
+// sorted returns the spent nullifiers in a stable order for snapshots
+func (s *NullifierSet) sorted() []Nullifier {
+	out := make([]Nullifier, 0, len(s.spent))
+	for n := range s.spent {
+		out = append(out, n)
+	}
+	sort.Slice(out, func(i, j int) bool { return string(out[i][:]) < string(out[j][:]) })
+	return out
+}
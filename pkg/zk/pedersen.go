@@ -0,0 +1,166 @@
+package zk
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Pedersen commitments C = v*G + r*H hide an amount v behind a random blinding factor r
+// and bind the committer to it. H is derived by hashing so nobody knows log_G(H).
+// Commitments are additively homomorphic: C(v1, r1) + C(v2, r2) = C(v1+v2, r1+r2).
+
+// pedersenH is the blinding generator
+var pedersenH = hashToPoint("EasyCash/Pedersen/H")
+
+// Blinding is a commitment blinding factor, a big-endian scalar modulo the group order
+type Blinding [32]byte
+
+// NewBlinding returns a uniformly random blinding factor
+func NewBlinding() (Blinding, error) {
+	s, err := randomScalar()
+	if err != nil {
+		return Blinding{}, err
+	}
+	return s.Bytes(), nil
+}
+
+func (b Blinding) scalar() scalar {
+	var s scalar
+	s.SetBytes((*[32]byte)(&b))
+	return s
+}
+
+// Add returns b + other, the blinding factor of the sum of two commitments
+func (b Blinding) Add(other Blinding) Blinding {
+	s, o := b.scalar(), other.scalar()
+	return s.Add(&o).Bytes()
+}
+
+// Sub returns b - other, the blinding factor of the difference of two commitments
+func (b Blinding) Sub(other Blinding) Blinding {
+	s, o := b.scalar(), other.scalar()
+	o.Negate()
+	return s.Add(&o).Bytes()
+}
+
+// MarshalText encodes the blinding factor as 0x-prefixed hex
+func (b Blinding) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(b[:])), nil
+}
+
+// UnmarshalText decodes a blinding factor from 0x-prefixed hex
+func (b *Blinding) UnmarshalText(text []byte) error {
+	raw, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil || len(raw) != len(b) {
+		return fmt.Errorf("invalid blinding factor encoding")
+	}
+	copy(b[:], raw)
+	return nil
+}
+
+// Commitment is a Pedersen commitment to an amount
+type Commitment struct {
+	p point
+}
+
+// Commit commits to value with the given blinding factor
+func Commit(value uint64, blinding Blinding) *Commitment {
+	v, r := scalarFromUint64(value), blinding.scalar()
+	return commitScalar(&v, &r)
+}
+
+// CommitRandom commits to value with a fresh blinding factor and returns both
+func CommitRandom(value uint64) (*Commitment, Blinding, error) {
+	blinding, err := NewBlinding()
+	if err != nil {
+		return nil, Blinding{}, err
+	}
+	return Commit(value, blinding), blinding, nil
+}
+
+// commitScalar returns v*G + r*H
+func commitScalar(v, r *scalar) *Commitment {
+	vG := baseMul(v)
+	rH := mul(r, &pedersenH)
+	return &Commitment{p: add(&vG, &rH)}
+}
+
+// Verify checks that the commitment opens to value with blinding
+func (c *Commitment) Verify(value uint64, blinding Blinding) bool {
+	return c.Equal(Commit(value, blinding))
+}
+
+// Add returns the commitment to the sum of both committed amounts
+func (c *Commitment) Add(other *Commitment) *Commitment {
+	return &Commitment{p: add(&c.p, &other.p)}
+}
+
+// Sub returns the commitment to the difference of both committed amounts
+func (c *Commitment) Sub(other *Commitment) *Commitment {
+	return &Commitment{p: sub(&c.p, &other.p)}
+}
+
+// SumCommitments returns the commitment to the total of the committed amounts
+func SumCommitments(commitments []*Commitment) (*Commitment, error) {
+	if len(commitments) == 0 {
+		return nil, fmt.Errorf("no commitments to sum")
+	}
+	sum := &Commitment{p: commitments[0].p}
+	for _, c := range commitments[1:] {
+		sum = sum.Add(c)
+	}
+	return sum, nil
+}
+
+// Equal reports whether two commitments are the same group element
+func (c *Commitment) Equal(other *Commitment) bool {
+	return pointsEqual(&c.p, &other.p)
+}
+
+// Bytes returns the 33-byte compressed encoding of the commitment
+func (c *Commitment) Bytes() []byte {
+	return encodePoint(&c.p)
+}
+
+// String returns the 0x-prefixed hex encoding of the commitment
+func (c *Commitment) String() string {
+	return "0x" + hex.EncodeToString(c.Bytes())
+}
+
+// ParseCommitmentString decodes a 0x-prefixed hex commitment
+func ParseCommitmentString(s string) (*Commitment, error) {
+	c := new(Commitment)
+	if err := c.UnmarshalText([]byte(s)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ParseCommitment decodes a commitment from its 33-byte encoding
+func ParseCommitment(b []byte) (*Commitment, error) {
+	p, err := decodePoint(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commitment: %w", err)
+	}
+	return &Commitment{p: p}, nil
+}
+
+// MarshalText encodes the commitment as 0x-prefixed hex
+func (c *Commitment) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText decodes a commitment from 0x-prefixed hex
+func (c *Commitment) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid commitment encoding: %w", err)
+	}
+	parsed, err := ParseCommitment(b)
+	if err != nil {
+		return err
+	}
+	*c = *parsed
+	return nil
+}
@@ -0,0 +1,64 @@
+package zk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitmentOpening(t *testing.T) {
+	c, r, err := CommitRandom(1000)
+	require.NoError(t, err)
+	assert.True(t, c.Verify(1000, r))
+	assert.False(t, c.Verify(1001, r))
+
+	other, err := NewBlinding()
+	require.NoError(t, err)
+	assert.False(t, c.Verify(1000, other))
+
+	// The same amount under different blinding factors gives unrelated commitments
+	c2, _, err := CommitRandom(1000)
+	require.NoError(t, err)
+	assert.False(t, c.Equal(c2))
+}
+
+func TestCommitmentHomomorphism(t *testing.T) {
+	a, ra, err := CommitRandom(700)
+	require.NoError(t, err)
+	b, rb, err := CommitRandom(300)
+	require.NoError(t, err)
+
+	assert.True(t, a.Add(b).Verify(1000, ra.Add(rb)))
+	assert.True(t, a.Sub(b).Verify(400, ra.Sub(rb)))
+
+	// Subtracting a commitment from itself yields the identity, which still round trips
+	zero := a.Sub(a)
+	assert.True(t, zero.Verify(0, Blinding{}))
+	parsed, err := ParseCommitment(zero.Bytes())
+	require.NoError(t, err)
+	assert.True(t, parsed.Equal(zero))
+}
+
+func TestCommitmentEncoding(t *testing.T) {
+	c, _, err := CommitRandom(42)
+	require.NoError(t, err)
+
+	parsed, err := ParseCommitment(c.Bytes())
+	require.NoError(t, err)
+	assert.True(t, parsed.Equal(c))
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	var decoded Commitment
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, decoded.Equal(c))
+
+	_, err = ParseCommitment(c.Bytes()[:32])
+	assert.Error(t, err)
+	bad := c.Bytes()
+	bad[0] = 0x05
+	_, err = ParseCommitment(bad)
+	assert.Error(t, err)
+}
@@ -0,0 +1,237 @@
+package zk
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/useeasycash/ecash-sdk-core/pkg/monitoring"
+)
+
+var (
+	ErrPoolClosed = errors.New("prover pool is closed")
+	ErrQueueFull  = errors.New("prover queue is full")
+)
+
+// Priority orders queued proof jobs; higher priorities are proven first
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+// ProverPoolConfig bounds a ProverPool
+type ProverPoolConfig struct {
+	Workers  int                 // concurrent provers; each holds one witness and proof in memory
+	MaxQueue int                 // jobs waiting for a worker; 0 means unbounded
+	Metrics  *monitoring.Metrics // receives queue depth and proving times; may be nil
+}
+
+// ProofJob is a solvency statement to prove
+type ProofJob struct {
+	Priority Priority
+	Witness  SolvencyWitness
+	Inputs   PublicInputs
+}
+
+// ProofTask is a submitted job; Wait blocks until it is proven, failed or cancelled
+type ProofTask struct {
+	ctx   context.Context
+	job   ProofJob
+	seq   uint64
+	done  chan struct{}
+	proof string
+	err   error
+}
+
+// Wait returns the proof, or the error that ended the job
+func (t *ProofTask) Wait() (string, error) {
+	<-t.done
+	return t.proof, t.err
+}
+
+// Done is closed when the job has finished
+func (t *ProofTask) Done() <-chan struct{} {
+	return t.done
+}
+
+func (t *ProofTask) finish(proof string, err error) {
+	t.proof, t.err = proof, err
+	close(t.done)
+}
+
+// ProverPool proves jobs on a fixed number of workers, highest priority first and in
+// submission order within a priority. Jobs whose context ends while queued are dropped
+// without proving.
+type ProverPool struct {
+	pg      *ProofGenerator
+	cfg     ProverPoolConfig
+	metrics *monitoring.Metrics
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  taskQueue
+	seq    uint64
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewProverPool starts cfg.Workers workers proving with pg
+func NewProverPool(pg *ProofGenerator, cfg ProverPoolConfig) *ProverPool {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	p := &ProverPool{pg: pg, cfg: cfg, metrics: cfg.Metrics}
+	p.cond = sync.NewCond(&p.mu)
+
+	p.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// Submit queues a job. The returned task fails with ctx's error if ctx ends first.
+func (p *ProverPool) Submit(ctx context.Context, job ProofJob) (*ProofTask, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+	if p.cfg.MaxQueue > 0 && len(p.queue) >= p.cfg.MaxQueue {
+		return nil, ErrQueueFull
+	}
+
+	p.seq++
+	task := &ProofTask{ctx: ctx, job: job, seq: p.seq, done: make(chan struct{})}
+	heap.Push(&p.queue, task)
+	p.reportDepth()
+	p.cond.Signal()
+
+	// Wake the queue when the job is cancelled so it does not wait for a worker
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.mu.Lock()
+			p.removeLocked(task)
+			p.mu.Unlock()
+		case <-task.done:
+		}
+	}()
+	return task, nil
+}
+
+// Prove submits a job and waits for its proof
+func (p *ProverPool) Prove(ctx context.Context, job ProofJob) (string, error) {
+	task, err := p.Submit(ctx, job)
+	if err != nil {
+		return "", err
+	}
+	return task.Wait()
+}
+
+// QueueDepth returns the number of jobs waiting for a worker
+func (p *ProverPool) QueueDepth() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queue)
+}
+
+// Close stops the workers after their current jobs and fails all queued jobs
+func (p *ProverPool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	for len(p.queue) > 0 {
+		heap.Pop(&p.queue).(*ProofTask).finish("", ErrPoolClosed)
+	}
+	p.reportDepth()
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+func (p *ProverPool) worker() {
+	defer p.wg.Done()
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if p.closed {
+			p.mu.Unlock()
+			return
+		}
+		task := heap.Pop(&p.queue).(*ProofTask)
+		p.reportDepth()
+		p.mu.Unlock()
+
+		if err := task.ctx.Err(); err != nil {
+			task.finish("", err)
+			continue
+		}
+
+		start := time.Now()
+		proof, cached, err := p.pg.proveSolvency(task.ctx, task.job.Witness, task.job.Inputs)
+		if p.metrics != nil {
+			if cached {
+				p.metrics.RecordProofCacheHit()
+			} else {
+				p.metrics.RecordProof(err == nil, time.Since(start))
+			}
+		}
+		task.finish(proof, err)
+	}
+}
+
+// removeLocked drops a cancelled task that is still queued; p.mu must be held
+func (p *ProverPool) removeLocked(task *ProofTask) {
+	for i, queued := range p.queue {
+		if queued == task {
+			heap.Remove(&p.queue, i)
+			task.finish("", task.ctx.Err())
+			p.reportDepth()
+			return
+		}
+	}
+}
+
+// reportDepth publishes the queue depth; p.mu must be held
+func (p *ProverPool) reportDepth() {
+	if p.metrics != nil {
+		p.metrics.RecordProofQueueDepth(len(p.queue))
+	}
+}
+
+// taskQueue is a max-heap on priority, FIFO within a priority
+type taskQueue []*ProofTask
+
+func (q taskQueue) Len() int { return len(q) }
+
+func (q taskQueue) Less(i, j int) bool {
+	if q[i].job.Priority != q[j].job.Priority {
+		return q[i].job.Priority > q[j].job.Priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q taskQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *taskQueue) Push(x any) { *q = append(*q, x.(*ProofTask)) }
+
+func (q *taskQueue) Pop() any {
+	old := *q
+	task := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return task
+}
@@ -0,0 +1,160 @@
+package zk
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/useeasycash/ecash-sdk-core/pkg/monitoring"
+)
+
+// gatedProver records the order of proved jobs and blocks each one until released
+type gatedProver struct {
+	*ReferenceProver
+	release chan struct{}
+
+	mu    sync.Mutex
+	order []string
+}
+
+func (p *gatedProver) ProveSolvency(ctx context.Context, witness SolvencyWitness, inputs PublicInputs) ([]byte, error) {
+	p.mu.Lock()
+	p.order = append(p.order, inputs.Recipient)
+	p.mu.Unlock()
+
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return p.ReferenceProver.ProveSolvency(ctx, witness, inputs)
+}
+
+func newGatedPool(t *testing.T, cfg ProverPoolConfig) (*ProverPool, *gatedProver) {
+	prover := &gatedProver{ReferenceProver: NewReferenceProver(NewVerificationKey(SolvencyCircuitID)), release: make(chan struct{})}
+	pool := NewProverPool(NewProofGenerator(prover), cfg)
+	t.Cleanup(pool.Close)
+	return pool, prover
+}
+
+func testProofJob(priority Priority, recipient string) ProofJob {
+	blinding := Blinding{1}
+	return ProofJob{
+		Priority: priority,
+		Witness:  SolvencyWitness{Balance: 10, Amount: 5, AmountBlinding: blinding},
+		Inputs:   PublicInputs{AmountCommitment: Commit(5, blinding), Recipient: recipient},
+	}
+}
+
+func TestProverPoolPriorities(t *testing.T) {
+	metrics := &monitoring.Metrics{}
+	pool, prover := newGatedPool(t, ProverPoolConfig{Workers: 1, Metrics: metrics})
+	ctx := context.Background()
+
+	// The first job occupies the only worker while the rest queue up
+	first, err := pool.Submit(ctx, testProofJob(PriorityNormal, "first"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return pool.QueueDepth() == 0 }, time.Second, time.Millisecond)
+
+	var tasks []*ProofTask
+	for _, job := range []ProofJob{
+		testProofJob(PriorityLow, "low"),
+		testProofJob(PriorityNormal, "normal-1"),
+		testProofJob(PriorityHigh, "high"),
+		testProofJob(PriorityNormal, "normal-2"),
+	} {
+		task, err := pool.Submit(ctx, job)
+		require.NoError(t, err)
+		tasks = append(tasks, task)
+	}
+	assert.Equal(t, 4, pool.QueueDepth())
+	assert.Equal(t, int64(4), metrics.GetStats()["proof_queue_depth"])
+
+	close(prover.release)
+	for _, task := range append(tasks, first) {
+		proof, err := task.Wait()
+		require.NoError(t, err)
+		assert.NotEmpty(t, proof)
+	}
+
+	assert.Equal(t, []string{"first", "high", "normal-1", "normal-2", "low"}, prover.order)
+	stats := metrics.GetStats()
+	assert.Equal(t, int64(5), stats["proofs_generated"])
+	assert.Equal(t, int64(0), stats["proof_queue_depth"])
+	assert.Equal(t, int64(4), stats["max_proof_queue_depth"])
+}
+
+func TestProverPoolCancellation(t *testing.T) {
+	pool, prover := newGatedPool(t, ProverPoolConfig{Workers: 1, MaxQueue: 1})
+
+	running, err := pool.Submit(context.Background(), testProofJob(PriorityNormal, "running"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return pool.QueueDepth() == 0 }, time.Second, time.Millisecond)
+
+	// A queued job whose context ends is dropped without reaching the prover
+	ctx, cancel := context.WithCancel(context.Background())
+	queued, err := pool.Submit(ctx, testProofJob(PriorityHigh, "cancelled"))
+	require.NoError(t, err)
+
+	_, err = pool.Submit(context.Background(), testProofJob(PriorityHigh, "overflow"))
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	cancel()
+	_, err = queued.Wait()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, pool.QueueDepth())
+
+	close(prover.release)
+	_, err = running.Wait()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"running"}, prover.order)
+}
+
+func TestProverPoolClose(t *testing.T) {
+	pool, prover := newGatedPool(t, ProverPoolConfig{Workers: 1})
+
+	running, err := pool.Submit(context.Background(), testProofJob(PriorityNormal, "running"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return pool.QueueDepth() == 0 }, time.Second, time.Millisecond)
+	queued, err := pool.Submit(context.Background(), testProofJob(PriorityNormal, "queued"))
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(prover.release)
+	}()
+	pool.Close()
+
+	_, err = queued.Wait()
+	assert.ErrorIs(t, err, ErrPoolClosed)
+	_, err = running.Wait()
+	assert.NoError(t, err)
+
+	_, err = pool.Submit(context.Background(), testProofJob(PriorityNormal, "late"))
+	assert.ErrorIs(t, err, ErrPoolClosed)
+}
+
+func TestProverPoolCountsCacheHitsSeparately(t *testing.T) {
+	metrics := &monitoring.Metrics{}
+	prover := &countingProver{ReferenceProver: NewReferenceProver(NewVerificationKey(SolvencyCircuitID))}
+	pg := NewProofGenerator(prover)
+	pg.SetCache(NewProofCache(time.Minute))
+	pool := NewProverPool(pg, ProverPoolConfig{Workers: 1, Metrics: metrics})
+	t.Cleanup(pool.Close)
+
+	for i := 0; i < 3; i++ {
+		task, err := pool.Submit(context.Background(), testProofJob(PriorityNormal, "same"))
+		require.NoError(t, err)
+		_, err = task.Wait()
+		require.NoError(t, err)
+	}
+
+	// Only the first job reached the prover
+	stats := metrics.GetStats()
+	assert.Equal(t, 1, prover.calls)
+	assert.Equal(t, int64(1), stats["proofs_generated"])
+	assert.Equal(t, int64(2), stats["proof_cache_hits"])
+}
@@ -1,39 +1,245 @@
 package zk
 
 import (
-	"crypto/sha256"
+	"bytes"
+	"context"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"strings"
 )
 
 // ProofGenerator handles the creation of Zero-Knowledge proofs for transactions.
-// In a real implementation, this would interface with Rust/Circom bindings.
+// Proving is delegated to a Prover backend; verification only needs its key.
 type ProofGenerator struct {
-	// configuration for circuit keys
-	CircuitPath string
+	prover Prover
+	cache  *ProofCache
 }
 
-func NewProofGenerator(circuitPath string) *ProofGenerator {
+func NewProofGenerator(prover Prover) *ProofGenerator {
 	return &ProofGenerator{
-		CircuitPath: circuitPath,
+		prover: prover,
 	}
 }
 
-// GenerateSolvencyProof simulates the generation of a ZK proof for a shielded balance.
-// It returns a hex-encoded proof string.
-func (pg *ProofGenerator) GenerateSolvencyProof(balance string, required string) (string, error) {
-	// Mock logic: In reality, this involves complex polynomial arithmetic.
-	// We simulate "work" by hashing the inputs.
-	input := fmt.Sprintf("%s-%s-%s", balance, required, pg.CircuitPath)
-	hash := sha256.Sum256([]byte(input))
+// Prover returns the backend proofs are generated with
+func (pg *ProofGenerator) Prover() Prover {
+	return pg.prover
+}
+
+// SetCache enables reuse of proofs for identical statements; nil disables caching
+func (pg *ProofGenerator) SetCache(cache *ProofCache) {
+	pg.cache = cache
+}
+
+// Cache returns the proof cache, or nil when caching is disabled
+func (pg *ProofGenerator) Cache() *ProofCache {
+	return pg.cache
+}
+
+// solvencyRounds is the inner product argument length of a two-value range proof
+const solvencyRounds = 7
+
+// SolvencyWitness holds the private openings a solvency proof is built from
+type SolvencyWitness struct {
+	Balance         uint64   `json:"balance"`
+	BalanceBlinding Blinding `json:"balance_blinding"`
+	Amount          uint64   `json:"amount"`
+	AmountBlinding  Blinding `json:"amount_blinding"`
+}
+
+// SolvencyProof shows that a committed balance covers the committed amount in the
+// public inputs. It is an aggregated range proof that both balance and
+// balance - amount lie in [0, 2^RangeBits), so neither amount is revealed.
+type SolvencyProof struct {
+	KeyHash []byte
+	Balance *Commitment
+	Inputs  PublicInputs
+	Range   *RangeProof
+}
+
+// ProveSolvency proves balance >= amount, bound to vk and the public inputs
+func ProveSolvency(vk *VerificationKey, witness SolvencyWitness, inputs PublicInputs) (*SolvencyProof, error) {
+	if witness.Balance < witness.Amount {
+		return nil, fmt.Errorf("balance does not cover required amount")
+	}
+	if inputs.AmountCommitment == nil || !inputs.AmountCommitment.Verify(witness.Amount, witness.AmountBlinding) {
+		return nil, fmt.Errorf("amount commitment does not open to the witness amount")
+	}
+	context, err := proofContext(vk, &inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeProof, _, err := proveRange(context,
+		[]uint64{witness.Balance, witness.Balance - witness.Amount},
+		[]Blinding{witness.BalanceBlinding, witness.BalanceBlinding.Sub(witness.AmountBlinding)},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &SolvencyProof{
+		KeyHash: vk.Hash(),
+		Balance: Commit(witness.Balance, witness.BalanceBlinding),
+		Inputs:  inputs,
+		Range:   rangeProof,
+	}, nil
+}
+
+// Verify checks the proof under vk against the expected public inputs. Failures are
+// reported as *VerificationError.
+func (p *SolvencyProof) Verify(vk *VerificationKey, expected PublicInputs) error {
+	if len(p.Range.L) != solvencyRounds {
+		return verificationError(FailureMalformed, "range proof has %d rounds, expected %d", len(p.Range.L), solvencyRounds)
+	}
+	if !bytes.Equal(p.KeyHash, vk.Hash()) {
+		return verificationError(FailureKeyMismatch, "proof was created for key %x, verifying with %x", p.KeyHash, vk.Hash())
+	}
+	if !p.Inputs.equal(&expected) {
+		return &VerificationError{Reason: FailureInputMismatch, Err: inputMismatch(&p.Inputs, &expected)}
+	}
+	context, err := proofContext(vk, &expected)
+	if err != nil {
+		return &VerificationError{Reason: FailureInputMismatch, Err: err}
+	}
+	if err := p.Range.verify(context, []*Commitment{p.Balance, p.Balance.Sub(expected.AmountCommitment)}); err != nil {
+		return &VerificationError{Reason: FailureInvalidProof, Err: err}
+	}
+	return nil
+}
+
+// VerifySpentNotes checks that the proof's balance commitment is the sum of the spent
+// notes' commitments, i.e. that the balance proven solvent is what those notes hold.
+// Failures are reported as *VerificationError.
+func (p *SolvencyProof) VerifySpentNotes(spent []*Commitment) error {
+	sum, err := SumCommitments(spent)
+	if err != nil {
+		return &VerificationError{Reason: FailureInputMismatch, Err: err}
+	}
+	if !p.Balance.Equal(sum) {
+		return verificationError(FailureInputMismatch, "balance commitment is not the sum of the %d spent notes", len(spent))
+	}
+	return nil
+}
+
+// inputMismatch names the first public input that differs
+func inputMismatch(got, expected *PublicInputs) error {
+	switch {
+	case got.AmountCommitment == nil || expected.AmountCommitment == nil || !got.AmountCommitment.Equal(expected.AmountCommitment):
+		return fmt.Errorf("amount commitment differs")
+	case got.Nullifier != expected.Nullifier:
+		return fmt.Errorf("nullifier %s, expected %s", got.Nullifier, expected.Nullifier)
+	default:
+		return fmt.Errorf("recipient %q, expected %q", got.Recipient, expected.Recipient)
+	}
+}
 
-	// Simulate the '0x' prefixed proof data
-	proof := "0x" + hex.EncodeToString(hash[:])
-	return proof, nil
+// Bytes encodes the proof as key hash, balance commitment, public inputs and range proof
+func (p *SolvencyProof) Bytes() []byte {
+	out := append([]byte(nil), p.KeyHash...)
+	out = append(out, p.Balance.Bytes()...)
+	inputs, _ := p.Inputs.encode()
+	out = append(out, inputs...)
+	return append(out, p.Range.Bytes()...)
 }
 
-// VerifyProof verifies a ZK proof off-chain.
-func (pg *ProofGenerator) VerifyProof(proof string) bool {
-	// Always return true for mock purposes
-	return len(proof) > 10
+// ParseSolvencyProof decodes a proof produced by Bytes, checking its structure.
+// Failures are reported as *VerificationError with FailureMalformed.
+func ParseSolvencyProof(b []byte) (*SolvencyProof, error) {
+	const header = 32 + pointSize + pointSize + 32 + 2
+	if len(b) < header {
+		return nil, verificationError(FailureMalformed, "proof is %d bytes, shorter than its %d byte header", len(b), header)
+	}
+
+	p := &SolvencyProof{KeyHash: append([]byte(nil), b[:32]...)}
+	b = b[32:]
+	var err error
+	if p.Balance, err = ParseCommitment(b[:pointSize]); err != nil {
+		return nil, &VerificationError{Reason: FailureMalformed, Err: fmt.Errorf("balance: %w", err)}
+	}
+	b = b[pointSize:]
+	if p.Inputs.AmountCommitment, err = ParseCommitment(b[:pointSize]); err != nil {
+		return nil, &VerificationError{Reason: FailureMalformed, Err: fmt.Errorf("amount: %w", err)}
+	}
+	b = b[pointSize:]
+	copy(p.Inputs.Nullifier[:], b[:32])
+	recipientLen := int(binary.BigEndian.Uint16(b[32:34]))
+	b = b[34:]
+	if len(b) < recipientLen {
+		return nil, verificationError(FailureMalformed, "truncated recipient")
+	}
+	p.Inputs.Recipient = string(b[:recipientLen])
+
+	if p.Range, err = ParseRangeProof(b[recipientLen:]); err != nil {
+		return nil, &VerificationError{Reason: FailureMalformed, Err: err}
+	}
+	return p, nil
+}
+
+// GenerateSolvencyProof proves that balance covers required without revealing either.
+// It fills in inputs.AmountCommitment with a fresh commitment to required and returns
+// a hex-encoded proof string bound to inputs.
+func (pg *ProofGenerator) GenerateSolvencyProof(ctx context.Context, balance string, required string, inputs *PublicInputs) (string, error) {
+	balanceUnits, err := ParseAmount(balance)
+	if err != nil {
+		return "", fmt.Errorf("invalid balance: %w", err)
+	}
+	requiredUnits, err := ParseAmount(required)
+	if err != nil {
+		return "", fmt.Errorf("invalid required amount: %w", err)
+	}
+
+	witness := SolvencyWitness{Balance: balanceUnits, Amount: requiredUnits}
+	if witness.BalanceBlinding, err = NewBlinding(); err != nil {
+		return "", err
+	}
+	if witness.AmountBlinding, err = NewBlinding(); err != nil {
+		return "", err
+	}
+	inputs.AmountCommitment = Commit(requiredUnits, witness.AmountBlinding)
+
+	return pg.ProveSolvency(ctx, witness, *inputs)
+}
+
+// ProveSolvency proves a witness the caller built, e.g. when the amount commitment is
+// an output note whose blinding factor must be known. It returns a hex-encoded proof.
+// A cached proof of the same statement is returned when one is available.
+func (pg *ProofGenerator) ProveSolvency(ctx context.Context, witness SolvencyWitness, inputs PublicInputs) (string, error) {
+	proof, _, err := pg.proveSolvency(ctx, witness, inputs)
+	return proof, err
+}
+
+// proveSolvency is ProveSolvency that also reports whether the proof came from the cache
+// rather than the prover
+func (pg *ProofGenerator) proveSolvency(ctx context.Context, witness SolvencyWitness, inputs PublicInputs) (string, bool, error) {
+	vk := pg.prover.VerificationKey()
+	if pg.cache != nil {
+		if proof, ok := pg.cache.Get(vk, inputs); ok {
+			return proof, true, nil
+		}
+	}
+
+	raw, err := pg.prover.ProveSolvency(ctx, witness, inputs)
+	if err != nil {
+		return "", false, err
+	}
+	proof := "0x" + hex.EncodeToString(raw)
+	if pg.cache != nil {
+		pg.cache.Put(vk, inputs, proof)
+	}
+	return proof, false, nil
+}
+
+// VerifyProof verifies a hex-encoded solvency proof off-chain against the expected
+// public inputs. Failures are reported as *VerificationError.
+func (pg *ProofGenerator) VerifyProof(proof string, inputs PublicInputs) error {
+	data, err := hex.DecodeString(strings.TrimPrefix(proof, "0x"))
+	if err != nil {
+		return &VerificationError{Reason: FailureMalformed, Err: fmt.Errorf("invalid hex: %w", err)}
+	}
+	parsed, err := ParseSolvencyProof(data)
+	if err != nil {
+		return err
+	}
+	return parsed.Verify(pg.prover.VerificationKey(), inputs)
 }
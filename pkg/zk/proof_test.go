@@ -0,0 +1,151 @@
+package zk
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAmount(t *testing.T) {
+	for amount, units := range map[string]uint64{"0": 0, "1": 1_000_000, "1000.00": 1_000_000_000, "0.000001": 1, "12.5": 12_500_000} {
+		got, err := ParseAmount(amount)
+		require.NoError(t, err, amount)
+		assert.Equal(t, units, got, amount)
+	}
+	assert.Equal(t, "12.5", FormatAmount(12_500_000))
+	assert.Equal(t, "0.000001", FormatAmount(1))
+	assert.Equal(t, "3", FormatAmount(3_000_000))
+
+	for _, amount := range []string{"", ".5", "-1", "+1", "1.0000001", "abc", "99999999999999999999"} {
+		_, err := ParseAmount(amount)
+		assert.Error(t, err, amount)
+	}
+}
+
+// requireFailure asserts err is a *VerificationError with the given reason
+func requireFailure(t *testing.T, err error, reason VerificationFailure) {
+	t.Helper()
+	var verr *VerificationError
+	require.True(t, errors.As(err, &verr), "expected *VerificationError, got %v", err)
+	assert.Equal(t, reason, verr.Reason, verr.Error())
+}
+
+func TestSolvencyProof(t *testing.T) {
+	pg := NewProofGenerator(NewReferenceProver(NewVerificationKey(SolvencyCircuitID)))
+	ctx := context.Background()
+	inputs := &PublicInputs{Nullifier: Nullifier{1, 2, 3}, Recipient: "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"}
+
+	proof, err := pg.GenerateSolvencyProof(ctx, "1500.25", "1000", inputs)
+	require.NoError(t, err)
+	require.NotNil(t, inputs.AmountCommitment)
+	assert.True(t, strings.HasPrefix(proof, "0x"))
+	assert.NoError(t, pg.VerifyProof(proof, *inputs))
+
+	// Exact balance is solvent
+	exact := &PublicInputs{}
+	exactProof, err := pg.GenerateSolvencyProof(ctx, "1000", "1000", exact)
+	require.NoError(t, err)
+	assert.NoError(t, pg.VerifyProof(exactProof, *exact))
+
+	_, err = pg.GenerateSolvencyProof(ctx, "999.99", "1000", &PublicInputs{})
+	assert.Error(t, err)
+
+	t.Run("public inputs", func(t *testing.T) {
+		other := *inputs
+		other.Recipient = "0x0000000000000000000000000000000000000001"
+		requireFailure(t, pg.VerifyProof(proof, other), FailureInputMismatch)
+
+		other = *inputs
+		other.Nullifier = Nullifier{9}
+		requireFailure(t, pg.VerifyProof(proof, other), FailureInputMismatch)
+
+		other = *inputs
+		other.AmountCommitment = exact.AmountCommitment
+		requireFailure(t, pg.VerifyProof(proof, other), FailureInputMismatch)
+	})
+
+	t.Run("verification key", func(t *testing.T) {
+		otherKey := NewProofGenerator(NewReferenceProver(NewVerificationKey("easycash/other/v1")))
+		requireFailure(t, otherKey.VerifyProof(proof, *inputs), FailureKeyMismatch)
+	})
+
+	t.Run("structure", func(t *testing.T) {
+		requireFailure(t, pg.VerifyProof("0x0123456789abcdef", *inputs), FailureMalformed)
+		requireFailure(t, pg.VerifyProof("not hex", *inputs), FailureMalformed)
+		requireFailure(t, pg.VerifyProof(proof[:len(proof)-2], *inputs), FailureMalformed)
+	})
+
+	t.Run("tampering", func(t *testing.T) {
+		parsed, err := ParseSolvencyProof(mustDecodeHex(t, proof))
+		require.NoError(t, err)
+
+		// Rewriting the embedded inputs to match another verifier's expectation breaks the transcript
+		other := *inputs
+		other.Recipient = "attacker"
+		parsed.Inputs = other
+		err = parsed.Verify(pg.Prover().VerificationKey(), other)
+		requireFailure(t, err, FailureInvalidProof)
+		assert.ErrorIs(t, err, ErrInvalidRangeProof)
+	})
+}
+
+func TestProveSolvencyRejectsBadWitness(t *testing.T) {
+	rb, err := NewBlinding()
+	require.NoError(t, err)
+	ra, err := NewBlinding()
+	require.NoError(t, err)
+	vk := NewVerificationKey(SolvencyCircuitID)
+
+	inputs := PublicInputs{AmountCommitment: Commit(200, ra)}
+	_, err = ProveSolvency(vk, SolvencyWitness{Balance: 500, BalanceBlinding: rb, Amount: 300, AmountBlinding: ra}, inputs)
+	assert.Error(t, err)
+
+	proof, err := ProveSolvency(vk, SolvencyWitness{Balance: 500, BalanceBlinding: rb, Amount: 200, AmountBlinding: ra}, inputs)
+	require.NoError(t, err)
+	assert.NoError(t, proof.Verify(vk, inputs))
+	assert.True(t, proof.Balance.Verify(500, rb))
+}
+
+func TestSolvencyProofCoversSpentNotes(t *testing.T) {
+	w := newTestWallet(t)
+	state := NewShieldedState()
+	for _, amount := range []uint64{50, 100, 400} {
+		addTestNote(t, w, state, "USDC", amount)
+	}
+	selection, err := w.SelectNotes("USDC", 420)
+	require.NoError(t, err)
+	require.Len(t, selection.Notes, 2)
+
+	ra, err := NewBlinding()
+	require.NoError(t, err)
+	vk := NewVerificationKey(SolvencyCircuitID)
+	inputs := PublicInputs{AmountCommitment: Commit(420, ra)}
+	witness := SolvencyWitness{Balance: selection.Total, BalanceBlinding: selection.BalanceBlinding(), Amount: 420, AmountBlinding: ra}
+	proof, err := ProveSolvency(vk, witness, inputs)
+	require.NoError(t, err)
+	require.NoError(t, proof.Verify(vk, inputs))
+	assert.NoError(t, proof.VerifySpentNotes(selection.Commitments()))
+
+	// The balance matches neither a subset of the notes nor no notes
+	requireFailure(t, proof.VerifySpentNotes(selection.Commitments()[:1]), FailureInputMismatch)
+	requireFailure(t, proof.VerifySpentNotes(nil), FailureInputMismatch)
+
+	// Nor does a balance committed under an unrelated blinding factor
+	witness.BalanceBlinding, err = NewBlinding()
+	require.NoError(t, err)
+	unlinked, err := ProveSolvency(vk, witness, inputs)
+	require.NoError(t, err)
+	requireFailure(t, unlinked.VerifySpentNotes(selection.Commitments()), FailureInputMismatch)
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	require.NoError(t, err)
+	return b
+}
@@ -0,0 +1,126 @@
+package zk
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// ProofCacheStats reports how often cached proofs were reused
+type ProofCacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Invalidations uint64 // entries dropped because the note tree root changed
+	Entries       int
+}
+
+// proofCacheEntry is a proof and the time it stops being reused
+type proofCacheEntry struct {
+	proof   string
+	expires time.Time
+}
+
+// ProofCache reuses proofs for identical statements within a TTL. Entries are keyed
+// by circuit, verification key and public inputs, and scoped to the note tree root
+// they were proven against: a root change drops every entry.
+type ProofCache struct {
+	ttl time.Duration
+
+	mu            sync.Mutex
+	root          MerkleHash
+	entries       map[[32]byte]*proofCacheEntry
+	hits          uint64
+	misses        uint64
+	invalidations uint64
+}
+
+// NewProofCache returns an empty cache whose entries live for ttl
+func NewProofCache(ttl time.Duration) *ProofCache {
+	return &ProofCache{
+		ttl:     ttl,
+		entries: make(map[[32]byte]*proofCacheEntry),
+	}
+}
+
+// proofCacheKey hashes the circuit, key version and public inputs of a statement
+func proofCacheKey(vk *VerificationKey, inputs PublicInputs) ([32]byte, bool) {
+	encoded, err := inputs.encode()
+	if err != nil {
+		return [32]byte{}, false
+	}
+	h := sha256.New()
+	h.Write([]byte("EasyCash/ProofCache/v1"))
+	h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(vk.CircuitID))))
+	h.Write([]byte(vk.CircuitID))
+	h.Write(vk.Hash())
+	h.Write(encoded)
+	var key [32]byte
+	copy(key[:], h.Sum(nil))
+	return key, true
+}
+
+// Get returns a live cached proof for the statement
+func (c *ProofCache) Get(vk *VerificationKey, inputs PublicInputs) (string, bool) {
+	key, ok := proofCacheKey(vk, inputs)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ok {
+		if entry, found := c.entries[key]; found {
+			if time.Now().Before(entry.expires) {
+				c.hits++
+				return entry.proof, true
+			}
+			delete(c.entries, key)
+		}
+	}
+	c.misses++
+	return "", false
+}
+
+// Put stores a proof for the statement and drops expired entries
+func (c *ProofCache) Put(vk *VerificationKey, inputs PublicInputs, proof string) {
+	key, ok := proofCacheKey(vk, inputs)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = &proofCacheEntry{proof: proof, expires: now.Add(c.ttl)}
+}
+
+// SetRoot records the current note tree root; a change invalidates all cached proofs
+func (c *ProofCache) SetRoot(root MerkleHash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if root == c.root {
+		return
+	}
+	c.root = root
+	c.invalidations += uint64(len(c.entries))
+	c.entries = make(map[[32]byte]*proofCacheEntry)
+}
+
+// Stats returns the cache counters
+func (c *ProofCache) Stats() ProofCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return ProofCacheStats{
+		Hits:          c.hits,
+		Misses:        c.misses,
+		Invalidations: c.invalidations,
+		Entries:       len(c.entries),
+	}
+}
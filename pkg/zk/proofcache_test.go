@@ -0,0 +1,90 @@
+package zk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingProver counts how often the backend is asked for a proof
+type countingProver struct {
+	*ReferenceProver
+	calls int
+}
+
+func (p *countingProver) ProveSolvency(ctx context.Context, witness SolvencyWitness, inputs PublicInputs) ([]byte, error) {
+	p.calls++
+	return p.ReferenceProver.ProveSolvency(ctx, witness, inputs)
+}
+
+func TestProofCache(t *testing.T) {
+	prover := &countingProver{ReferenceProver: NewReferenceProver(NewVerificationKey(SolvencyCircuitID))}
+	pg := NewProofGenerator(prover)
+	cache := NewProofCache(time.Minute)
+	pg.SetCache(cache)
+	ctx := context.Background()
+
+	nk, err := NewNullifierKey()
+	require.NoError(t, err)
+	spend := Nullifier{7}
+	blinding := nk.OutputBlinding(spend, 400, "0xabc")
+	assert.Equal(t, blinding, nk.OutputBlinding(spend, 400, "0xabc"))
+	assert.NotEqual(t, blinding, nk.OutputBlinding(spend, 401, "0xabc"))
+
+	output := NewNoteWithBlinding("USDC", 400, blinding)
+	witness := SolvencyWitness{Balance: 1000, Amount: 400, AmountBlinding: blinding}
+	inputs := PublicInputs{AmountCommitment: output.Commitment, Nullifier: spend, Recipient: "0xabc"}
+
+	first, err := pg.ProveSolvency(ctx, witness, inputs)
+	require.NoError(t, err)
+	second, err := pg.ProveSolvency(ctx, witness, inputs)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, prover.calls)
+	require.NoError(t, pg.VerifyProof(second, inputs))
+
+	// Different public inputs are a different statement
+	other := inputs
+	other.Recipient = "0xdef"
+	_, err = pg.ProveSolvency(ctx, witness, other)
+	require.NoError(t, err)
+	assert.Equal(t, 2, prover.calls)
+
+	// So is the same statement under another key version
+	otherKey := NewProofGenerator(prover)
+	otherKey.SetCache(cache)
+	prover.ReferenceProver = NewReferenceProver(NewVerificationKey("easycash/solvency/v2"))
+	_, err = otherKey.ProveSolvency(ctx, witness, inputs)
+	require.NoError(t, err)
+	assert.Equal(t, 3, prover.calls)
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(3), stats.Misses)
+	assert.Equal(t, 3, stats.Entries)
+
+	// A new note tree root invalidates everything
+	cache.SetRoot(MerkleHash{1})
+	assert.Equal(t, uint64(3), cache.Stats().Invalidations)
+	_, ok := cache.Get(prover.VerificationKey(), inputs)
+	assert.False(t, ok)
+}
+
+func TestProofCacheExpiry(t *testing.T) {
+	cache := NewProofCache(20 * time.Millisecond)
+	vk := NewVerificationKey(SolvencyCircuitID)
+	inputs := PublicInputs{AmountCommitment: Commit(1, Blinding{1})}
+
+	cache.Put(vk, inputs, "0x01")
+	proof, ok := cache.Get(vk, inputs)
+	require.True(t, ok)
+	assert.Equal(t, "0x01", proof)
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = cache.Get(vk, inputs)
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
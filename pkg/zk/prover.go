@@ -0,0 +1,270 @@
+package zk
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Prover backends
+const (
+	BackendReference = "reference"
+	BackendExternal  = "external"
+)
+
+// Prover generates proofs for a circuit. Backends differ in where proving happens;
+// every backend's output verifies under its VerificationKey.
+type Prover interface {
+	// Backend names the implementation, e.g. "reference" or "external"
+	Backend() string
+	VerificationKey() *VerificationKey
+	// ProveSolvency returns the encoded SolvencyProof for the witness, bound to inputs
+	ProveSolvency(ctx context.Context, witness SolvencyWitness, inputs PublicInputs) ([]byte, error)
+}
+
+// ProverConfig selects and configures a Prover backend
+type ProverConfig struct {
+	Backend      string        // BackendReference (default) or BackendExternal
+	CircuitPath  string        // circuit artifact handed to the external prover
+	KeyPath      string        // verification key file; empty uses the built-in solvency key
+	ManifestPath string        // circuit manifest pinning artifact and key hashes; replaces CircuitPath and KeyPath
+	CircuitID    string        // manifest circuit to prove with; empty selects SolvencyCircuitID
+	Command      string        // external prover binary
+	Args         []string      // extra arguments for the external prover
+	Timeout      time.Duration // per-proof limit for the external prover; zero means none
+}
+
+// NewProver loads the verification key and circuit from the configured paths, or from
+// a verified circuit manifest, and returns the selected backend
+func NewProver(cfg ProverConfig) (Prover, error) {
+	var vk *VerificationKey
+	var circuit *Circuit
+	if cfg.ManifestPath != "" {
+		if cfg.CircuitPath != "" || cfg.KeyPath != "" {
+			return nil, fmt.Errorf("a circuit manifest replaces the circuit and verification key paths")
+		}
+		manifest, err := LoadCircuitManifest(cfg.ManifestPath)
+		if err != nil {
+			return nil, err
+		}
+		id := cfg.CircuitID
+		if id == "" {
+			id = SolvencyCircuitID
+		}
+		if circuit, err = manifest.Circuit(id); err != nil {
+			return nil, err
+		}
+		vk = circuit.VerificationKey
+	} else {
+		var err error
+		if vk, err = LoadVerificationKey(cfg.KeyPath); err != nil {
+			return nil, err
+		}
+	}
+
+	switch cfg.Backend {
+	case "", BackendReference:
+		return NewReferenceProver(vk), nil
+	case BackendExternal:
+		return NewExternalProver(cfg, vk, circuit)
+	default:
+		return nil, fmt.Errorf("unknown prover backend: %s", cfg.Backend)
+	}
+}
+
+// LoadVerificationKey reads a JSON verification key; an empty path returns the
+// built-in solvency key
+func LoadVerificationKey(path string) (*VerificationKey, error) {
+	if path == "" {
+		return NewVerificationKey(SolvencyCircuitID), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verification key: %w", err)
+	}
+	var vk VerificationKey
+	if err := json.Unmarshal(data, &vk); err != nil {
+		return nil, fmt.Errorf("failed to parse verification key %s: %w", path, err)
+	}
+	if vk.CircuitID == "" {
+		return nil, fmt.Errorf("verification key %s has no circuit id", path)
+	}
+	if vk.RangeBits != RangeBits {
+		return nil, fmt.Errorf("verification key %s uses %d-bit ranges, only %d is supported", path, vk.RangeBits, RangeBits)
+	}
+	return &vk, nil
+}
+
+// ReferenceProver proves in-process with the pure-Go Bulletproofs implementation
+type ReferenceProver struct {
+	vk *VerificationKey
+}
+
+func NewReferenceProver(vk *VerificationKey) *ReferenceProver {
+	return &ReferenceProver{vk: vk}
+}
+
+func (p *ReferenceProver) Backend() string {
+	return BackendReference
+}
+
+func (p *ReferenceProver) VerificationKey() *VerificationKey {
+	return p.vk
+}
+
+func (p *ReferenceProver) ProveSolvency(ctx context.Context, witness SolvencyWitness, inputs PublicInputs) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	proof, err := ProveSolvency(p.vk, witness, inputs)
+	if err != nil {
+		return nil, err
+	}
+	return proof.Bytes(), nil
+}
+
+// ExternalProverRequest is written as JSON to the external prover's stdin
+type ExternalProverRequest struct {
+	Circuit         string           `json:"circuit"`
+	VerificationKey *VerificationKey `json:"verification_key"`
+	Witness         SolvencyWitness  `json:"witness"`
+	PublicInputs    PublicInputs     `json:"public_inputs"`
+}
+
+// ExternalProverResponse is read as JSON from the external prover's stdout
+type ExternalProverResponse struct {
+	Proof string `json:"proof,omitempty"` // hex encoded
+	Error string `json:"error,omitempty"`
+}
+
+// ExternalProver runs a local prover binary once per proof, exchanging one JSON request
+// and response over stdin/stdout. Proofs are verified before they are returned, so a
+// faulty binary cannot hand out proofs that would later be rejected.
+type ExternalProver struct {
+	command     string
+	args        []string
+	circuitPath string
+	circuit     *Circuit
+	timeout     time.Duration
+	vk          *VerificationKey
+}
+
+// NewExternalProver checks that the prover binary and circuit artifact exist. circuit
+// is a verified manifest entry, or nil to use cfg.CircuitPath; the artifact of a
+// manifest entry is re-checked before every proof.
+func NewExternalProver(cfg ProverConfig, vk *VerificationKey, circuit *Circuit) (*ExternalProver, error) {
+	if cfg.Command == "" {
+		return nil, fmt.Errorf("external prover requires a command")
+	}
+	command, err := exec.LookPath(cfg.Command)
+	if err != nil {
+		return nil, fmt.Errorf("external prover not found: %w", err)
+	}
+	circuitPath := cfg.CircuitPath
+	if circuit != nil {
+		circuitPath = circuit.ArtifactPath
+	}
+	if circuitPath != "" {
+		if _, err := os.Stat(circuitPath); err != nil {
+			return nil, fmt.Errorf("circuit not found: %w", err)
+		}
+	}
+	return &ExternalProver{
+		command:     command,
+		args:        cfg.Args,
+		circuitPath: circuitPath,
+		circuit:     circuit,
+		timeout:     cfg.Timeout,
+		vk:          vk,
+	}, nil
+}
+
+func (p *ExternalProver) Backend() string {
+	return BackendExternal
+}
+
+func (p *ExternalProver) VerificationKey() *VerificationKey {
+	return p.vk
+}
+
+func (p *ExternalProver) ProveSolvency(ctx context.Context, witness SolvencyWitness, inputs PublicInputs) ([]byte, error) {
+	// The artifact may have been replaced since it was verified at load
+	if p.circuit != nil {
+		if err := p.circuit.CheckArtifact(); err != nil {
+			return nil, err
+		}
+	}
+
+	request, err := json.Marshal(ExternalProverRequest{
+		Circuit:         p.circuitPath,
+		VerificationKey: p.vk,
+		Witness:         witness,
+		PublicInputs:    inputs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("external prover cancelled: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("external prover failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var response ExternalProverResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("invalid external prover response: %w", err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("external prover: %s", response.Error)
+	}
+	proof, err := hex.DecodeString(strings.TrimPrefix(response.Proof, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid external prover proof encoding: %w", err)
+	}
+
+	parsed, err := ParseSolvencyProof(proof)
+	if err != nil {
+		return nil, fmt.Errorf("external prover returned a bad proof: %w", err)
+	}
+	if err := parsed.Verify(p.vk, inputs); err != nil {
+		return nil, fmt.Errorf("external prover returned a bad proof: %w", err)
+	}
+	return proof, nil
+}
+
+// ServeExternalProver answers a single ExternalProverRequest from r on w using prover.
+// It is the entry point for prover binaries speaking the external backend protocol.
+func ServeExternalProver(ctx context.Context, prover Prover, r io.Reader, w io.Writer) error {
+	var request ExternalProverRequest
+	var response ExternalProverResponse
+	if err := json.NewDecoder(r).Decode(&request); err != nil {
+		response.Error = fmt.Sprintf("invalid request: %v", err)
+	} else if request.VerificationKey == nil || !bytes.Equal(request.VerificationKey.Hash(), prover.VerificationKey().Hash()) {
+		response.Error = "verification key mismatch"
+	} else if proof, err := prover.ProveSolvency(ctx, request.Witness, request.PublicInputs); err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Proof = "0x" + hex.EncodeToString(proof)
+	}
+	return json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,102 @@
+package zk
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperProverProcess is the prover binary used by the external backend tests:
+// the test executable re-runs itself with this test selected
+func TestHelperProverProcess(t *testing.T) {
+	switch os.Getenv("ECASH_TEST_PROVER") {
+	case "":
+		return
+	case "reference":
+		err := ServeExternalProver(context.Background(), NewReferenceProver(NewVerificationKey(SolvencyCircuitID)), os.Stdin, os.Stdout)
+		if err != nil {
+			os.Exit(1)
+		}
+	case "garbage":
+		os.Stdout.WriteString(`{"proof":"0x00"}`)
+	case "slow":
+		time.Sleep(10 * time.Second)
+	}
+	os.Exit(0)
+}
+
+func newTestExternalProver(t *testing.T, mode string, timeout time.Duration) Prover {
+	t.Setenv("ECASH_TEST_PROVER", mode)
+	circuit := filepath.Join(t.TempDir(), "spend.wasm")
+	require.NoError(t, os.WriteFile(circuit, []byte("circuit"), 0600))
+
+	prover, err := NewProver(ProverConfig{
+		Backend:     BackendExternal,
+		CircuitPath: circuit,
+		Command:     os.Args[0],
+		Args:        []string{"-test.run=^TestHelperProverProcess$"},
+		Timeout:     timeout,
+	})
+	require.NoError(t, err)
+	return prover
+}
+
+func TestExternalProver(t *testing.T) {
+	pg := NewProofGenerator(newTestExternalProver(t, "reference", 0))
+	assert.Equal(t, BackendExternal, pg.Prover().Backend())
+
+	inputs := &PublicInputs{Recipient: "recipient"}
+	proof, err := pg.GenerateSolvencyProof(context.Background(), "10", "4", inputs)
+	require.NoError(t, err)
+	assert.NoError(t, pg.VerifyProof(proof, *inputs))
+
+	// Proofs from either backend verify under the same key
+	reference := NewProofGenerator(NewReferenceProver(NewVerificationKey(SolvencyCircuitID)))
+	assert.NoError(t, reference.VerifyProof(proof, *inputs))
+}
+
+func TestExternalProverFailures(t *testing.T) {
+	inputs := &PublicInputs{}
+
+	_, err := NewProofGenerator(newTestExternalProver(t, "garbage", 0)).GenerateSolvencyProof(context.Background(), "10", "4", inputs)
+	assert.ErrorContains(t, err, "bad proof")
+
+	_, err = NewProofGenerator(newTestExternalProver(t, "slow", 100*time.Millisecond)).GenerateSolvencyProof(context.Background(), "10", "4", inputs)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = NewProver(ProverConfig{Backend: BackendExternal, Command: "/nonexistent/prover"})
+	assert.Error(t, err)
+	_, err = NewProver(ProverConfig{Backend: BackendExternal, Command: os.Args[0], CircuitPath: "/nonexistent/circuit"})
+	assert.Error(t, err)
+	_, err = NewProver(ProverConfig{Backend: "gpu"})
+	assert.Error(t, err)
+}
+
+func TestLoadVerificationKey(t *testing.T) {
+	vk, err := LoadVerificationKey("")
+	require.NoError(t, err)
+	assert.Equal(t, SolvencyCircuitID, vk.CircuitID)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "spend.vk.json")
+	data, err := json.Marshal(NewVerificationKey("easycash/spend/v2"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	prover, err := NewProver(ProverConfig{KeyPath: path})
+	require.NoError(t, err)
+	assert.Equal(t, BackendReference, prover.Backend())
+	assert.Equal(t, "easycash/spend/v2", prover.VerificationKey().CircuitID)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"circuit_id":"x","range_bits":32}`), 0600))
+	_, err = LoadVerificationKey(path)
+	assert.Error(t, err)
+	_, err = LoadVerificationKey(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
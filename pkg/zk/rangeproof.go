@@ -0,0 +1,450 @@
+package zk
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"sync"
+)
+
+// Range proofs follow Bulletproofs (Bünz et al., 2018): a prover shows that each of
+// m Pedersen commitments opens to a value in [0, 2^RangeBits) with a proof of
+// O(log(m*RangeBits)) size and without revealing the values. Aggregating m values into
+// one proof costs far less than m individual proofs.
+
+const (
+	// RangeBits is the bit width every proven value must fit in
+	RangeBits = 64
+
+	// MaxAggregation is the largest number of values one proof may cover
+	MaxAggregation = 16
+
+	rangeProofDomain = "EasyCash/RangeProof/v1"
+	rangeProofFixed  = 4*pointSize + 5*32
+)
+
+// ErrInvalidRangeProof is returned when a range proof is malformed or does not verify
+var ErrInvalidRangeProof = errors.New("invalid range proof")
+
+// Vector generators shared by all range proofs, derived on demand
+var (
+	generatorMu sync.Mutex
+	generatorsG []point
+	generatorsH []point
+)
+
+// generators returns the first n vector generators of each kind
+func generators(n int) ([]point, []point) {
+	generatorMu.Lock()
+	defer generatorMu.Unlock()
+
+	for i := len(generatorsG); i < n; i++ {
+		generatorsG = append(generatorsG, hashToPoint("EasyCash/Bulletproofs/G/"+strconv.Itoa(i)))
+		generatorsH = append(generatorsH, hashToPoint("EasyCash/Bulletproofs/H/"+strconv.Itoa(i)))
+	}
+	return generatorsG[:n:n], generatorsH[:n:n]
+}
+
+// RangeProof proves that a batch of commitments hold values in [0, 2^RangeBits)
+type RangeProof struct {
+	A, S, T1, T2 point
+	taux, mu, t  scalar
+	L, R         []point
+	a, b         scalar
+}
+
+// aggregationSize returns the padded batch size (a power of two) for count values
+func aggregationSize(count int) (int, error) {
+	if count < 1 || count > MaxAggregation {
+		return 0, fmt.Errorf("%w: batch size %d outside [1, %d]", ErrInvalidRangeProof, count, MaxAggregation)
+	}
+	return 1 << bits.Len(uint(count-1)), nil
+}
+
+// ProveRange commits to values under the given blinding factors and proves every value
+// fits in RangeBits bits. Batches that are not a power of two are padded internally.
+func ProveRange(values []uint64, blindings []Blinding) (*RangeProof, []*Commitment, error) {
+	return proveRange(nil, values, blindings)
+}
+
+// proveRange binds the proof to context, which the verifier must supply unchanged
+func proveRange(context []byte, values []uint64, blindings []Blinding) (*RangeProof, []*Commitment, error) {
+	if len(values) != len(blindings) {
+		return nil, nil, fmt.Errorf("got %d values but %d blinding factors", len(values), len(blindings))
+	}
+	m, err := aggregationSize(len(values))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	count := len(values)
+
+	// Padding entries commit to zero with a zero blinding factor (the identity)
+	values = append(append([]uint64(nil), values...), make([]uint64, m-len(values))...)
+	blindings = append(append([]Blinding(nil), blindings...), make([]Blinding, m-len(blindings))...)
+
+	commitments := make([]*Commitment, m)
+	gammas := make([]scalar, m)
+	for j := range values {
+		commitments[j] = Commit(values[j], blindings[j])
+		gammas[j] = blindings[j].scalar()
+	}
+
+	n := RangeBits
+	N := n * m
+	gens, hens := generators(N)
+	one := sOne()
+	G := baseMul(&one)
+
+	tr := newTranscript(rangeProofDomain)
+	tr.appendBytes("context", context)
+	tr.appendUint64("n", uint64(n))
+	tr.appendUint64("m", uint64(m))
+	for _, c := range commitments {
+		tr.appendPoint("V", &c.p)
+	}
+
+	// Bit decomposition: aL holds the bits, aR = aL - 1
+	aL := make([]scalar, N)
+	aR := make([]scalar, N)
+	for j, v := range values {
+		for i := 0; i < n; i++ {
+			if (v>>i)&1 == 1 {
+				aL[j*n+i] = one
+			}
+			aR[j*n+i] = sSub(aL[j*n+i], one)
+		}
+	}
+
+	alpha, err := randomScalar()
+	if err != nil {
+		return nil, nil, err
+	}
+	sL, err := randomScalars(N)
+	if err != nil {
+		return nil, nil, err
+	}
+	sR, err := randomScalars(N)
+	if err != nil {
+		return nil, nil, err
+	}
+	rho, err := randomScalar()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proof := &RangeProof{}
+	proof.A = vectorCommit(alpha, aL, aR, gens, hens)
+	proof.S = vectorCommit(rho, sL, sR, gens, hens)
+	tr.appendPoint("A", &proof.A)
+	tr.appendPoint("S", &proof.S)
+	y := tr.challenge("y")
+	z := tr.challenge("z")
+
+	yN := powers(y, N)
+	twoN := powers(scalarFromUint64(2), n)
+	zPow := powers(z, m+3)
+
+	// l(X) = l0 + l1*X, r(X) = r0 + r1*X
+	l0 := make([]scalar, N)
+	r0 := make([]scalar, N)
+	r1 := make([]scalar, N)
+	for i := 0; i < N; i++ {
+		l0[i] = sSub(aL[i], z)
+		r0[i] = sAdd(sMul(yN[i], sAdd(aR[i], z)), sMul(zPow[2+i/n], twoN[i%n]))
+		r1[i] = sMul(yN[i], sR[i])
+	}
+	t1 := sAdd(innerProduct(l0, r1), innerProduct(sL, r0))
+	t2 := innerProduct(sL, r1)
+
+	tau1, err := randomScalar()
+	if err != nil {
+		return nil, nil, err
+	}
+	tau2, err := randomScalar()
+	if err != nil {
+		return nil, nil, err
+	}
+	proof.T1 = commitScalar(&t1, &tau1).p
+	proof.T2 = commitScalar(&t2, &tau2).p
+	tr.appendPoint("T1", &proof.T1)
+	tr.appendPoint("T2", &proof.T2)
+	x := tr.challenge("x")
+
+	l := make([]scalar, N)
+	r := make([]scalar, N)
+	for i := 0; i < N; i++ {
+		l[i] = sAdd(l0[i], sMul(sL[i], x))
+		r[i] = sAdd(r0[i], sMul(r1[i], x))
+	}
+	proof.t = innerProduct(l, r)
+	proof.taux = sAdd(sMul(tau2, sMul(x, x)), sMul(tau1, x))
+	for j := range gammas {
+		proof.taux = sAdd(proof.taux, sMul(zPow[2+j], gammas[j]))
+	}
+	proof.mu = sAdd(alpha, sMul(rho, x))
+
+	tr.appendScalar("taux", &proof.taux)
+	tr.appendScalar("mu", &proof.mu)
+	tr.appendScalar("t", &proof.t)
+	w := tr.challenge("w")
+	Q := mul(&w, &G)
+
+	// Inner product argument over H'_i = y^-i * H_i
+	yInv := powers(sInv(y), N)
+	hPrime := make([]point, N)
+	for i := range hens {
+		hPrime[i] = mul(&yInv[i], &hens[i])
+	}
+	proof.L, proof.R, proof.a, proof.b = proveInnerProduct(tr, gens, hPrime, &Q, l, r)
+
+	return proof, commitments[:count], nil
+}
+
+// Verify checks the proof against the commitments it was created for, in order
+func (p *RangeProof) Verify(commitments []*Commitment) error {
+	return p.verify(nil, commitments)
+}
+
+func (p *RangeProof) verify(context []byte, commitments []*Commitment) error {
+	m, err := aggregationSize(len(commitments))
+	if err != nil {
+		return err
+	}
+	n := RangeBits
+	N := n * m
+	rounds := bits.Len(uint(N)) - 1
+	if len(p.L) != rounds || len(p.R) != rounds {
+		return fmt.Errorf("%w: proof covers %d values, got %d commitments", ErrInvalidRangeProof, (1<<len(p.L))/n, len(commitments))
+	}
+	gens, hens := generators(N)
+	one := sOne()
+	G := baseMul(&one)
+
+	tr := newTranscript(rangeProofDomain)
+	tr.appendBytes("context", context)
+	tr.appendUint64("n", uint64(n))
+	tr.appendUint64("m", uint64(m))
+	V := make([]point, m)
+	for j := range V {
+		if j < len(commitments) {
+			V[j] = commitments[j].p
+		}
+		tr.appendPoint("V", &V[j])
+	}
+	tr.appendPoint("A", &p.A)
+	tr.appendPoint("S", &p.S)
+	y := tr.challenge("y")
+	z := tr.challenge("z")
+	tr.appendPoint("T1", &p.T1)
+	tr.appendPoint("T2", &p.T2)
+	x := tr.challenge("x")
+	tr.appendScalar("taux", &p.taux)
+	tr.appendScalar("mu", &p.mu)
+	tr.appendScalar("t", &p.t)
+	w := tr.challenge("w")
+
+	yN := powers(y, N)
+	twoN := powers(scalarFromUint64(2), n)
+	zPow := powers(z, m+3)
+	x2 := sMul(x, x)
+
+	// Polynomial check: t*G + taux*H == sum(z^(2+j) * V_j) + delta(y, z)*G + x*T1 + x^2*T2
+	var sumY, sumTwo scalar
+	for i := range yN {
+		sumY = sAdd(sumY, yN[i])
+	}
+	for i := range twoN {
+		sumTwo = sAdd(sumTwo, twoN[i])
+	}
+	delta := sMul(sSub(z, zPow[2]), sumY)
+	for j := 0; j < m; j++ {
+		delta = sSub(delta, sMul(zPow[3+j], sumTwo))
+	}
+
+	scalars := []scalar{p.t, p.taux, sNeg(delta), sNeg(x), sNeg(x2)}
+	points := []point{G, pedersenH, G, p.T1, p.T2}
+	for j := range V {
+		scalars = append(scalars, sNeg(zPow[2+j]))
+		points = append(points, V[j])
+	}
+	if check := multiScalarMul(scalars, points); !isIdentity(&check) {
+		return fmt.Errorf("%w: polynomial commitment check failed", ErrInvalidRangeProof)
+	}
+
+	// Inner product check, folded into a single multi-scalar multiplication
+	u := make([]scalar, rounds)
+	for j := range u {
+		tr.appendPoint("L", &p.L[j])
+		tr.appendPoint("R", &p.R[j])
+		u[j] = tr.challenge("u")
+	}
+	s, sInvs := foldingScalars(u, N)
+
+	yInv := powers(sInv(y), N)
+	scalars = make([]scalar, 0, 2*N+2*rounds+4)
+	points = make([]point, 0, 2*N+2*rounds+4)
+	for i := 0; i < N; i++ {
+		// G_i: -z - a*s_i
+		scalars = append(scalars, sNeg(sAdd(z, sMul(p.a, s[i]))))
+		points = append(points, gens[i])
+	}
+	for i := 0; i < N; i++ {
+		// H_i: (z*y^i + z^(2+j)*2^(i mod n) - b/s_i) * y^-i
+		h := sAdd(sMul(z, yN[i]), sMul(zPow[2+i/n], twoN[i%n]))
+		h = sSub(h, sMul(p.b, sInvs[i]))
+		scalars = append(scalars, sMul(h, yInv[i]))
+		points = append(points, hens[i])
+	}
+	scalars = append(scalars, one, x, sNeg(p.mu), sMul(w, sSub(p.t, sMul(p.a, p.b))))
+	points = append(points, p.A, p.S, pedersenH, G)
+	for j := range u {
+		u2 := sMul(u[j], u[j])
+		scalars = append(scalars, u2, sInv(u2))
+		points = append(points, p.L[j], p.R[j])
+	}
+	if check := multiScalarMul(scalars, points); !isIdentity(&check) {
+		return fmt.Errorf("%w: inner product check failed", ErrInvalidRangeProof)
+	}
+	return nil
+}
+
+// proveInnerProduct proves knowledge of a, b with P = <a, G> + <b, H> + <a, b>*Q,
+// halving the vectors each round
+func proveInnerProduct(tr *transcript, G, H []point, Q *point, a, b []scalar) ([]point, []point, scalar, scalar) {
+	G = append([]point(nil), G...)
+	H = append([]point(nil), H...)
+	a = append([]scalar(nil), a...)
+	b = append([]scalar(nil), b...)
+
+	var Ls, Rs []point
+	for len(a) > 1 {
+		k := len(a) / 2
+		cL := innerProduct(a[:k], b[k:])
+		cR := innerProduct(a[k:], b[:k])
+
+		L := multiScalarMul(
+			append(append(append([]scalar(nil), a[:k]...), b[k:]...), cL),
+			append(append(append([]point(nil), G[k:]...), H[:k]...), *Q),
+		)
+		R := multiScalarMul(
+			append(append(append([]scalar(nil), a[k:]...), b[:k]...), cR),
+			append(append(append([]point(nil), G[:k]...), H[k:]...), *Q),
+		)
+		Ls = append(Ls, L)
+		Rs = append(Rs, R)
+		tr.appendPoint("L", &L)
+		tr.appendPoint("R", &R)
+		u := tr.challenge("u")
+		uInv := sInv(u)
+
+		for i := 0; i < k; i++ {
+			G[i] = multiScalarMul([]scalar{uInv, u}, []point{G[i], G[k+i]})
+			H[i] = multiScalarMul([]scalar{u, uInv}, []point{H[i], H[k+i]})
+			a[i] = sAdd(sMul(u, a[i]), sMul(uInv, a[k+i]))
+			b[i] = sAdd(sMul(uInv, b[i]), sMul(u, b[k+i]))
+		}
+		G, H, a, b = G[:k], H[:k], a[:k], b[:k]
+	}
+	return Ls, Rs, a[0], b[0]
+}
+
+// foldingScalars returns s_i and 1/s_i such that the folded generators of the inner
+// product argument are sum(s_i * G_i) and sum(1/s_i * H_i)
+func foldingScalars(u []scalar, n int) ([]scalar, []scalar) {
+	rounds := len(u)
+	uInv := make([]scalar, rounds)
+	for j := range u {
+		uInv[j] = sInv(u[j])
+	}
+
+	s := make([]scalar, n)
+	sInvs := make([]scalar, n)
+	for i := 0; i < n; i++ {
+		s[i], sInvs[i] = sOne(), sOne()
+		for j := 0; j < rounds; j++ {
+			// Round j splits on bit (rounds-1-j); the upper half is weighted by u_j
+			if (i>>(rounds-1-j))&1 == 1 {
+				s[i] = sMul(s[i], u[j])
+				sInvs[i] = sMul(sInvs[i], uInv[j])
+			} else {
+				s[i] = sMul(s[i], uInv[j])
+				sInvs[i] = sMul(sInvs[i], u[j])
+			}
+		}
+	}
+	return s, sInvs
+}
+
+// vectorCommit returns blinding*H + <left, G> + <right, H_vec>
+func vectorCommit(blinding scalar, left, right []scalar, G, H []point) point {
+	scalars := append(append([]scalar{blinding}, left...), right...)
+	points := append(append([]point{pedersenH}, G...), H...)
+	return multiScalarMul(scalars, points)
+}
+
+func randomScalars(n int) ([]scalar, error) {
+	out := make([]scalar, n)
+	for i := range out {
+		s, err := randomScalar()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+// Bytes serializes the proof as A, S, T1, T2, taux, mu, t, a, b followed by the L/R pairs
+func (p *RangeProof) Bytes() []byte {
+	out := make([]byte, 0, rangeProofFixed+len(p.L)*2*pointSize)
+	for _, pt := range []*point{&p.A, &p.S, &p.T1, &p.T2} {
+		out = append(out, encodePoint(pt)...)
+	}
+	for _, s := range []*scalar{&p.taux, &p.mu, &p.t, &p.a, &p.b} {
+		b := s.Bytes()
+		out = append(out, b[:]...)
+	}
+	for i := range p.L {
+		out = append(out, encodePoint(&p.L[i])...)
+		out = append(out, encodePoint(&p.R[i])...)
+	}
+	return out
+}
+
+// ParseRangeProof decodes a proof produced by Bytes
+func ParseRangeProof(b []byte) (*RangeProof, error) {
+	if len(b) < rangeProofFixed || (len(b)-rangeProofFixed)%(2*pointSize) != 0 {
+		return nil, fmt.Errorf("%w: invalid length %d", ErrInvalidRangeProof, len(b))
+	}
+	rounds := (len(b) - rangeProofFixed) / (2 * pointSize)
+	if rounds > bits.Len(uint(RangeBits*MaxAggregation))-1 || 1<<rounds < RangeBits {
+		return nil, fmt.Errorf("%w: unsupported proof size", ErrInvalidRangeProof)
+	}
+
+	p := &RangeProof{L: make([]point, rounds), R: make([]point, rounds)}
+	var err error
+	for _, pt := range []*point{&p.A, &p.S, &p.T1, &p.T2} {
+		if *pt, err = decodePoint(b[:pointSize]); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRangeProof, err)
+		}
+		b = b[pointSize:]
+	}
+	for _, s := range []*scalar{&p.taux, &p.mu, &p.t, &p.a, &p.b} {
+		if *s, err = decodeScalar(b[:32]); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRangeProof, err)
+		}
+		b = b[32:]
+	}
+	for i := 0; i < rounds; i++ {
+		if p.L[i], err = decodePoint(b[:pointSize]); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRangeProof, err)
+		}
+		if p.R[i], err = decodePoint(b[pointSize : 2*pointSize]); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRangeProof, err)
+		}
+		b = b[2*pointSize:]
+	}
+	return p, nil
+}
@@ -0,0 +1,73 @@
+package zk
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomBlindings(t *testing.T, n int) []Blinding {
+	out := make([]Blinding, n)
+	for i := range out {
+		b, err := NewBlinding()
+		require.NoError(t, err)
+		out[i] = b
+	}
+	return out
+}
+
+func TestRangeProof(t *testing.T) {
+	for _, value := range []uint64{0, 1, 1000, math.MaxUint64} {
+		proof, commitments, err := ProveRange([]uint64{value}, randomBlindings(t, 1))
+		require.NoError(t, err)
+		require.Len(t, commitments, 1)
+		assert.NoError(t, proof.Verify(commitments), "value %d", value)
+
+		// Round trip through the wire encoding
+		parsed, err := ParseRangeProof(proof.Bytes())
+		require.NoError(t, err)
+		assert.NoError(t, parsed.Verify(commitments))
+	}
+}
+
+func TestAggregatedRangeProof(t *testing.T) {
+	values := []uint64{5, 10, 1 << 40}
+	blindings := randomBlindings(t, len(values))
+	proof, commitments, err := ProveRange(values, blindings)
+	require.NoError(t, err)
+	require.Len(t, commitments, 3)
+	assert.NoError(t, proof.Verify(commitments))
+
+	// 3 values are padded to 4: log2(4*64) = 8 rounds
+	assert.Len(t, proof.L, 8)
+
+	// Swapping commitments breaks the proof
+	assert.ErrorIs(t, proof.Verify([]*Commitment{commitments[1], commitments[0], commitments[2]}), ErrInvalidRangeProof)
+	// So does verifying against a different batch size
+	assert.ErrorIs(t, proof.Verify(commitments[:2]), ErrInvalidRangeProof)
+}
+
+func TestRangeProofRejectsOutOfRange(t *testing.T) {
+	// A proof does not carry over to a commitment to a negative amount (-1 wraps to n-1)
+	blinding := randomBlindings(t, 1)
+	proof, _, err := ProveRange([]uint64{1}, blinding)
+	require.NoError(t, err)
+
+	minusOne := Commit(0, blinding[0]).Sub(Commit(1, Blinding{}))
+	assert.ErrorIs(t, proof.Verify([]*Commitment{minusOne}), ErrInvalidRangeProof)
+
+	// Tampered proof bytes fail
+	data := proof.Bytes()
+	data[4*pointSize+31] ^= 1
+	tampered, err := ParseRangeProof(data)
+	require.NoError(t, err)
+	assert.ErrorIs(t, tampered.Verify([]*Commitment{Commit(1, blinding[0])}), ErrInvalidRangeProof)
+
+	_, err = ParseRangeProof(data[:100])
+	assert.ErrorIs(t, err, ErrInvalidRangeProof)
+
+	_, _, err = ProveRange(make([]uint64, MaxAggregation+1), make([]Blinding, MaxAggregation+1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package zk
+
+// Value-semantics helpers over scalars; ModNScalar methods mutate their receiver,
+// which makes vector arithmetic hard to read
+
+func sAdd(a, b scalar) scalar {
+	return *a.Add(&b)
+}
+
+func sSub(a, b scalar) scalar {
+	return *a.Add(b.Negate())
+}
+
+func sMul(a, b scalar) scalar {
+	return *a.Mul(&b)
+}
+
+func sNeg(a scalar) scalar {
+	return *a.Negate()
+}
+
+func sInv(a scalar) scalar {
+	return *a.InverseNonConst()
+}
+
+func sOne() scalar {
+	var s scalar
+	s.SetInt(1)
+	return s
+}
+
+// powers returns [1, x, x^2, ..., x^(n-1)]
+func powers(x scalar, n int) []scalar {
+	out := make([]scalar, n)
+	if n == 0 {
+		return out
+	}
+	out[0] = sOne()
+	for i := 1; i < n; i++ {
+		out[i] = sMul(out[i-1], x)
+	}
+	return out
+}
+
+func innerProduct(a, b []scalar) scalar {
+	var sum scalar
+	for i := range a {
+		sum = sAdd(sum, sMul(a[i], b[i]))
+	}
+	return sum
+}
@@ -0,0 +1,238 @@
+package zk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/useeasycash/ecash-sdk-core/pkg/fileutil"
+)
+
+var (
+	// ErrUnknownAnchor is returned when a spend references a root outside the root history
+	ErrUnknownAnchor = errors.New("unknown merkle root")
+	// ErrNoteNotInTree is returned when a spent note is not a leaf of the tree at the anchor
+	ErrNoteNotInTree = errors.New("note is not in the note commitment tree")
+)
+
+// ShieldedState is the local view of the shielded pool: the note commitment tree and
+// the set of spent nullifiers. It is safe for concurrent use.
+type ShieldedState struct {
+	mu         sync.Mutex
+	tree       *MerkleTree
+	nullifiers *NullifierSet
+}
+
+// NewShieldedState returns an empty state with the default tree parameters
+func NewShieldedState() *ShieldedState {
+	tree, _ := NewMerkleTree(DefaultTreeDepth, DefaultRootHistory)
+	return &ShieldedState{tree: tree, nullifiers: NewNullifierSet()}
+}
+
+// Root returns the current note commitment tree root
+func (s *ShieldedState) Root() MerkleHash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.Root()
+}
+
+// AddCommitment appends a note commitment and returns its leaf index
+func (s *ShieldedState) AddCommitment(c *Commitment) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.Append(c)
+}
+
+// HasCommitment reports whether commitment c is the leaf at index of the tree
+func (s *ShieldedState) HasCommitment(index uint64, c *Commitment) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.hasLeaf(index, c)
+}
+
+// SyncCommitments mirrors note commitments published from leaf start on, e.g. by the
+// ledger, into the tree and returns how many were appended. Leaves the tree already
+// holds must match; a start past the end of the tree fails since the leaves in between
+// are missing. Nothing is appended when the sync fails.
+func (s *ShieldedState) SyncCommitments(start uint64, commitments []*Commitment) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	size := s.tree.Size()
+	if start > size {
+		return 0, fmt.Errorf("commitments start at leaf %d, the tree only has %d", start, size)
+	}
+	known := size - start
+	if uint64(len(commitments)) < known {
+		known = uint64(len(commitments))
+	}
+	for i, c := range commitments[:known] {
+		if !s.tree.hasLeaf(start+uint64(i), c) {
+			return 0, fmt.Errorf("commitment at leaf %d differs from the tree", start+uint64(i))
+		}
+	}
+	added := commitments[known:]
+	if !s.tree.hasRoom(uint64(len(added))) {
+		return 0, ErrTreeFull
+	}
+	for _, c := range added {
+		if c == nil {
+			return 0, fmt.Errorf("missing commitment")
+		}
+	}
+	for _, c := range added {
+		if _, err := s.tree.Append(c); err != nil {
+			return 0, err
+		}
+	}
+	return len(added), nil
+}
+
+// Witness returns the membership witness of a note against the current root
+func (s *ShieldedState) Witness(index uint64) (*MerkleWitness, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.Witness(index)
+}
+
+// IsKnownRoot reports whether root is a valid spend anchor
+func (s *ShieldedState) IsKnownRoot(root MerkleHash) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.IsKnownRoot(root)
+}
+
+// IsSpent reports whether a nullifier has been spent
+func (s *ShieldedState) IsSpent(n Nullifier) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nullifiers.IsSpent(n)
+}
+
+// PendingSpend holds nullifiers reserved by BeginSpend until the spend is committed or aborted
+type PendingSpend struct {
+	state      *ShieldedState
+	nullifiers []Nullifier
+	done       bool
+}
+
+// BeginSpend checks each note's membership witness against the anchor and reserves the
+// notes' nullifiers, failing with ErrDoubleSpend if any is already spent or reserved by
+// a concurrent spend
+func (s *ShieldedState) BeginSpend(anchor MerkleHash, notes []Note) (*PendingSpend, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.tree.IsKnownRoot(anchor) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAnchor, anchor)
+	}
+	nullifiers := make([]Nullifier, len(notes))
+	for i, note := range notes {
+		w, err := s.tree.WitnessAt(note.LeafIndex, anchor)
+		if err != nil || note.Commitment == nil || !w.Verify(note.Commitment) {
+			return nil, fmt.Errorf("%w: leaf %d at %s", ErrNoteNotInTree, note.LeafIndex, anchor)
+		}
+		nullifiers[i] = note.Nullifier
+	}
+	if err := s.nullifiers.reserve(nullifiers); err != nil {
+		return nil, err
+	}
+	return &PendingSpend{state: s, nullifiers: nullifiers}, nil
+}
+
+// Commit marks the nullifiers spent and appends the output note commitments,
+// returning their leaf indices. Either all outputs are appended or, when they do not
+// fit, none are and the nullifiers are released.
+func (p *PendingSpend) Commit(outputs ...*Commitment) ([]uint64, error) {
+	s := p.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.done {
+		return nil, fmt.Errorf("spend already finished")
+	}
+	p.done = true
+
+	if !s.tree.hasRoom(uint64(len(outputs))) {
+		s.nullifiers.release(p.nullifiers)
+		return nil, ErrTreeFull
+	}
+	indices := make([]uint64, 0, len(outputs))
+	for _, c := range outputs {
+		index, err := s.tree.Append(c)
+		if err != nil {
+			return nil, err
+		}
+		indices = append(indices, index)
+	}
+	s.nullifiers.commit(p.nullifiers)
+	return indices, nil
+}
+
+// Abort releases the reserved nullifiers; it is a no-op after Commit
+func (p *PendingSpend) Abort() {
+	s := p.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.done {
+		return
+	}
+	p.done = true
+	s.nullifiers.release(p.nullifiers)
+}
+
+// shieldedSnapshot is the persisted form of a ShieldedState
+type shieldedSnapshot struct {
+	Tree       merkleSnapshot `json:"tree"`
+	Nullifiers []Nullifier    `json:"nullifiers"`
+}
+
+// Snapshot serializes the tree and spent nullifiers. Pending spends are not included.
+func (s *ShieldedState) Snapshot() ([]byte, error) {
+	s.mu.Lock()
+	snapshot := shieldedSnapshot{Tree: s.tree.snapshot(), Nullifiers: s.nullifiers.sorted()}
+	s.mu.Unlock()
+	return json.Marshal(snapshot)
+}
+
+// RestoreShieldedState rebuilds a state from a snapshot, checking the tree against its root history
+func RestoreShieldedState(data []byte) (*ShieldedState, error) {
+	var snapshot shieldedSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse shielded state: %w", err)
+	}
+	tree, err := restoreMerkleTree(snapshot.Tree)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shielded state: %w", err)
+	}
+	nullifiers := NewNullifierSet()
+	nullifiers.commit(snapshot.Nullifiers)
+	return &ShieldedState{tree: tree, nullifiers: nullifiers}, nil
+}
+
+// SaveFile writes a snapshot via a temporary file and rename so a crash never leaves a partial state
+func (s *ShieldedState) SaveFile(path string) error {
+	data, err := s.Snapshot()
+	if err != nil {
+		return err
+	}
+	if err := fileutil.WriteAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to save shielded state: %w", err)
+	}
+	return nil
+}
+
+// LoadShieldedStateFile restores a state saved with SaveFile; a missing file yields an empty state
+func LoadShieldedStateFile(path string) (*ShieldedState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewShieldedState(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shielded state: %w", err)
+	}
+	return RestoreShieldedState(data)
+}
@@ -0,0 +1,170 @@
+package zk
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addTestNotes appends n note commitments to the state and returns them as spendable
+// notes with nullifiers {1}, {2}, ...
+func addTestNotes(t *testing.T, state *ShieldedState, n int) []Note {
+	notes := make([]Note, n)
+	for i, c := range testCommitments(t, n) {
+		index, err := state.AddCommitment(c)
+		require.NoError(t, err)
+		notes[i] = Note{Commitment: c, LeafIndex: index, Nullifier: Nullifier{byte(i + 1)}}
+	}
+	return notes
+}
+
+func TestShieldedStateDoubleSpend(t *testing.T) {
+	state := NewShieldedState()
+	notes := addTestNotes(t, state, 4)
+	anchor := state.Root()
+	nf := notes[0].Nullifier
+
+	spend, err := state.BeginSpend(anchor, notes[:1])
+	require.NoError(t, err)
+
+	// A concurrent spend of the same note is rejected while the first is pending
+	_, err = state.BeginSpend(anchor, notes[:1])
+	assert.ErrorIs(t, err, ErrDoubleSpend)
+
+	// Aborting releases the reservation
+	spend.Abort()
+	spend, err = state.BeginSpend(anchor, notes[:1])
+	require.NoError(t, err)
+
+	output, _, err := CommitRandom(5)
+	require.NoError(t, err)
+	indices, err := spend.Commit(output)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{4}, indices)
+	assert.True(t, state.IsSpent(nf))
+	spend.Abort() // no-op after commit
+	assert.True(t, state.IsSpent(nf))
+
+	_, err = state.BeginSpend(state.Root(), notes[:1])
+	assert.ErrorIs(t, err, ErrDoubleSpend)
+	_, err = state.BeginSpend(state.Root(), []Note{notes[1], notes[1]})
+	assert.ErrorIs(t, err, ErrDoubleSpend)
+	_, err = state.BeginSpend(MerkleHash{9}, notes[2:3])
+	assert.ErrorIs(t, err, ErrUnknownAnchor)
+
+	// The previous root is still a valid anchor
+	_, err = state.BeginSpend(anchor, notes[3:4])
+	assert.NoError(t, err)
+}
+
+func TestShieldedStateRejectsNotesOutsideTree(t *testing.T) {
+	state := NewShieldedState()
+	notes := addTestNotes(t, state, 2)
+	anchor := state.Root()
+
+	// A commitment that was never added, even at an index the tree holds
+	foreign := testCommitments(t, 1)[0]
+	_, err := state.BeginSpend(anchor, []Note{{Commitment: foreign, LeafIndex: 1, Nullifier: Nullifier{7}}})
+	assert.ErrorIs(t, err, ErrNoteNotInTree)
+	_, err = state.BeginSpend(anchor, []Note{{Commitment: foreign, LeafIndex: 9, Nullifier: Nullifier{7}}})
+	assert.ErrorIs(t, err, ErrNoteNotInTree)
+
+	// A note appended after the anchor is not covered by it
+	later := addTestNotes(t, state, 3)[2]
+	_, err = state.BeginSpend(anchor, []Note{later})
+	assert.ErrorIs(t, err, ErrNoteNotInTree)
+	_, err = state.BeginSpend(state.Root(), []Note{later})
+	assert.NoError(t, err)
+
+	// A rejected spend reserves nothing
+	_, err = state.BeginSpend(anchor, []Note{notes[0], {Commitment: foreign, Nullifier: notes[1].Nullifier}})
+	assert.ErrorIs(t, err, ErrNoteNotInTree)
+	_, err = state.BeginSpend(anchor, notes)
+	assert.NoError(t, err)
+}
+
+func TestShieldedStateCommitIsAtomic(t *testing.T) {
+	tree, err := NewMerkleTree(2, DefaultRootHistory)
+	require.NoError(t, err)
+	state := &ShieldedState{tree: tree, nullifiers: NewNullifierSet()}
+	notes := addTestNotes(t, state, 3)
+	root := state.Root()
+
+	// Two outputs do not fit in the last free leaf, so neither is appended
+	spend, err := state.BeginSpend(root, notes[:1])
+	require.NoError(t, err)
+	_, err = spend.Commit(testCommitments(t, 2)...)
+	assert.ErrorIs(t, err, ErrTreeFull)
+	assert.Equal(t, root, state.Root())
+	assert.False(t, state.IsSpent(notes[0].Nullifier))
+
+	// The released note can still be spent into the free leaf
+	spend, err = state.BeginSpend(root, notes[:1])
+	require.NoError(t, err)
+	indices, err := spend.Commit(testCommitments(t, 1)...)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{3}, indices)
+}
+
+func TestShieldedStateConcurrentSpends(t *testing.T) {
+	state := NewShieldedState()
+	note := addTestNotes(t, state, 1)[0]
+	anchor := state.Root()
+
+	var wg sync.WaitGroup
+	var succeeded atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			spend, err := state.BeginSpend(anchor, []Note{note})
+			if err != nil {
+				return
+			}
+			if _, err := spend.Commit(); err == nil {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), succeeded.Load())
+}
+
+func TestShieldedStatePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shielded.json")
+
+	state, err := LoadShieldedStateFile(path)
+	require.NoError(t, err)
+	commitments := testCommitments(t, 3)
+	for _, c := range commitments {
+		_, err := state.AddCommitment(c)
+		require.NoError(t, err)
+	}
+	spend, err := state.BeginSpend(state.Root(), []Note{{Commitment: commitments[0], Nullifier: Nullifier{1}}})
+	require.NoError(t, err)
+	_, err = spend.Commit()
+	require.NoError(t, err)
+	require.NoError(t, state.SaveFile(path))
+
+	restored, err := LoadShieldedStateFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, state.Root(), restored.Root())
+	assert.True(t, restored.IsSpent(Nullifier{1}))
+
+	w, err := restored.Witness(2)
+	require.NoError(t, err)
+	assert.True(t, w.Verify(commitments[2]))
+
+	// Snapshots whose root history does not match the leaves are rejected
+	data, err := state.Snapshot()
+	require.NoError(t, err)
+	tampered := strings.Replace(string(data), state.tree.layers[0][0].String(), MerkleHash{}.String(), 1)
+	require.NotEqual(t, string(data), tampered)
+	_, err = RestoreShieldedState([]byte(tampered))
+	assert.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package zk
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+)
+
+// transcript makes interactive proofs non-interactive (Fiat-Shamir): every message the
+// prover sends is absorbed, and challenges are derived from everything absorbed so far
+type transcript struct {
+	state []byte
+}
+
+func newTranscript(domain string) *transcript {
+	t := &transcript{}
+	t.appendBytes("domain", []byte(domain))
+	return t
+}
+
+func (t *transcript) appendBytes(label string, data []byte) {
+	t.state = binary.BigEndian.AppendUint32(t.state, uint32(len(label)))
+	t.state = append(t.state, label...)
+	t.state = binary.BigEndian.AppendUint32(t.state, uint32(len(data)))
+	t.state = append(t.state, data...)
+}
+
+func (t *transcript) appendUint64(label string, v uint64) {
+	t.appendBytes(label, binary.BigEndian.AppendUint64(nil, v))
+}
+
+func (t *transcript) appendPoint(label string, p *point) {
+	t.appendBytes(label, encodePoint(p))
+}
+
+func (t *transcript) appendScalar(label string, s *scalar) {
+	b := s.Bytes()
+	t.appendBytes(label, b[:])
+}
+
+// challenge derives a non-zero scalar and absorbs it
+func (t *transcript) challenge(label string) scalar {
+	for {
+		t.appendBytes("challenge", []byte(label))
+		digest := sha256.Sum256(t.state)
+		t.state = append(t.state[:0], digest[:]...)
+
+		var c scalar
+		if overflow := c.SetBytes(&digest); overflow == 0 && !c.IsZero() {
+			return c
+		}
+	}
+}
+
+// wideChallenge derives 64 bytes to reduce into a scalar of another group, and absorbs them
+func (t *transcript) wideChallenge(label string) []byte {
+	t.appendBytes("challenge", []byte(label))
+	digest := sha512.Sum512(t.state)
+	t.state = append(t.state[:0], digest[:]...)
+	return digest[:]
+}
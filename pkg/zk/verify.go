@@ -0,0 +1,130 @@
+package zk
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// SolvencyCircuitID identifies the solvency statement proven by ProofGenerator
+const SolvencyCircuitID = "easycash/solvency/v1"
+
+// VerificationKey fixes the statement a proof is checked against. Proofs carry the
+// hash of the key they were created for and are rejected under any other key.
+type VerificationKey struct {
+	CircuitID string `json:"circuit_id"`
+	RangeBits int    `json:"range_bits"`
+}
+
+// NewVerificationKey returns the key for a circuit using the SDK's range proof parameters
+func NewVerificationKey(circuitID string) *VerificationKey {
+	return &VerificationKey{CircuitID: circuitID, RangeBits: RangeBits}
+}
+
+// Hash returns the 32-byte digest identifying the key
+func (vk *VerificationKey) Hash() []byte {
+	h := sha256.New()
+	h.Write([]byte("EasyCash/VerificationKey/v1"))
+	h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(vk.CircuitID))))
+	h.Write([]byte(vk.CircuitID))
+	h.Write(binary.BigEndian.AppendUint32(nil, uint32(vk.RangeBits)))
+	h.Write([]byte(rangeProofDomain))
+	return h.Sum(nil)
+}
+
+// Nullifier marks a note as spent without revealing which note it was
+type Nullifier [32]byte
+
+// String returns the 0x-prefixed hex encoding of the nullifier
+func (n Nullifier) String() string {
+	return "0x" + hex.EncodeToString(n[:])
+}
+
+// MarshalText encodes the nullifier as 0x-prefixed hex
+func (n Nullifier) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+// UnmarshalText decodes a nullifier from 0x-prefixed hex
+func (n *Nullifier) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil || len(b) != len(n) {
+		return fmt.Errorf("invalid nullifier: %q", text)
+	}
+	copy(n[:], b)
+	return nil
+}
+
+// PublicInputs are the values a proof is bound to. A proof verifies only against the
+// exact inputs it was generated for.
+type PublicInputs struct {
+	AmountCommitment *Commitment `json:"amount_commitment"`
+	Nullifier        Nullifier   `json:"nullifier"`
+	Recipient        string      `json:"recipient"`
+}
+
+// maxRecipientLength bounds the recipient encoding inside a proof
+const maxRecipientLength = 1<<16 - 1
+
+// encode returns the canonical encoding of the inputs that is hashed into the proof transcript
+func (in *PublicInputs) encode() ([]byte, error) {
+	if in.AmountCommitment == nil {
+		return nil, fmt.Errorf("amount commitment is required")
+	}
+	if len(in.Recipient) > maxRecipientLength {
+		return nil, fmt.Errorf("recipient too long")
+	}
+	out := append(in.AmountCommitment.Bytes(), in.Nullifier[:]...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(in.Recipient)))
+	return append(out, in.Recipient...), nil
+}
+
+// equal reports whether two sets of public inputs are identical
+func (in *PublicInputs) equal(other *PublicInputs) bool {
+	a, errA := in.encode()
+	b, errB := other.encode()
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
+
+// proofContext binds a proof transcript to a verification key and public inputs
+func proofContext(vk *VerificationKey, inputs *PublicInputs) ([]byte, error) {
+	encoded, err := inputs.encode()
+	if err != nil {
+		return nil, err
+	}
+	return append(vk.Hash(), encoded...), nil
+}
+
+// VerificationFailure classifies why a proof was rejected
+type VerificationFailure string
+
+const (
+	FailureMalformed     VerificationFailure = "malformed proof"
+	FailureKeyMismatch   VerificationFailure = "verification key mismatch"
+	FailureInputMismatch VerificationFailure = "public input mismatch"
+	FailureInvalidProof  VerificationFailure = "proof does not verify"
+)
+
+// VerificationError explains why a proof was rejected
+type VerificationError struct {
+	Reason VerificationFailure
+	Err    error
+}
+
+func (e *VerificationError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("proof verification failed: %s: %v", e.Reason, e.Err)
+	}
+	return fmt.Sprintf("proof verification failed: %s", e.Reason)
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+func verificationError(reason VerificationFailure, format string, args ...interface{}) *VerificationError {
+	return &VerificationError{Reason: reason, Err: fmt.Errorf(format, args...)}
+}
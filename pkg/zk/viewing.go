@@ -0,0 +1,216 @@
+package zk
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// SpendingKey is the root secret of a shielded account. The nullifier key needed to
+// spend and the viewing key needed to read notes are both derived from it one-way, so
+// a viewing key grants no spend authority.
+type SpendingKey [32]byte
+
+// NewSpendingKey returns a random spending key
+func NewSpendingKey() (SpendingKey, error) {
+	var sk SpendingKey
+	if _, err := rand.Read(sk[:]); err != nil {
+		return sk, fmt.Errorf("failed to generate spending key: %w", err)
+	}
+	return sk, nil
+}
+
+// derive returns the 32-byte subkey of sk for label
+func (sk SpendingKey) derive(label string) [32]byte {
+	h := sha256.New()
+	h.Write([]byte(label))
+	h.Write(sk[:])
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// NullifierKey derives the key that computes the account's nullifiers
+func (sk SpendingKey) NullifierKey() NullifierKey {
+	return NullifierKey(sk.derive("EasyCash/NullifierKey/v1"))
+}
+
+// ViewingKey derives the account's viewing key
+func (sk SpendingKey) ViewingKey() *ViewingKey {
+	incoming := sk.derive("EasyCash/IncomingViewingKey/v1")
+	rk, err := ReceivingKeyFromBytes(incoming[:])
+	if err != nil {
+		// X25519 accepts every 32-byte string as a private key
+		panic(err)
+	}
+	return &ViewingKey{Incoming: rk, Outgoing: OutgoingViewingKey(sk.derive("EasyCash/OutgoingViewingKey/v1"))}
+}
+
+// MarshalText encodes the key as 0x-prefixed hex
+func (sk SpendingKey) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(sk[:])), nil
+}
+
+// UnmarshalText decodes a key from 0x-prefixed hex
+func (sk *SpendingKey) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil || len(b) != len(sk) {
+		return fmt.Errorf("invalid spending key encoding")
+	}
+	copy(sk[:], b)
+	return nil
+}
+
+// OutgoingViewingKey recovers the recipient and contents of notes the account sent
+type OutgoingViewingKey [32]byte
+
+// cipher derives the key sealing the outgoing ciphertext of enc
+func (ovk *OutgoingViewingKey) cipher(enc *EncryptedNote) (cipher.AEAD, error) {
+	info := "EasyCash/OutgoingCipher/v1" + string(enc.EphemeralKey[:]) + string(enc.associatedData())
+	key, err := hkdf.Key(sha256.New, ovk[:], nil, info, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
+
+// open recovers the recipient address and ephemeral private key of a note the account sent
+func (ovk *OutgoingViewingKey) open(enc *EncryptedNote) (ReceivingAddress, *ecdh.PrivateKey, error) {
+	var addr ReceivingAddress
+	aead, err := ovk.cipher(enc)
+	if err != nil {
+		return addr, nil, err
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	plaintext, err := aead.Open(nil, nonce, enc.OutCiphertext, enc.associatedData())
+	if err != nil || len(plaintext) != 64 {
+		return addr, nil, ErrNotForKey
+	}
+	ephemeral, err := ecdh.X25519().NewPrivateKey(plaintext[32:])
+	if err != nil || !bytes.Equal(ephemeral.PublicKey().Bytes(), enc.EphemeralKey[:]) {
+		return addr, nil, ErrNotForKey
+	}
+	copy(addr[:], plaintext[:32])
+	return addr, ephemeral, nil
+}
+
+// Decrypt recovers a note the account sent, its memo and the address it was sent to
+func (ovk *OutgoingViewingKey) Decrypt(enc *EncryptedNote) (*Note, []byte, ReceivingAddress, error) {
+	addr, ephemeral, err := ovk.open(enc)
+	if err != nil {
+		return nil, nil, addr, err
+	}
+	shared, err := agree(ephemeral, [32]byte(addr))
+	if err != nil {
+		return nil, nil, addr, err
+	}
+	note, memo, err := openNote(enc, shared, addr)
+	return note, memo, addr, err
+}
+
+// ViewingKey reads an account's shielded history, incoming and outgoing, without
+// being able to spend. It is what the account holder hands to an auditor.
+type ViewingKey struct {
+	Incoming *ReceivingKey
+	Outgoing OutgoingViewingKey
+}
+
+// NewViewingKey returns a random viewing key, for wallets without a spending key
+func NewViewingKey() (*ViewingKey, error) {
+	rk, err := NewReceivingKey()
+	if err != nil {
+		return nil, err
+	}
+	vk := &ViewingKey{Incoming: rk}
+	if _, err := rand.Read(vk.Outgoing[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate viewing key: %w", err)
+	}
+	return vk, nil
+}
+
+// Address returns the receiving address whose notes the key can read
+func (vk *ViewingKey) Address() ReceivingAddress {
+	return vk.Incoming.Address()
+}
+
+// String encodes the key as 0x-prefixed hex of the incoming and outgoing keys
+func (vk *ViewingKey) String() string {
+	return "0x" + hex.EncodeToString(vk.Incoming.priv.Bytes()) + hex.EncodeToString(vk.Outgoing[:])
+}
+
+// ParseViewingKey decodes a viewing key from String
+func ParseViewingKey(s string) (*ViewingKey, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != 64 {
+		return nil, fmt.Errorf("invalid viewing key encoding")
+	}
+	rk, err := ReceivingKeyFromBytes(b[:32])
+	if err != nil {
+		return nil, err
+	}
+	vk := &ViewingKey{Incoming: rk}
+	copy(vk.Outgoing[:], b[32:])
+	return vk, nil
+}
+
+// MarshalText encodes the key as in String
+func (vk *ViewingKey) MarshalText() ([]byte, error) {
+	return []byte(vk.String()), nil
+}
+
+// UnmarshalText decodes a key from String
+func (vk *ViewingKey) UnmarshalText(text []byte) error {
+	parsed, err := ParseViewingKey(string(text))
+	if err != nil {
+		return err
+	}
+	*vk = *parsed
+	return nil
+}
+
+// ViewedNote is a note of the account's history found with its viewing key
+type ViewedNote struct {
+	Note *Note
+	Memo []byte
+	// Outgoing is set for notes the account sent; Counterparty is then the address
+	// they went to. For incoming notes it is the account's own address.
+	Outgoing     bool
+	Counterparty ReceivingAddress
+}
+
+// Scan returns the notes received or sent by the account among published notes
+func (vk *ViewingKey) Scan(notes []*EncryptedNote) ([]ViewedNote, error) {
+	var found []ViewedNote
+	var errs []error
+	for _, enc := range notes {
+		note, memo, err := vk.Incoming.Decrypt(enc)
+		if err == nil {
+			found = append(found, ViewedNote{Note: note, Memo: memo, Counterparty: vk.Address()})
+			continue
+		}
+		if !errors.Is(err, ErrNotForKey) {
+			errs = append(errs, fmt.Errorf("leaf %d: %w", enc.LeafIndex, err))
+			continue
+		}
+
+		note, memo, addr, err := vk.Outgoing.Decrypt(enc)
+		if errors.Is(err, ErrNotForKey) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("leaf %d: %w", enc.LeafIndex, err))
+			continue
+		}
+		found = append(found, ViewedNote{Note: note, Memo: memo, Outgoing: true, Counterparty: addr})
+	}
+	return found, errors.Join(errs...)
+}
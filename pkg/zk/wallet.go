@@ -0,0 +1,366 @@
+package zk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/useeasycash/ecash-sdk-core/pkg/fileutil"
+)
+
+var (
+	ErrInsufficientShieldedFunds = errors.New("insufficient shielded funds")
+	ErrDuplicateNote             = errors.New("note already recorded")
+)
+
+// NoteWallet tracks the wallet's shielded notes and derives their nullifiers.
+// It is safe for concurrent use.
+type NoteWallet struct {
+	nk NullifierKey
+	vk *ViewingKey
+
+	mu       sync.Mutex
+	notes    []*Note
+	byNf     map[Nullifier]*Note
+	reserved map[Nullifier]bool
+}
+
+// NewNoteWallet returns an empty wallet using nk to derive nullifiers
+func NewNoteWallet(nk NullifierKey) *NoteWallet {
+	return &NoteWallet{
+		nk:       nk,
+		byNf:     make(map[Nullifier]*Note),
+		reserved: make(map[Nullifier]bool),
+	}
+}
+
+// NewNoteWalletFromSpendingKey returns an empty wallet with the nullifier and viewing
+// keys derived from sk
+func NewNoteWalletFromSpendingKey(sk SpendingKey) *NoteWallet {
+	w := NewNoteWallet(sk.NullifierKey())
+	w.vk = sk.ViewingKey()
+	return w
+}
+
+// ViewingKey returns the key the wallet reads notes with, or nil if none is set
+func (w *NoteWallet) ViewingKey() *ViewingKey {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.vk
+}
+
+// SetViewingKey sets the key the wallet reads notes with
+func (w *NoteWallet) SetViewingKey(vk *ViewingKey) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.vk = vk
+}
+
+// Receive trial-decrypts published notes with the wallet's incoming viewing key and records
+// the ones addressed to it. Notes already in the wallet are skipped. A note is only
+// recorded once its commitment is at its leaf in state, since it cannot be spent
+// before; others are reported with ErrNoteNotInTree and can be received again after
+// the tree is synced.
+func (w *NoteWallet) Receive(state *ShieldedState, notes ...*EncryptedNote) ([]ScannedNote, error) {
+	vk := w.ViewingKey()
+	if vk == nil {
+		return nil, fmt.Errorf("note wallet has no viewing key")
+	}
+	found, scanErr := NewNoteScanner(vk.Incoming).Scan(notes)
+
+	errs := []error{scanErr}
+	received := make([]ScannedNote, 0, len(found))
+	for _, scanned := range found {
+		if !state.HasCommitment(scanned.Note.LeafIndex, scanned.Note.Commitment) {
+			errs = append(errs, fmt.Errorf("leaf %d: %w", scanned.Note.LeafIndex, ErrNoteNotInTree))
+			continue
+		}
+		if err := w.AddNote(scanned.Note); err != nil {
+			if errors.Is(err, ErrDuplicateNote) {
+				continue
+			}
+			return received, err
+		}
+		received = append(received, scanned)
+	}
+	return received, errors.Join(errs...)
+}
+
+// OutputBlinding derives the blinding factor of a spend's output note, see
+// NullifierKey.OutputBlinding
+func (w *NoteWallet) OutputBlinding(spend Nullifier, amount uint64, recipient string) Blinding {
+	return w.nk.OutputBlinding(spend, amount, recipient)
+}
+
+// AddNote records a received note after checking that its amount and blinding factor
+// open its commitment. The nullifier is derived from the wallet's key.
+func (w *NoteWallet) AddNote(note *Note) error {
+	if note.Commitment == nil || !note.Commitment.Verify(note.Amount, note.Blinding) {
+		return fmt.Errorf("note commitment does not open to its amount")
+	}
+	stored := *note
+	stored.Nullifier = w.nk.Nullifier(note.Commitment, note.LeafIndex)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.byNf[stored.Nullifier]; ok {
+		return fmt.Errorf("%w: leaf %d", ErrDuplicateNote, note.LeafIndex)
+	}
+	w.notes = append(w.notes, &stored)
+	w.byNf[stored.Nullifier] = &stored
+	note.Nullifier = stored.Nullifier
+	return nil
+}
+
+// Notes returns copies of all recorded notes, spent or not
+func (w *NoteWallet) Notes() []Note {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	out := make([]Note, len(w.notes))
+	for i, note := range w.notes {
+		out[i] = *note
+	}
+	return out
+}
+
+// Balance returns the unspent shielded balance of an asset in base units
+func (w *NoteWallet) Balance(asset string) uint64 {
+	return w.Balances()[asset]
+}
+
+// Balances returns the unspent shielded balance of every asset in base units
+func (w *NoteWallet) Balances() map[string]uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	balances := make(map[string]uint64)
+	for _, note := range w.notes {
+		if !note.Spent {
+			balances[note.Asset] += note.Amount
+		}
+	}
+	return balances
+}
+
+// NoteSelection is a set of notes reserved for one spend. Reserved notes are skipped by
+// other selections until the selection is released or marked spent.
+type NoteSelection struct {
+	wallet *NoteWallet
+	Notes  []Note
+	Total  uint64
+	done   bool
+}
+
+// Nullifiers returns the nullifiers of the selected notes
+func (s *NoteSelection) Nullifiers() []Nullifier {
+	out := make([]Nullifier, len(s.Notes))
+	for i, note := range s.Notes {
+		out[i] = note.Nullifier
+	}
+	return out
+}
+
+// BalanceBlinding returns the sum of the selected notes' blinding factors, so that
+// Commit(Total, BalanceBlinding()) is the sum of their commitments
+func (s *NoteSelection) BalanceBlinding() Blinding {
+	var sum Blinding
+	for _, note := range s.Notes {
+		sum = sum.Add(note.Blinding)
+	}
+	return sum
+}
+
+// Commitments returns the commitments of the selected notes
+func (s *NoteSelection) Commitments() []*Commitment {
+	out := make([]*Commitment, len(s.Notes))
+	for i, note := range s.Notes {
+		out[i] = note.Commitment
+	}
+	return out
+}
+
+// Release returns the notes to the spendable pool; it is a no-op after MarkSpent
+func (s *NoteSelection) Release() {
+	w := s.wallet
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if s.done {
+		return
+	}
+	s.done = true
+	for _, note := range s.Notes {
+		delete(w.reserved, note.Nullifier)
+	}
+}
+
+// MarkSpent records the selected notes as spent
+func (s *NoteSelection) MarkSpent() {
+	w := s.wallet
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if s.done {
+		return
+	}
+	s.done = true
+	for _, note := range s.Notes {
+		delete(w.reserved, note.Nullifier)
+		if stored, ok := w.byNf[note.Nullifier]; ok {
+			stored.Spent = true
+		}
+	}
+}
+
+// SelectNotes reserves unspent notes of asset covering amount. A single note that covers
+// the amount on its own is preferred (smallest such note); otherwise the largest notes
+// are combined, keeping the number of inputs low.
+func (w *NoteWallet) SelectNotes(asset string, amount uint64) (*NoteSelection, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var candidates []*Note
+	for _, note := range w.notes {
+		if note.Asset == asset && !note.Spent && !w.reserved[note.Nullifier] {
+			candidates = append(candidates, note)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Amount > candidates[j].Amount })
+
+	var selected []*Note
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if candidates[i].Amount >= amount {
+			selected = []*Note{candidates[i]}
+			break
+		}
+	}
+	if selected == nil {
+		var total uint64
+		for _, note := range candidates {
+			selected = append(selected, note)
+			total += note.Amount
+			if total >= amount {
+				break
+			}
+		}
+		if total < amount {
+			return nil, fmt.Errorf("%w: %s balance %s, need %s", ErrInsufficientShieldedFunds, asset, FormatAmount(total), FormatAmount(amount))
+		}
+	}
+
+	selection := &NoteSelection{wallet: w}
+	for _, note := range selected {
+		w.reserved[note.Nullifier] = true
+		selection.Notes = append(selection.Notes, *note)
+		selection.Total += note.Amount
+	}
+	return selection, nil
+}
+
+// SelectByNullifiers reserves the notes with the given nullifiers
+func (w *NoteWallet) SelectByNullifiers(nullifiers []Nullifier) (*NoteSelection, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	selection := &NoteSelection{wallet: w}
+	for _, n := range nullifiers {
+		note, ok := w.byNf[n]
+		if !ok {
+			return nil, fmt.Errorf("unknown note nullifier %s", n)
+		}
+		if note.Spent || w.reserved[n] {
+			return nil, fmt.Errorf("%w: %s", ErrDoubleSpend, n)
+		}
+		selection.Notes = append(selection.Notes, *note)
+		selection.Total += note.Amount
+	}
+	if len(selection.Notes) > 0 && !sameAsset(selection.Notes) {
+		return nil, fmt.Errorf("selected notes hold different assets")
+	}
+	for _, n := range nullifiers {
+		w.reserved[n] = true
+	}
+	return selection, nil
+}
+
+func sameAsset(notes []Note) bool {
+	for _, note := range notes[1:] {
+		if note.Asset != notes[0].Asset {
+			return false
+		}
+	}
+	return true
+}
+
+// SyncSpent marks notes whose nullifiers the shielded state has seen spent, e.g. by
+// another device sharing the wallet, and returns how many were updated
+func (w *NoteWallet) SyncSpent(state *ShieldedState) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	updated := 0
+	for _, note := range w.notes {
+		if !note.Spent && state.IsSpent(note.Nullifier) {
+			note.Spent = true
+			updated++
+		}
+	}
+	return updated
+}
+
+// walletFile is the persisted form of a NoteWallet. It contains secrets (the nullifier
+// key and blinding factors) and is written with owner-only permissions.
+type walletFile struct {
+	NullifierKey NullifierKey `json:"nullifier_key"`
+	ViewingKey   *ViewingKey  `json:"viewing_key,omitempty"`
+	Notes        []*Note      `json:"notes"`
+}
+
+// SaveFile writes the wallet via a temporary file and rename
+func (w *NoteWallet) SaveFile(path string) error {
+	w.mu.Lock()
+	data, err := json.MarshalIndent(walletFile{NullifierKey: w.nk, ViewingKey: w.vk, Notes: w.notes}, "", "  ")
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := fileutil.WriteAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to save note wallet: %w", err)
+	}
+	return nil
+}
+
+// LoadNoteWalletFile restores a wallet saved with SaveFile. A missing file yields an
+// empty wallet with keys derived from a new spending key.
+func LoadNoteWalletFile(path string) (*NoteWallet, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		sk, err := NewSpendingKey()
+		if err != nil {
+			return nil, err
+		}
+		return NewNoteWalletFromSpendingKey(sk), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read note wallet: %w", err)
+	}
+
+	var file walletFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse note wallet: %w", err)
+	}
+	w := NewNoteWallet(file.NullifierKey)
+	w.vk = file.ViewingKey
+	for _, note := range file.Notes {
+		if err := w.AddNote(note); err != nil {
+			return nil, fmt.Errorf("invalid note in wallet: %w", err)
+		}
+	}
+	return w, nil
+}
@@ -0,0 +1,102 @@
+package zk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addTestNote creates a note, appends it to the state's tree and records it in the wallet
+func addTestNote(t *testing.T, w *NoteWallet, state *ShieldedState, asset string, amount uint64) *Note {
+	note, err := NewNote(asset, amount)
+	require.NoError(t, err)
+	note.LeafIndex, err = state.AddCommitment(note.Commitment)
+	require.NoError(t, err)
+	require.NoError(t, w.AddNote(note))
+	return note
+}
+
+func newTestWallet(t *testing.T) *NoteWallet {
+	nk, err := NewNullifierKey()
+	require.NoError(t, err)
+	return NewNoteWallet(nk)
+}
+
+func TestNoteWalletBalances(t *testing.T) {
+	w := newTestWallet(t)
+	state := NewShieldedState()
+	addTestNote(t, w, state, "USDC", 100)
+	addTestNote(t, w, state, "USDC", 250)
+	addTestNote(t, w, state, "ETH", 7)
+
+	assert.Equal(t, uint64(350), w.Balance("USDC"))
+	assert.Equal(t, map[string]uint64{"USDC": 350, "ETH": 7}, w.Balances())
+
+	// Notes whose commitment does not open are rejected, as are duplicates
+	bad, err := NewNote("USDC", 10)
+	require.NoError(t, err)
+	bad.Amount = 11
+	assert.Error(t, w.AddNote(bad))
+	assert.ErrorIs(t, w.AddNote(&w.Notes()[0]), ErrDuplicateNote)
+}
+
+func TestNoteSelection(t *testing.T) {
+	w := newTestWallet(t)
+	state := NewShieldedState()
+	for _, amount := range []uint64{50, 100, 400, 30} {
+		addTestNote(t, w, state, "USDC", amount)
+	}
+
+	// The smallest single note that covers the amount is preferred
+	selection, err := w.SelectNotes("USDC", 80)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), selection.Total)
+	assert.Len(t, selection.Notes, 1)
+
+	// Reserved notes are skipped by other selections
+	other, err := w.SelectNotes("USDC", 420)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(450), other.Total) // largest first: 400 + 50
+	_, err = w.SelectNotes("USDC", 31)
+	assert.ErrorIs(t, err, ErrInsufficientShieldedFunds)
+
+	other.Release()
+	selection.MarkSpent()
+	selection.Release() // no-op after MarkSpent
+	assert.Equal(t, uint64(480), w.Balance("USDC"))
+
+	_, err = w.SelectNotes("USDC", 481)
+	assert.ErrorIs(t, err, ErrInsufficientShieldedFunds)
+	_, err = w.SelectByNullifiers(selection.Nullifiers())
+	assert.ErrorIs(t, err, ErrDoubleSpend)
+}
+
+func TestNoteWalletSyncAndPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.json")
+	w, err := LoadNoteWalletFile(path)
+	require.NoError(t, err)
+	state := NewShieldedState()
+	a := addTestNote(t, w, state, "USDC", 100)
+	addTestNote(t, w, state, "USDC", 200)
+
+	// A spend recorded elsewhere is picked up from the nullifier set
+	spend, err := state.BeginSpend(state.Root(), []Note{*a})
+	require.NoError(t, err)
+	_, err = spend.Commit()
+	require.NoError(t, err)
+	assert.Equal(t, 1, w.SyncSpent(state))
+	assert.Equal(t, uint64(200), w.Balance("USDC"))
+
+	require.NoError(t, w.SaveFile(path))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	restored, err := LoadNoteWalletFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, w.Balances(), restored.Balances())
+	assert.Equal(t, w.Notes(), restored.Notes())
+}